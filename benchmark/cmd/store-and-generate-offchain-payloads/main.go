@@ -14,6 +14,7 @@ import (
 	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
 	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/cometbft/cometbft/libs/bytes"
 	"github.com/cometbft/cometbft/rpc/client/http"
 	"github.com/google/uuid"
 	"go.uber.org/atomic"
@@ -61,11 +62,23 @@ func main() {
 		panic(err)
 	}
 
-	ch := make(chan []byte, *n)
+	chainId, err := helpers.ChainID(context.Background(), client)
+	if err != nil {
+		panic(err)
+	}
+
+	sequence, err := helpers.NextSequence(context.Background(), client, identity.Principal(*principal))
+	if err != nil {
+		panic(err)
+	}
+
+	payloads := make([][]byte, *n)
 	for i := 0; i < *n; i++ {
 		event.Event.System.EventRecordId = i
 
 		payload, err := rpc.NewBuilder().
+			ChainID(chainId).
+			Sequence(sequence+uint64(i)).
 			App(events.AppName).
 			Data(events.RequestTypeCreate, events.CreateRequest{
 				Event: events.ScrubbedEvent{
@@ -81,12 +94,11 @@ func main() {
 			panic(err)
 		}
 
-		ch <- payload
+		payloads[i] = payload
 	}
 
-	close(ch)
-
-	// Start workers
+	// Start workers. Transactions are broadcast in sequence number order, but polled for concurrently.
+	ch := make(chan bytes.HexBytes, *n)
 	group, _ := errgroup.WithContext(context.Background())
 	counter := atomic.NewInt32(0)
 
@@ -95,8 +107,8 @@ func main() {
 
 	for i := 0; i < *workers; i++ {
 		group.Go(func() error {
-			for payload := range ch {
-				res, err := helpers.BroadcastAndPollDefault(context.Background(), client, payload)
+			for hash := range ch {
+				res, err := helpers.PollDefault(context.Background(), client, hash)
 				if err != nil {
 					return err
 				}
@@ -128,6 +140,17 @@ func main() {
 		})
 	}
 
+	for _, payload := range payloads {
+		hash, err := helpers.Broadcast(context.Background(), client, payload)
+		if err != nil {
+			panic(err)
+		}
+
+		ch <- hash
+	}
+
+	close(ch)
+
 	if err := group.Wait(); err != nil {
 		panic(err)
 	}
@@ -8,6 +8,11 @@ import (
 type Config struct {
 	PrincipalName       string `json:"principal_name"`
 	PrincipalPrivateKey string `json:"principal_private_key"`
+	ChainId             string `json:"chain_id"` // ID of the chain under test, bound by request signatures
+	// Sequence is the next sequence number expected of the principal when the run starts. Transactions from multiple
+	// connections may reach the mempool out of sequence number order, and be rejected, so a single connection should
+	// be used.
+	Sequence uint64 `json:"sequence"`
 }
 
 const Path = "config.json"
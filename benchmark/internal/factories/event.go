@@ -8,16 +8,21 @@ import (
 	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
 	"github.com/google/uuid"
 	"github.com/informalsystems/tm-load-test/pkg/loadtest"
+	"go.uber.org/atomic"
 )
 
 type (
 	EventClientFactory struct {
 		config config.Config
+		// sequence is shared by all clients, as they sign with the same principal
+		sequence *atomic.Uint64
 	}
 
 	EventClient struct {
 		principalName string
 		privateKey    []byte
+		chainId       string
+		sequence      *atomic.Uint64
 	}
 )
 
@@ -26,7 +31,8 @@ var _ loadtest.Client = (*EventClient)(nil)
 
 func NewEventClientFactory(config config.Config) *EventClientFactory {
 	return &EventClientFactory{
-		config: config,
+		config:   config,
+		sequence: atomic.NewUint64(config.Sequence),
 	}
 }
 
@@ -43,11 +49,15 @@ func (f *EventClientFactory) NewClient(cfg loadtest.Config) (loadtest.Client, er
 	return &EventClient{
 		principalName: f.config.PrincipalName,
 		privateKey:    decoded,
+		chainId:       f.config.ChainId,
+		sequence:      f.sequence,
 	}, nil
 }
 
 func (c *EventClient) GenerateTx() ([]byte, error) {
 	return rpc.NewBuilder().
+		ChainID(c.chainId).
+		Sequence(c.sequence.Inc()-1).
 		App(events.AppName).
 		Data(events.RequestTypeCreate, events.CreateRequest{
 			Event: RandomEvent(),
@@ -6,6 +6,7 @@ import (
 	"github.com/RyanW02/wineventchain/chain-client/config"
 	"github.com/RyanW02/wineventchain/chain-client/internal"
 	"github.com/RyanW02/wineventchain/chain-client/prompt"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
 	"github.com/cometbft/cometbft/rpc/client/http"
 	"github.com/manifoldco/promptui"
 	"go.uber.org/zap"
@@ -37,10 +38,16 @@ func main() {
 		panic(err)
 	}
 
+	chainId, err := helpers.ChainID(context.Background(), tmClient)
+	if err != nil {
+		panic(err)
+	}
+
 	client := internal.Client{
-		Config: conf,
-		Client: tmClient,
-		Logger: logger,
+		Config:  conf,
+		Client:  tmClient,
+		Logger:  logger,
+		ChainId: chainId,
 	}
 
 	// Set active principal
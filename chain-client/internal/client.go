@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"context"
 	"crypto/ed25519"
 	"github.com/RyanW02/wineventchain/chain-client/config"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/cometbft/cometbft/rpc/client/http"
 	"go.uber.org/zap"
 )
@@ -11,7 +14,14 @@ type Client struct {
 	Config config.Config
 	Client *http.HTTP
 	Logger *zap.Logger
+	// ChainId is the ID of the chain that the node is participating in, which is bound by request signatures
+	ChainId string
 
 	ActivePrincipal  *string
 	ActivePrivateKey ed25519.PrivateKey
 }
+
+// NextSequence fetches the sequence number that the next request signed by the active principal must carry.
+func (c *Client) NextSequence() (uint64, error) {
+	return helpers.NextSequence(context.Background(), c.Client, identity.Principal(*c.ActivePrincipal))
+}
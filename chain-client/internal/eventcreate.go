@@ -86,7 +86,14 @@ func (c *Client) submitEvent(event events.ScrubbedEvent) error {
 		return c.OpenMainMenu()
 	}
 
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
 	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
 		App(events.AppName).
 		Data(events.RequestTypeCreate, events.CreateRequest{
 			Event: event,
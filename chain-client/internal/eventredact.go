@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/RyanW02/wineventchain/chain-client/prompt"
+	"github.com/RyanW02/wineventchain/chain-client/validate"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (c *Client) HandleRedactEvent() error {
+	if c.ActivePrincipal == nil || c.ActivePrivateKey == nil {
+		if err := prompt.Display("Error", "No active principal"); err != nil {
+			return err
+		}
+
+		return c.OpenEventsActionSelector()
+	}
+
+	eventIdStr, err := prompt.Text("Event ID", validate.Sha256Hash)
+	if err != nil {
+		return err
+	}
+
+	eventId, err := hex.DecodeString(eventIdStr)
+	if err != nil {
+		return err
+	}
+
+	reason, err := prompt.Text("Reason", validate.LengthBetween(1, events.MaxRedactionReasonLength))
+	if err != nil {
+		return err
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(events.AppName).
+		Data(events.RequestTypeRedact, events.RedactRequest{
+			EventIds: []events.EventHash{eventId},
+			Reason:   reason,
+			Nonce:    uuid.New(),
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	res, err := helpers.BroadcastAndPollDefault(context.Background(), c.Client, marshalled)
+	if err != nil {
+		return err
+	}
+
+	// Check if the transaction was successful
+	if res.TxResult.Code == events.CodeOk {
+		c.Logger.Info("Redaction order recorded successfully")
+
+		if err := prompt.Display("Redaction", string(res.TxResult.Data)); err != nil {
+			return err
+		}
+	} else {
+		if len(res.TxResult.Log) == 0 {
+			c.Logger.Error("failed to redact event", zap.Uint32("code", res.TxResult.Code))
+		} else {
+			c.Logger.Error(
+				"failed to redact event",
+				zap.Uint32("code", res.TxResult.Code),
+				zap.String("log", res.TxResult.Log),
+			)
+		}
+	}
+
+	return c.OpenEventsActionSelector()
+}
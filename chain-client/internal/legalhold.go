@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/chain-client/prompt"
+	"github.com/RyanW02/wineventchain/chain-client/validate"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"time"
+)
+
+func (c *Client) HandleHoldPlace() error {
+	filePath, err := prompt.Text("Path to JSON hold criteria file", validate.FileExists)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var criteria offchain.HoldCriteria
+	if err := json.Unmarshal(bytes, &criteria); err != nil {
+		return err
+	}
+
+	if err := criteria.Validate(); err != nil {
+		if err := prompt.Display("Hold Validation Error", "Hold criteria are invalid: "+err.Error()); err != nil {
+			return err
+		}
+
+		return c.OpenRetentionPolicyActionSelector()
+	}
+
+	reason, err := prompt.Text("Reason", validate.LengthBetween(1, retention.MaxHoldReasonLength))
+	if err != nil {
+		return err
+	}
+
+	expiresInRaw, err := prompt.TextWithDefault("Expires in (e.g. 720h, 0 for never)", "0", func(input string) error {
+		_, err := time.ParseDuration(input)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	expiresIn, err := time.ParseDuration(expiresInRaw)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		t := time.Now().Add(expiresIn)
+		expiresAt = &t
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(retention.AppName).
+		Data(retention.RequestTypePlaceHold, retention.PlaceHoldRequest{
+			Criteria:  criteria,
+			Reason:    reason,
+			ExpiresAt: expiresAt,
+			Nonce:     uuid.New(),
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	return c.submitHoldTx(marshalled, "place legal hold")
+}
+
+func (c *Client) HandleHoldRelease() error {
+	holdIdRaw, err := prompt.Text("Hold ID", validate.Uint)
+	if err != nil {
+		return err
+	}
+
+	holdId, err := strconv.ParseUint(holdIdRaw, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(retention.AppName).
+		Data(retention.RequestTypeReleaseHold, retention.ReleaseHoldRequest{
+			HoldId: holdId,
+			Nonce:  uuid.New(),
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	return c.submitHoldTx(marshalled, "release legal hold")
+}
+
+func (c *Client) HandleHoldsView() error {
+	var holds offchain.LegalHolds
+	return c.queryRetentionApp(retention.QueryPathHolds, "Legal Holds", &holds)
+}
+
+func (c *Client) submitHoldTx(marshalled []byte, action string) error {
+	res, err := helpers.BroadcastAndPollDefault(context.Background(), c.Client, marshalled)
+	if err != nil {
+		return err
+	}
+
+	if res.TxResult.Code == retention.CodeOk {
+		var response retention.HoldResponse
+		if err := json.Unmarshal(res.TxResult.Data, &response); err != nil {
+			return err
+		}
+
+		c.Logger.Info("Successfully submitted transaction", zap.String("action", action))
+
+		if err := prompt.DisplayMarshalled("Legal Hold", response.Hold); err != nil {
+			return err
+		}
+	} else {
+		msg := fmt.Sprintf("Failed to %s. Code: %s:%d, log: %s", action, res.TxResult.Codespace, res.TxResult.Code, res.TxResult.Log)
+		if err := prompt.Display("Error", msg); err != nil {
+			return err
+		}
+	}
+
+	return c.OpenRetentionPolicyActionSelector()
+}
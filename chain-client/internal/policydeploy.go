@@ -40,7 +40,14 @@ func (c *Client) HandlePolicyDeploy() error {
 		return c.OpenRetentionPolicyActionSelector()
 	}
 
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
 	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
 		App(retention.AppName).
 		Data(retention.RequestTypeSetPolicy, retention.SetPolicyRequest{
 			Policy: policy,
@@ -68,13 +75,13 @@ func (c *Client) HandlePolicyDeploy() error {
 		}
 	} else {
 		if res.TxResult.Codespace == retention.Codespace && res.TxResult.Code == retention.CodePolicyAlreadySet {
-			if err := prompt.Display("Policy Already Set", "A retention policy has already been set"); err != nil {
+			if err := prompt.Display("Policy Already Set", "A retention policy has already been set. Amendments must be made by proposal."); err != nil {
 				return err
 			}
 
 			return c.OpenRetentionPolicyActionSelector()
 		} else if res.TxResult.Codespace == retention.Codespace && res.TxResult.Code == retention.CodeUnauthorized {
-			if err := prompt.Display("Unauthorized", "You are not authorized to set the retention policy: the admin role is required to be assigned to the principal making the request"); err != nil {
+			if err := prompt.Display("Unauthorized", "You are not authorized to set the retention policy: the principal making the request must hold a role permitted to manage the policy"); err != nil {
 				return err
 			}
 
@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/chain-client/prompt"
+	"github.com/RyanW02/wineventchain/chain-client/validate"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strconv"
+)
+
+func (c *Client) HandlePolicyPropose() error {
+	filePath, err := prompt.Text("Path to YAML policy file", validate.FileExists)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	var policy offchain.RetentionPolicy
+	if err := yaml.Unmarshal(bytes, &policy); err != nil {
+		return err
+	}
+
+	if err := policy.Validate(); err != nil {
+		if err := prompt.Display("Policy Validation Error", "Policy is invalid: "+err.Error()); err != nil {
+			return err
+		}
+
+		return c.OpenRetentionPolicyActionSelector()
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(retention.AppName).
+		Data(retention.RequestTypeProposePolicy, retention.ProposePolicyRequest{
+			Policy: policy,
+			Nonce:  uuid.New(),
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	return c.submitProposalTx(marshalled, "propose policy amendment")
+}
+
+func (c *Client) HandlePolicyVote() error {
+	proposalIdRaw, err := prompt.Text("Proposal ID", validate.Uint)
+	if err != nil {
+		return err
+	}
+
+	proposalId, err := strconv.ParseUint(proposalIdRaw, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	requestType, err := prompt.SelectString("Vote", retention.RequestTypeApprovePolicy, retention.RequestTypeRejectPolicy)
+	if err != nil {
+		return err
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(retention.AppName).
+		Data(rpc.RequestType(requestType), retention.VoteRequest{
+			ProposalId: proposalId,
+			Nonce:      uuid.New(),
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	return c.submitProposalTx(marshalled, "vote on proposal")
+}
+
+func (c *Client) submitProposalTx(marshalled []byte, action string) error {
+	res, err := helpers.BroadcastAndPollDefault(context.Background(), c.Client, marshalled)
+	if err != nil {
+		return err
+	}
+
+	if res.TxResult.Code == retention.CodeOk {
+		var response retention.ProposalResponse
+		if err := json.Unmarshal(res.TxResult.Data, &response); err != nil {
+			return err
+		}
+
+		c.Logger.Info("Successfully submitted transaction", zap.String("action", action))
+
+		if err := prompt.DisplayMarshalled("Proposal", response.Proposal); err != nil {
+			return err
+		}
+	} else {
+		msg := fmt.Sprintf("Failed to %s. Code: %s:%d, log: %s", action, res.TxResult.Codespace, res.TxResult.Code, res.TxResult.Log)
+		if err := prompt.Display("Error", msg); err != nil {
+			return err
+		}
+	}
+
+	return c.OpenRetentionPolicyActionSelector()
+}
+
+func (c *Client) HandlePolicyHistoryView() error {
+	var history offchain.PolicyHistory
+	return c.queryRetentionApp(retention.QueryPathHistory, "Policy History", &history)
+}
+
+func (c *Client) HandlePolicyProposalsView() error {
+	var proposals []retention.Proposal
+	return c.queryRetentionApp(retention.QueryPathProposals, "Proposals", &proposals)
+}
+
+func (c *Client) queryRetentionApp(path, label string, value any) error {
+	data := rpc.MuxedRequest{App: retention.AppName}
+	marshalled, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.Client.ABCIQueryWithOptions(context.Background(), path, marshalled, ABCIQueryOptionsNoProve)
+	if err != nil {
+		return err
+	}
+
+	if res.Response.Codespace == retention.Codespace && res.Response.Code == retention.CodeOk {
+		if err := json.Unmarshal(res.Response.Value, value); err != nil {
+			return err
+		}
+
+		if err := prompt.DisplayMarshalled(label, value); err != nil {
+			return err
+		}
+	} else {
+		msg := fmt.Sprintf(
+			"Blockchain node returned an error. Code: %s:%d, log: %s",
+			res.Response.Codespace,
+			res.Response.Code,
+			res.Response.Log,
+		)
+
+		if err := prompt.Display("Error", msg); err != nil {
+			return err
+		}
+	}
+
+	return c.OpenRetentionPolicyActionSelector()
+}
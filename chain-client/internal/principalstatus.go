@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"github.com/RyanW02/wineventchain/chain-client/prompt"
+	"github.com/RyanW02/wineventchain/chain-client/validate"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (c *Client) HandleChangePrincipalStatus() error {
+	return prompt.SelectAndExecute("Choose an action",
+		prompt.NewSelectOption("Suspend", "⏸️", func() error {
+			return c.changePrincipalStatus(identity.RequestTypeSuspend)
+		}),
+		prompt.NewSelectOption("Reinstate", "▶️", func() error {
+			return c.changePrincipalStatus(identity.RequestTypeReinstate)
+		}),
+		prompt.NewSelectOption("Revoke", "⛔", func() error {
+			return c.changePrincipalStatus(identity.RequestTypeRevoke)
+		}),
+		prompt.NewSelectOption("Back", "⬅️", c.OpenIdentityActionSelector),
+	)
+}
+
+func (c *Client) changePrincipalStatus(requestType rpc.RequestType) error {
+	if c.ActivePrincipal == nil || c.ActivePrivateKey == nil {
+		if err := prompt.Display("Error", "No active principal"); err != nil {
+			return err
+		}
+
+		return c.OpenIdentityActionSelector()
+	}
+
+	principal, err := prompt.Text("Principal", validate.LengthBetween(1, 255))
+	if err != nil {
+		return err
+	}
+
+	reason, err := prompt.Text("Reason", validate.MinLength(1))
+	if err != nil {
+		return err
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(identity.AppName).
+		Data(requestType, identity.PayloadStatusChange{
+			Principal: identity.Principal(principal),
+			Reason:    reason,
+			Nonce:     uuid.New(),
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	res, err := helpers.BroadcastAndPollDefault(context.Background(), c.Client, marshalled)
+	if err != nil {
+		return err
+	}
+
+	// Check if the transaction was successful
+	if res.TxResult.Code == identity.CodeOk {
+		c.Logger.Info("Successfully changed principal status", zap.String("principal", principal), zap.String("action", string(requestType)))
+	} else {
+		if len(res.TxResult.Log) == 0 {
+			c.Logger.Error("failed to change principal status", zap.Uint32("code", res.TxResult.Code))
+		} else {
+			c.Logger.Error(
+				"failed to change principal status",
+				zap.Uint32("code", res.TxResult.Code),
+				zap.String("log", res.TxResult.Log),
+			)
+		}
+	}
+
+	return c.OpenIdentityActionSelector()
+}
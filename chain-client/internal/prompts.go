@@ -71,6 +71,8 @@ func (c *Client) OpenIdentityActionSelector() error {
 	return prompt.SelectAndExecute("Choose an action",
 		prompt.NewSelectOption("Seed", "🌱", c.HandleSeed),
 		prompt.NewSelectOption("Register", "👤", c.HandleRegister),
+		prompt.NewSelectOption("Rotate Key", "🔄", c.HandleRotateKey),
+		prompt.NewSelectOption("Change Principal Status", "🚫", c.HandleChangePrincipalStatus),
 		prompt.NewSelectOption("View Principal", "🔍", c.HandleViewPrincipal),
 		prompt.NewSelectOption("Back", "⬅️", c.OpenAppSelector),
 	)
@@ -80,6 +82,7 @@ func (c *Client) OpenEventsActionSelector() error {
 	return prompt.SelectAndExecute("Choose an action",
 		prompt.NewSelectOption("Create", "➕ ", c.HandleCreateEvent),
 		prompt.NewSelectOption("View", "🔍", c.HandleViewEvent),
+		prompt.NewSelectOption("Redact", "✂️", c.HandleRedactEvent),
 		prompt.NewSelectOption("Back", "⬅️", c.OpenAppSelector),
 	)
 }
@@ -87,7 +90,14 @@ func (c *Client) OpenEventsActionSelector() error {
 func (c *Client) OpenRetentionPolicyActionSelector() error {
 	return prompt.SelectAndExecute("Choose an action",
 		prompt.NewSelectOption("Deploy Policy File", "📦", c.HandlePolicyDeploy),
+		prompt.NewSelectOption("Propose Policy Amendment", "📝", c.HandlePolicyPropose),
+		prompt.NewSelectOption("Vote on Proposal", "🗳️", c.HandlePolicyVote),
 		prompt.NewSelectOption("View Active Policy", "🔍", c.HandlePolicyView),
+		prompt.NewSelectOption("View Policy History", "📜", c.HandlePolicyHistoryView),
+		prompt.NewSelectOption("View Proposals", "📋", c.HandlePolicyProposalsView),
+		prompt.NewSelectOption("Place Legal Hold", "🔒", c.HandleHoldPlace),
+		prompt.NewSelectOption("Release Legal Hold", "🔓", c.HandleHoldRelease),
+		prompt.NewSelectOption("View Legal Holds", "⚖️", c.HandleHoldsView),
 		prompt.NewSelectOption("Back", "⬅️", c.OpenAppSelector),
 	)
 }
@@ -26,7 +26,7 @@ func (c *Client) HandleRegister() error {
 		return err
 	}
 
-	role, err := prompt.Select("Choose a role", identity.RoleUser, identity.RoleAdmin)
+	role, err := prompt.Select("Choose a role", identity.Roles...)
 	if err != nil {
 		return err
 	}
@@ -41,7 +41,14 @@ func (c *Client) HandleRegister() error {
 		return err
 	}
 
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
 	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
 		App(identity.AppName).
 		Data(identity.RequestTypeRegister, identity.PayloadRegister{
 			Principal: identity.Principal(principal),
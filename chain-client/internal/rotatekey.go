@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/RyanW02/wineventchain/chain-client/prompt"
+	"github.com/RyanW02/wineventchain/chain-client/validate"
+	"github.com/RyanW02/wineventchain/common/pkg/blockchain/helpers"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"go.uber.org/zap"
+)
+
+func (c *Client) HandleRotateKey() error {
+	if c.ActivePrincipal == nil || c.ActivePrivateKey == nil {
+		if err := prompt.Display("Error", "No active principal"); err != nil {
+			return err
+		}
+
+		return c.OpenIdentityActionSelector()
+	}
+
+	principal, err := prompt.TextWithDefault("Principal", *c.ActivePrincipal, validate.LengthBetween(1, 255))
+	if err != nil {
+		return err
+	}
+
+	privKeyPath, err := prompt.TextWithDefault("Path to write new private key to", "./privkey_"+principal, validate.MinLength(1))
+	if err != nil {
+		return err
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	sequence, err := c.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := rpc.NewBuilder().
+		ChainID(c.ChainId).
+		Sequence(sequence).
+		App(identity.AppName).
+		Data(identity.RequestTypeRotateKey, identity.PayloadRotateKey{
+			Principal: identity.Principal(principal),
+			Key:       pub,
+		}).
+		Signed(identity.Principal(*c.ActivePrincipal), c.ActivePrivateKey).
+		Marshal()
+
+	if err != nil {
+		return err
+	}
+
+	res, err := helpers.BroadcastAndPollDefault(context.Background(), c.Client, marshalled)
+	if err != nil {
+		return err
+	}
+
+	// Check if the transaction was successful
+	if res.TxResult.Code == identity.CodeOk {
+		c.Logger.Info("Successfully rotated key")
+
+		if err := WritePrivateKey(privKeyPath, priv); err != nil {
+			return err
+		}
+
+		// Write privkey path to config
+		c.Config.Client.PrivateKeyFiles[principal] = privKeyPath
+
+		// If we rotated our own key, start using the new key immediately
+		if principal == *c.ActivePrincipal {
+			c.ActivePrivateKey = priv
+		}
+
+		if err := c.Config.Write(); err != nil {
+			return err
+		}
+	} else {
+		if len(res.TxResult.Log) == 0 {
+			c.Logger.Error("failed to rotate key", zap.Uint32("code", res.TxResult.Code))
+			return c.OpenIdentityActionSelector()
+		} else {
+			c.Logger.Error(
+				"failed to rotate key",
+				zap.Uint32("code", res.TxResult.Code),
+				zap.String("log", res.TxResult.Log),
+			)
+			return c.OpenIdentityActionSelector()
+		}
+	}
+
+	return c.OpenIdentityActionSelector()
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/manifoldco/promptui"
 	"os"
 	"regexp"
+	"strconv"
 )
 
 // MinLength returns a ValidateFunc that checks if the input is at least min characters long, inclusive of min.
@@ -58,3 +59,11 @@ func FileExists(input string) error {
 
 	return nil
 }
+
+func Uint(input string) error {
+	if _, err := strconv.ParseUint(input, 10, 64); err != nil {
+		return fmt.Errorf("input must be a non-negative integer")
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"context"
+	"github.com/cometbft/cometbft/rpc/client/http"
+)
+
+// ChainID fetches the ID of the chain that the node is participating in, which must be bound by request signatures.
+func ChainID(ctx context.Context, client *http.HTTP) (string, error) {
+	status, err := client.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return status.NodeInfo.Network, nil
+}
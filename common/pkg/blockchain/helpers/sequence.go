@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/cometbft/cometbft/rpc/client/http"
+)
+
+// NextSequence fetches the sequence number that the next request signed by the principal must carry.
+func NextSequence(ctx context.Context, client *http.HTTP, principal identity.Principal) (uint64, error) {
+	marshalled, err := json.Marshal(rpc.MuxedRequest{App: identity.AppName})
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := client.ABCIQuery(ctx, identity.QueryPathSequence+principal.String(), marshalled)
+	if err != nil {
+		return 0, err
+	}
+
+	if res.Response.Code != identity.CodeOk {
+		return 0, fmt.Errorf("error fetching sequence number: code %s:%d; message: %s", res.Response.Codespace, res.Response.Code, res.Response.Log)
+	}
+
+	var sequence identity.SequenceResponse
+	if err := json.Unmarshal(res.Response.Value, &sequence); err != nil {
+		return 0, err
+	}
+
+	return sequence.Sequence, nil
+}
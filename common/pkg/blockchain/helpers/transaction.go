@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/cometbft/cometbft/libs/bytes"
 	"github.com/cometbft/cometbft/rpc/client/http"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
@@ -17,6 +18,17 @@ func BroadcastAndPollDefault(ctx context.Context, client *http.HTTP, tx types.Tx
 }
 
 func BroadcastAndPoll(ctx context.Context, client *http.HTTP, tx types.Tx, retryFrequency time.Duration, timeout time.Duration) (*coretypes.ResultTx, error) {
+	hash, err := Broadcast(ctx, client, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return Poll(ctx, client, hash, retryFrequency, timeout)
+}
+
+// Broadcast submits the transaction to the mempool, returning its hash once it has passed CheckTx. Transactions signed
+// by the same principal must be broadcast in sequence number order.
+func Broadcast(ctx context.Context, client *http.HTTP, tx types.Tx) (bytes.HexBytes, error) {
 	res, err := client.BroadcastTxSync(ctx, tx)
 	if err != nil {
 		return nil, err
@@ -26,6 +38,15 @@ func BroadcastAndPoll(ctx context.Context, client *http.HTTP, tx types.Tx, retry
 		return nil, fmt.Errorf("Transaction failed! Code %s:%d; Message: %s", res.Codespace, res.Code, res.Log)
 	}
 
+	return res.Hash, nil
+}
+
+func PollDefault(ctx context.Context, client *http.HTTP, hash bytes.HexBytes) (*coretypes.ResultTx, error) {
+	return Poll(ctx, client, hash, 200*time.Millisecond, 15*time.Second)
+}
+
+// Poll waits for the transaction with the given hash to be included in a block.
+func Poll(ctx context.Context, client *http.HTTP, hash bytes.HexBytes, retryFrequency time.Duration, timeout time.Duration) (*coretypes.ResultTx, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
 	defer cancelFunc()
 
@@ -34,7 +55,7 @@ func BroadcastAndPoll(ctx context.Context, client *http.HTTP, tx types.Tx, retry
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case <-time.After(retryFrequency):
-			res, err := client.Tx(ctx, res.Hash, true)
+			res, err := client.Tx(ctx, hash, true)
 			if err != nil {
 				var rpcError *rpctypes.RPCError
 				if errors.As(err, &rpcError) && strings.Contains(rpcError.Data, "not found") {
@@ -0,0 +1,152 @@
+package proof
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	ics23 "github.com/confio/ics23/go"
+	"sort"
+)
+
+// MultiplexProof proves the position of a sub-application's tree root in the multiplexed app hash, by carrying the
+// roots of every sub-application, from which the app hash is recomputed.
+type MultiplexProof struct {
+	AppHashes map[string][]byte `json:"app_hashes"`
+}
+
+const TypeMultiplex = "multiplex"
+
+// BoundAppHashVersion is the first app version whose app hash binds the name and length of each sub-application root.
+// Earlier versions concatenate the roots alone, so a proof's roots can be relabelled or re-split without changing the
+// app hash.
+const BoundAppHashVersion uint64 = 2
+
+var (
+	ErrUnexpectedProofOps = errors.New("unexpected proof operations")
+	ErrRootMismatch       = errors.New("sub-application root does not match multiplexed app hash")
+	ErrAppHashMismatch    = errors.New("multiplexed app hash does not match block header")
+)
+
+// MultiplexAppHash combines the roots of each sub-application into the app hash reported to CometBFT, in the format of
+// the given app version: the SHA-256 hash of each application name and root, in order of application name. From
+// BoundAppHashVersion, each name and root is prefixed with its length.
+func MultiplexAppHash(appVersion uint64, appHashes map[string][]byte) []byte {
+	appNames := make([]string, 0, len(appHashes))
+	for name := range appHashes {
+		appNames = append(appNames, name)
+	}
+
+	sort.Strings(appNames)
+
+	var combined []byte
+	for _, name := range appNames {
+		if appVersion < BoundAppHashVersion {
+			combined = append(combined, appHashes[name]...)
+			continue
+		}
+
+		combined = binary.AppendUvarint(combined, uint64(len(name)))
+		combined = append(combined, name...)
+		combined = binary.AppendUvarint(combined, uint64(len(appHashes[name])))
+		combined = append(combined, appHashes[name]...)
+	}
+
+	sum := sha256.Sum256(combined)
+	return sum[:]
+}
+
+// ProofOpForMultiplex generates a proof of the root of the given sub-application within the multiplexed app hash.
+func ProofOpForMultiplex(appName string, appHashes map[string][]byte) (crypto.ProofOp, error) {
+	marshalled, err := json.Marshal(MultiplexProof{AppHashes: appHashes})
+	if err != nil {
+		return crypto.ProofOp{}, err
+	}
+
+	return crypto.ProofOp{
+		Type: TypeMultiplex,
+		Key:  []byte(appName),
+		Data: marshalled,
+	}, nil
+}
+
+// VerifyChain verifies that the proof operations prove the presence or absence of key in the tree of the given
+// sub-application, and that the sub-application's tree root is committed to by appHash, which must be taken from a
+// trusted block header. The header that commits to the state at height H is the header of block H+1, whose app version
+// determines the format of the app hash.
+//
+// Returns the proven value, or nil if the proof is of the key's absence. The proven value must be used in place of
+// any value returned alongside the proof.
+func VerifyChain(proofOps *crypto.ProofOps, appName string, key []byte, appHash []byte, appVersion uint64) ([]byte, error) {
+	if proofOps == nil || len(proofOps.Ops) == 0 {
+		return nil, ErrMissingProof
+	}
+
+	if len(proofOps.Ops) != 2 || proofOps.Ops[0].Type != TypeIAVL || proofOps.Ops[1].Type != TypeMultiplex {
+		return nil, fmt.Errorf("%w: expected %s followed by %s", ErrUnexpectedProofOps, TypeIAVL, TypeMultiplex)
+	}
+
+	treeOp, multiplexOp := proofOps.Ops[0], proofOps.Ops[1]
+	if !bytes.Equal(treeOp.Key, key) {
+		return nil, fmt.Errorf("%w: proof is for key %x, expected %x", ErrInvalidProof, treeOp.Key, key)
+	}
+
+	var treeProof TreeProof
+	if err := json.Unmarshal(treeOp.Data, &treeProof); err != nil {
+		return nil, err
+	}
+
+	// Prove the key against the sub-application's root
+	var value []byte
+	switch proof := treeProof.Proof.Proof.(type) {
+	case *ics23.CommitmentProof_Exist:
+		if err := proof.Exist.Verify(ics23.IavlSpec, treeProof.AppHash, key, proof.Exist.Value); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+
+		value = proof.Exist.Value
+	case *ics23.CommitmentProof_Nonexist:
+		if err := proof.Nonexist.Verify(ics23.IavlSpec, treeProof.AppHash, key); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported proof type %T", treeProof.Proof.Proof)
+	}
+
+	// Prove the sub-application's root against the app hash
+	if string(multiplexOp.Key) != appName {
+		return nil, fmt.Errorf("%w: proof is for app %s, expected %s", ErrInvalidProof, multiplexOp.Key, appName)
+	}
+
+	var multiplexProof MultiplexProof
+	if err := json.Unmarshal(multiplexOp.Data, &multiplexProof); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(multiplexProof.AppHashes[appName], treeProof.AppHash) {
+		return nil, fmt.Errorf(
+			"%w: tree root %x, %s root %x",
+			ErrRootMismatch, treeProof.AppHash, appName, multiplexProof.AppHashes[appName],
+		)
+	}
+
+	if computed := MultiplexAppHash(appVersion, multiplexProof.AppHashes); !bytes.Equal(computed, appHash) {
+		return nil, fmt.Errorf("%w: computed %x, header %x", ErrAppHashMismatch, computed, appHash)
+	}
+
+	return value, nil
+}
+
+// ListedProofOps combines the proof of an entry returned by a listing query with the multiplex proof attached to the
+// listing response, so that the entry can be verified with VerifyChain.
+func ListedProofOps(entry crypto.ProofOp, listing *crypto.ProofOps) *crypto.ProofOps {
+	ops := []crypto.ProofOp{entry}
+	if listing != nil {
+		ops = append(ops, listing.Ops...)
+	}
+
+	return &crypto.ProofOps{Ops: ops}
+}
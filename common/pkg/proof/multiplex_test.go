@@ -0,0 +1,109 @@
+package proof
+
+import (
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVerifyChainPresent(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("key17"))
+
+	value, err := VerifyChain(ops, "events", []byte("key17"), appHash, BoundAppHashVersion)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+}
+
+func TestVerifyChainAbsent(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("does_not_exist"))
+
+	value, err := VerifyChain(ops, "events", []byte("does_not_exist"), appHash, BoundAppHashVersion)
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+func TestVerifyChainWrongHeader(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("key17"))
+	appHash[0] ^= 0xff
+
+	_, err := VerifyChain(ops, "events", []byte("key17"), appHash, BoundAppHashVersion)
+	require.ErrorIs(t, err, ErrAppHashMismatch)
+}
+
+func TestVerifyChainWrongKey(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("key17"))
+
+	_, err := VerifyChain(ops, "events", []byte("key18"), appHash, BoundAppHashVersion)
+	require.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestVerifyChainWrongApp(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("key17"))
+
+	_, err := VerifyChain(ops, "identity", []byte("key17"), appHash, BoundAppHashVersion)
+	require.ErrorIs(t, err, ErrInvalidProof)
+}
+
+// A node may generate a valid tree proof against a root of its choosing, but the root must then match the app hash
+func TestVerifyChainForgedTree(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("key17"))
+
+	forged := generateTree(t, 101)
+	ops.Ops[0] = generateProof(t, forged, []byte("key17"))
+
+	_, err := VerifyChain(ops, "events", []byte("key17"), appHash, BoundAppHashVersion)
+	require.ErrorIs(t, err, ErrRootMismatch)
+}
+
+func TestVerifyChainMissingMultiplexOp(t *testing.T) {
+	ops, appHash := generateChain(t, []byte("key17"))
+	ops.Ops = ops.Ops[:1]
+
+	_, err := VerifyChain(ops, "events", []byte("key17"), appHash, BoundAppHashVersion)
+	require.ErrorIs(t, err, ErrUnexpectedProofOps)
+}
+
+func TestMultiplexAppHashOrder(t *testing.T) {
+	for _, version := range []uint64{BoundAppHashVersion - 1, BoundAppHashVersion} {
+		a := MultiplexAppHash(version, map[string][]byte{"a": {1}, "b": {2}})
+		b := MultiplexAppHash(version, map[string][]byte{"b": {2}, "a": {1}})
+		require.Equal(t, a, b)
+
+		swapped := MultiplexAppHash(version, map[string][]byte{"a": {2}, "b": {1}})
+		require.NotEqual(t, a, swapped)
+	}
+}
+
+// From BoundAppHashVersion, roots cannot be moved between apps, or between each other, without changing the app hash
+func TestMultiplexAppHashBound(t *testing.T) {
+	appHashes := map[string][]byte{"events": {1, 2}, "identity": {3, 4}}
+
+	relabelled := map[string][]byte{"events": {1, 2}, "identityx": {3, 4}}
+	resplit := map[string][]byte{"events": {1}, "identity": {2, 3, 4}}
+	require.Equal(t, MultiplexAppHash(BoundAppHashVersion-1, appHashes), MultiplexAppHash(BoundAppHashVersion-1, relabelled))
+	require.Equal(t, MultiplexAppHash(BoundAppHashVersion-1, appHashes), MultiplexAppHash(BoundAppHashVersion-1, resplit))
+
+	bound := MultiplexAppHash(BoundAppHashVersion, appHashes)
+	require.NotEqual(t, bound, MultiplexAppHash(BoundAppHashVersion, relabelled))
+	require.NotEqual(t, bound, MultiplexAppHash(BoundAppHashVersion, resplit))
+}
+
+// generateChain generates a proof of key in an events tree, alongside another app, and returns the app hash that the
+// proof chain commits to.
+func generateChain(t *testing.T, key []byte) (*crypto.ProofOps, []byte) {
+	tree := generateTree(t, 100)
+	root, err := tree.Hash()
+	require.NoError(t, err)
+
+	appHashes := map[string][]byte{
+		"events":   root,
+		"identity": {1, 2, 3},
+	}
+
+	multiplexOp, err := ProofOpForMultiplex("events", appHashes)
+	require.NoError(t, err)
+
+	return &crypto.ProofOps{
+		Ops: []crypto.ProofOp{generateProof(t, tree, key), multiplexOp},
+	}, MultiplexAppHash(BoundAppHashVersion, appHashes)
+}
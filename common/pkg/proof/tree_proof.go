@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"github.com/cometbft/cometbft/proto/tendermint/crypto"
 	ics23 "github.com/confio/ics23/go"
-	"github.com/cosmos/iavl"
 )
 
 type TreeProof struct {
@@ -22,7 +21,14 @@ var (
 	ErrTreeUninitialized = errors.New("uninitialized merkle tree: cannot generate proof for empty tree")
 )
 
-func ProofOpForTree(tree *iavl.MutableTree, key []byte) (crypto.ProofOp, error) {
+// ProvableTree is a merkle tree that can produce proofs of its contents, satisfied by both mutable IAVL trees and the
+// immutable trees of their saved versions.
+type ProvableTree interface {
+	Hash() ([]byte, error)
+	GetProof(key []byte) (*ics23.CommitmentProof, error)
+}
+
+func ProofOpForTree(tree ProvableTree, key []byte) (crypto.ProofOp, error) {
 	hash, err := tree.Hash()
 	if err != nil {
 		return crypto.ProofOp{}, err
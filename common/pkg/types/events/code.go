@@ -8,14 +8,29 @@ const (
 	CodeInvalidQueryPath
 	CodeEventNotFound
 	CodeTreeUninitialized
+	CodeUnauthorized
+	CodeInvalidBatchSize
+	CodeDuplicateEvent
+	CodeInvalidQueryParameter
+	CodeAlreadyRedacted
+	CodeInvalidRedaction
+	CodeRedactionNotFound
+	CodeInvalidOffChainHash
+	CodeMissingChannel
+	CodeMissingProvider
+	CodeInvalidEventId
+	CodeTimeSkew
+	CodeKeyRotated
 )
 
 const (
 	EventCreate        = "create"
+	EventRedact        = "redact"
 	AttributeType      = "type"
 	AttributeEventId   = "event_id"
 	AttributeBlockTime = "block_time"
 	AttributePrincipal = "principal"
 
 	AttributeValueCreate = "create"
+	AttributeValueRedact = "redact"
 )
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestTypeRedact is used by administrators to order off-chain nodes to erase the data of up to MaxBatchSize
+	// events, for example to honour a data-subject erasure request.
+	RequestTypeRedact = "redact"
+
+	// QueryPathRedaction returns the redaction order recorded for an event, if any: /redaction/{event_id}
+	QueryPathRedaction = "/redaction/"
+	// QueryPathRedactionsByHeight lists the redaction orders recorded between two block heights (inclusive):
+	// /redactions-by-height/{low}/{high}?limit=&cursor=
+	QueryPathRedactionsByHeight = "/redactions-by-height/"
+
+	// MaxRedactionReasonLength is the maximum length of the reason given for a redaction order, in bytes.
+	MaxRedactionReasonLength = 1024
+)
+
+// RedactionTreeKeyPrefix prefixes the keys under which redaction orders are stored in the events tree.
+const RedactionTreeKeyPrefix = "redaction/"
+
+type RedactRequest struct {
+	EventIds []EventHash `json:"event_ids"`
+	// Reason is a human-readable justification for the redaction, such as a reference to an erasure request.
+	Reason string `json:"reason"`
+	// Nonce is a unique identifier for the request. Prevents "tx already exists in cache" errors from Tendermint.
+	Nonce uuid.UUID `json:"nonce"`
+}
+
+type RedactResponse struct {
+	Redactions []Redaction `json:"redactions"`
+}
+
+// Redaction is an order, recorded on-chain, to erase the off-chain data of an event. The event itself remains on-chain,
+// so that its existence can still be proven.
+type Redaction struct {
+	EventId EventHash          `json:"event_id" bson:"event_id"`
+	Reason  string             `json:"reason" bson:"reason"`
+	Author  identity.Principal `json:"author" bson:"author"`
+	// Height is the height of the block in which the redaction was ordered.
+	Height int64 `json:"height" bson:"height"`
+}
+
+// ProvenRedaction is a redaction order returned by a listing query, along with a proof of its presence in the events
+// tree under its RedactionTreeKey.
+type ProvenRedaction struct {
+	Redaction Redaction      `json:"redaction"`
+	Key       []byte         `json:"key"`
+	Proof     crypto.ProofOp `json:"proof"`
+}
+
+// RedactionListResponse is returned by the QueryPathRedactionsByHeight query. Each redaction is proven against the
+// root of the events tree, which is in turn proven against the app hash by the multiplex proof of the response.
+type RedactionListResponse struct {
+	Redactions []ProvenRedaction `json:"redactions"`
+	// Next is the hex encoded cursor from which the next page can be fetched, or empty if there are no more redactions.
+	Next string `json:"next,omitempty"`
+}
+
+// RedactionTreeKey returns the key under which the redaction order for the event is stored in the events tree, against
+// which redaction queries are proven.
+func RedactionTreeKey(eventId EventHash) []byte {
+	return []byte(RedactionTreeKeyPrefix + eventId.String())
+}
+
+func (r Redaction) String() string {
+	return fmt.Sprintf("redacted at height %d by %s", r.Height, r.Author)
+}
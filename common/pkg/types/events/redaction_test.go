@@ -0,0 +1,41 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestRedactionTreeKey(t *testing.T) {
+	eventId := EventHash(bytes.Repeat([]byte{0xab}, 32))
+
+	key := RedactionTreeKey(eventId)
+	require.True(t, strings.HasPrefix(string(key), RedactionTreeKeyPrefix))
+	require.Equal(t, RedactionTreeKeyPrefix+eventId.String(), string(key))
+
+	// Events are stored under their raw 32 byte ID, so a redaction key must never collide with an event key
+	require.NotEqual(t, 32, len(key))
+}
+
+func TestRedactionString(t *testing.T) {
+	redaction := Redaction{
+		EventId: EventHash{0x01, 0x02},
+		Reason:  "erasure request",
+		Author:  "admin",
+		Height:  42,
+	}
+
+	require.Equal(t, "redacted at height 42 by admin", redaction.String())
+}
+
+func TestStoredEventRedactionOmitted(t *testing.T) {
+	marshalled, err := json.Marshal(StoredEvent{})
+	require.NoError(t, err)
+	require.NotContains(t, string(marshalled), `"redaction"`)
+
+	marshalled, err = json.Marshal(StoredEvent{Redaction: &Redaction{Height: 1}})
+	require.NoError(t, err)
+	require.Contains(t, string(marshalled), `"redaction"`)
+}
@@ -1,24 +1,92 @@
 package events
 
-import "github.com/google/uuid"
+import (
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/google/uuid"
+)
 
 const (
 	AppName = "events"
 
 	// RequestTypeCreate is used to create a new event
 	RequestTypeCreate = "create"
+	// RequestTypeCreateBatch is used to create up to MaxBatchSize events in a single transaction
+	RequestTypeCreateBatch = "create_batch"
+)
+
+const (
+	// QueryPathEventsByPrincipal lists the events submitted by a principal, optionally between the block heights given by
+	// the from and to query parameters (inclusive): /events-by-principal/{principal}?from=&to=
+	QueryPathEventsByPrincipal = "/events-by-principal/"
+	// QueryPathEventsByHeight lists the events stored between two block heights (inclusive):
+	// /events-by-height/{low}/{high}
+	QueryPathEventsByHeight = "/events-by-height/"
+
+	// QueryParamFrom and QueryParamTo bound the block heights listed by QueryPathEventsByPrincipal
+	QueryParamFrom = "from"
+	QueryParamTo   = "to"
+	// QueryParamLimit is the maximum number of events returned by a listing query
+	QueryParamLimit = "limit"
+	// QueryParamCursor resumes a listing query from the EventListResponse.Next cursor of the previous page
+	QueryParamCursor = "cursor"
 )
 
+const (
+	// DefaultPageSize is the number of events returned by a listing query if no limit is given.
+	DefaultPageSize = 100
+	// MaxPageSize is the maximum number of events that a listing query may return.
+	MaxPageSize = 500
+)
+
+// MaxBatchSize is the maximum number of events that may be carried by a single create_batch request.
+const MaxBatchSize = 500
+
 type CreateRequest struct {
 	Event ScrubbedEvent `json:"event"`
 	// Nonce is a unique identifier for the request. Prevents "tx already exists in cache" errors from Tendermint.
 	Nonce uuid.UUID `json:"nonce"`
 }
 
+type CreateBatchRequest struct {
+	Events []ScrubbedEvent `json:"events"`
+	// Nonce is a unique identifier for the request. Prevents "tx already exists in cache" errors from Tendermint.
+	Nonce uuid.UUID `json:"nonce"`
+}
+
 type CreateResponse struct {
+	// Metadata is the metadata of the created event. For create_batch requests, this is the metadata of the first
+	// event in the batch.
 	Metadata Metadata `json:"metadata"`
+	// Batch contains the metadata of every event created by a create_batch request, in the order the events were
+	// supplied. Empty for create requests.
+	Batch []Metadata `json:"batch,omitempty"`
+}
+
+// All returns the metadata of every event created by the transaction, in order.
+func (r CreateResponse) All() []Metadata {
+	if len(r.Batch) > 0 {
+		return r.Batch
+	}
+
+	return []Metadata{r.Metadata}
 }
 
 type EventCountResponse struct {
 	Count uint64 `json:"count"`
 }
+
+// IndexedEvent is an event returned by a listing query, along with proofs of both the index entry that matched the
+// query and the event itself.
+type IndexedEvent struct {
+	Event      EventWithMetadata `json:"event"`
+	Height     int64             `json:"height"`
+	IndexKey   []byte            `json:"index_key"`
+	IndexProof crypto.ProofOp    `json:"index_proof"`
+	EventProof crypto.ProofOp    `json:"event_proof"`
+}
+
+type EventListResponse struct {
+	Events []IndexedEvent `json:"events"`
+	// Next is the hex encoded cursor from which the next page can be fetched, or empty if there are no more events.
+	Next string `json:"next,omitempty"`
+}
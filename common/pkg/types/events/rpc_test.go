@@ -0,0 +1,27 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreateResponseAll(t *testing.T) {
+	single := CreateResponse{Metadata: Metadata{EventId: EventHash{1}}}
+	require.Equal(t, []Metadata{single.Metadata}, single.All())
+
+	batch := CreateResponse{
+		Metadata: Metadata{EventId: EventHash{1}},
+		Batch: []Metadata{
+			{EventId: EventHash{1}},
+			{EventId: EventHash{2}},
+		},
+	}
+	require.Equal(t, batch.Batch, batch.All())
+}
+
+func TestCreateResponseOmitsEmptyBatch(t *testing.T) {
+	marshalled, err := json.Marshal(CreateResponse{})
+	require.NoError(t, err)
+	require.NotContains(t, string(marshalled), "batch")
+}
@@ -44,6 +44,8 @@ type (
 		EventWithData EventWithData `json:"event" bson:"event"`
 		Metadata      Metadata      `json:"metadata" bson:"metadata"`
 		TxHash        TxHash        `json:"tx_hash" bson:"tx_hash"`
+		// Redaction is set if the event data has been erased by a redaction order, in which case EventData is empty.
+		Redaction *Redaction `json:"redaction,omitempty" bson:"redaction,omitempty"`
 	}
 
 	// EventId is the event type ID - it represents the type of the event, e.g. system log on
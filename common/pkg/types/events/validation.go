@@ -0,0 +1,77 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MaxEventId is the largest event type ID that Windows can assign to an event.
+const MaxEventId EventId = 65535
+
+var offChainHashRegex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// ValidationError is returned when an event is malformed, and carries the code with which the chain rejects it.
+type ValidationError struct {
+	Code uint32
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newValidationError(code uint32, err error) *ValidationError {
+	return &ValidationError{
+		Code: code,
+		Err:  err,
+	}
+}
+
+// ValidationAppVersion is the first app version at which created events are validated when blocks are finalized.
+// Events created at earlier versions were stored as submitted, so must still be accepted when their blocks are replayed
+// or their data is submitted off-chain.
+const ValidationAppVersion uint64 = 2
+
+// MaxBlockTimeSkew is the maximum difference between the time at which an event was created and the time of the block
+// that stores it, from ValidationAppVersion. It is a consensus rule, so can only be changed by a new app version. The
+// limit is generous, so that agents can submit the events buffered during an outage.
+const MaxBlockTimeSkew = 30 * 24 * time.Hour
+
+// Validate checks that the event is well-formed. If maxSkew is non-zero, the time at which the event was created must
+// also be within maxSkew of the given reference time, such as the time of the block that stores the event.
+func (e ScrubbedEvent) Validate(reference time.Time, maxSkew time.Duration) error {
+	if !offChainHashRegex.MatchString(e.OffChainHash) {
+		return newValidationError(CodeInvalidOffChainHash, errors.New("off-chain hash must be a hex encoded sha256 hash"))
+	}
+
+	system := e.Event.System
+	if system.Channel == "" {
+		return newValidationError(CodeMissingChannel, errors.New("channel is required"))
+	}
+
+	if (system.Provider.Name == nil || *system.Provider.Name == "") && system.Provider.Guid == nil {
+		return newValidationError(CodeMissingProvider, errors.New("provider name or GUID is required"))
+	}
+
+	if system.EventId < 0 || system.EventId > MaxEventId {
+		return newValidationError(CodeInvalidEventId, fmt.Errorf("event ID must be between 0 and %d", MaxEventId))
+	}
+
+	if maxSkew > 0 {
+		skew := system.TimeCreated.SystemTime.Sub(reference)
+		if skew > maxSkew || skew < -maxSkew {
+			return newValidationError(
+				CodeTimeSkew,
+				fmt.Errorf("event creation time %s is more than %s from %s", system.TimeCreated.SystemTime, maxSkew, reference),
+			)
+		}
+	}
+
+	return nil
+}
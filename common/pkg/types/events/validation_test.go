@@ -0,0 +1,47 @@
+package events
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func requireValidationCode(t *testing.T, code uint32, err error) {
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, code, validationErr.Code)
+}
+
+func TestScrubbedEventValidate(t *testing.T) {
+	event := ScrubbedEvent{
+		OffChainHash: hex.EncodeToString(TestEvent.EventData.Hash()),
+		Event:        TestEvent.Event,
+	}
+	reference := TestEvent.System.TimeCreated.SystemTime
+
+	require.NoError(t, event.Validate(reference, 0))
+	require.NoError(t, event.Validate(reference.Add(time.Minute), time.Minute))
+	requireValidationCode(t, CodeTimeSkew, event.Validate(reference.Add(time.Hour), time.Minute))
+	requireValidationCode(t, CodeTimeSkew, event.Validate(reference.Add(-time.Hour), time.Minute))
+
+	// The skew check is disabled if maxSkew is 0
+	require.NoError(t, event.Validate(reference.Add(time.Hour), 0))
+
+	invalid := event
+	invalid.OffChainHash = "abc"
+	requireValidationCode(t, CodeInvalidOffChainHash, invalid.Validate(reference, 0))
+
+	invalid = event
+	invalid.Event.System.Channel = ""
+	requireValidationCode(t, CodeMissingChannel, invalid.Validate(reference, 0))
+
+	invalid = event
+	invalid.Event.System.Provider = Provider{}
+	requireValidationCode(t, CodeMissingProvider, invalid.Validate(reference, 0))
+
+	invalid = event
+	invalid.Event.System.EventId = MaxEventId + 1
+	requireValidationCode(t, CodeInvalidEventId, invalid.Validate(reference, 0))
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"encoding/hex"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+)
+
+const (
+	// QueryPathWatermarks lists the record watermarks of every channel that a principal has submitted events from:
+	// /watermarks/{principal}?limit=&cursor=. The watermarks of every principal are listed if the principal is omitted.
+	QueryPathWatermarks = "/watermarks/"
+
+	// WatermarkTreeKeyPrefix prefixes the keys under which watermarks are stored in the events tree.
+	WatermarkTreeKeyPrefix = "watermark/"
+)
+
+const (
+	// EventRecordGap is emitted when the record ID of a stored event skips past record IDs that were never submitted
+	// from the same channel, indicating that events were dropped or suppressed on the host.
+	EventRecordGap = "record_gap"
+	// EventRecordRegression is emitted when the record ID of a stored event does not exceed that of the previous event
+	// submitted from the same channel, for example because the event log was cleared.
+	EventRecordRegression = "record_regression"
+
+	AttributeChannel          = "channel"
+	AttributeRecordId         = "record_id"
+	AttributePreviousRecordId = "previous_record_id"
+	AttributeMissingRecords   = "missing_records"
+
+	AttributeValueRecordGap        = "record_gap"
+	AttributeValueRecordRegression = "record_regression"
+)
+
+// RecordWatermark tracks the event record IDs that a principal has submitted from a single event log channel. Windows
+// assigns record IDs sequentially within each channel, so a jump in the record ID shows that events were not forwarded.
+type RecordWatermark struct {
+	Principal identity.Principal `json:"principal"`
+	Channel   string             `json:"channel"`
+	// LastRecordId is the record ID of the most recently stored event from the channel.
+	LastRecordId int       `json:"last_record_id"`
+	LastEventId  EventHash `json:"last_event_id"`
+	// Height is the height of the block in which the most recent event was stored.
+	Height int64 `json:"height"`
+	// MissingRecords is the total number of record IDs skipped by the events stored from the channel.
+	MissingRecords uint64 `json:"missing_records"`
+	// Regressions is the number of times that the record ID of a stored event did not follow on from the previous one.
+	Regressions uint64 `json:"regressions"`
+}
+
+// ProvenWatermark is a watermark returned by a listing query, along with a proof of its presence in the events tree.
+type ProvenWatermark struct {
+	Watermark RecordWatermark `json:"watermark"`
+	Key       []byte          `json:"key"`
+	Proof     crypto.ProofOp  `json:"proof"`
+}
+
+type WatermarkListResponse struct {
+	Watermarks []ProvenWatermark `json:"watermarks"`
+	// Next is the hex encoded cursor from which the next page can be fetched, or empty if there are no more watermarks.
+	Next string `json:"next,omitempty"`
+}
+
+// Observe advances the watermark to an event with the given record ID, returning the number of records skipped since
+// the previous event, and whether the record ID went backwards instead.
+func (w *RecordWatermark) Observe(recordId int) (missing int, regression bool) {
+	switch {
+	case recordId > w.LastRecordId+1:
+		missing = recordId - w.LastRecordId - 1
+		w.MissingRecords += uint64(missing)
+	case recordId <= w.LastRecordId:
+		regression = true
+		w.Regressions++
+	}
+
+	// Follow the new record ID even if it went backwards, as it does when the event log is cleared
+	w.LastRecordId = recordId
+	return missing, regression
+}
+
+// WatermarkPrincipalPrefix returns the prefix of the keys under which the watermarks of a principal are stored.
+// Principals and channels are hex encoded, so that a separator within them cannot match the entries of another.
+func WatermarkPrincipalPrefix(principal identity.Principal) string {
+	return WatermarkTreeKeyPrefix + hex.EncodeToString(principal.Bytes()) + "/"
+}
+
+// WatermarkTreeKey returns the key under which the watermark of a principal's channel is stored in the events tree,
+// against which watermark queries are proven. The key is always an odd number of bytes long, so it cannot collide with
+// a 32 byte event ID.
+func WatermarkTreeKey(principal identity.Principal, channel string) []byte {
+	return []byte(WatermarkPrincipalPrefix(principal) + hex.EncodeToString([]byte(channel)))
+}
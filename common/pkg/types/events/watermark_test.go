@@ -0,0 +1,37 @@
+package events
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRecordWatermarkObserve(t *testing.T) {
+	watermark := RecordWatermark{LastRecordId: 10}
+
+	missing, regression := watermark.Observe(11)
+	require.Zero(t, missing)
+	require.False(t, regression)
+
+	missing, regression = watermark.Observe(15)
+	require.Equal(t, 3, missing)
+	require.False(t, regression)
+	require.Equal(t, uint64(3), watermark.MissingRecords)
+
+	// The watermark follows record IDs that go backwards, so that a cleared log is only reported once
+	missing, regression = watermark.Observe(1)
+	require.Zero(t, missing)
+	require.True(t, regression)
+	require.Equal(t, 1, watermark.LastRecordId)
+
+	missing, regression = watermark.Observe(2)
+	require.Zero(t, missing)
+	require.False(t, regression)
+	require.Equal(t, uint64(1), watermark.Regressions)
+}
+
+func TestWatermarkTreeKey(t *testing.T) {
+	// Channel names commonly contain the separator
+	key := WatermarkTreeKey("agent", "Microsoft-Windows-Sysmon/Operational")
+	require.Equal(t, 1, len(key)%2)
+	require.NotEqual(t, WatermarkTreeKey("agent", "a/b"), WatermarkTreeKey("agent/a", "b"))
+}
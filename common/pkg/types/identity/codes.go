@@ -12,4 +12,13 @@ const (
 	CodePrincipalAlreadyExists
 	CodeTreeUninitialized
 	CodeUnknownError
+	CodeInvalidKey
+	CodeKeyRotationPending
+	CodePrincipalRevoked
+	CodePrincipalSuspended
+	CodeInvalidStatusTransition
+	CodeStatusChangePending
+	CodeInvalidRole
+	CodeInvalidSequence
+	CodeRotationConflict
 )
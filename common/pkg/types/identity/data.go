@@ -9,28 +9,123 @@ import (
 type IdentityData struct {
 	PublicKey ed25519.PublicKey `json:"public_key"`
 	Role      Role              `json:"role"`
+	// PreviousKeys contains the keys that were previously assigned to the principal, in the order they were retired.
+	PreviousKeys []RetiredKey `json:"previous_keys"`
+	// Status is the current status of the principal. Principals registered before statuses were introduced have an
+	// empty status, which is treated as active.
+	Status Status `json:"status"`
+	// StatusChangedAt is the height of the block in which the status of the principal was last changed. For revoked
+	// principals, this is the revocation height.
+	StatusChangedAt int64 `json:"status_changed_at,omitempty"`
+}
+
+// RetiredKey is a public key that was previously assigned to a principal. The key was valid for all blocks up to and
+// including RetiredAt, the height of the block containing the rotation, after which it was replaced by a newer key.
+// Principals cannot create events in the block containing the rotation, so the key that signed the data of an event is
+// unambiguous.
+type RetiredKey struct {
+	PublicKey ed25519.PublicKey `json:"public_key"`
+	RetiredAt int64             `json:"retired_at"`
 }
 
 func (i IdentityData) IsAdmin() bool {
 	return i.Role == RoleAdmin
 }
 
+// Can returns true if the principal's role has been granted the given permission. The principal's status is not taken
+// into account.
+func (i IdentityData) Can(permission Permission) bool {
+	return i.Role.Can(permission)
+}
+
+// IsActive returns true if the principal has not been suspended or revoked.
+func (i IdentityData) IsActive() bool {
+	return i.Status == "" || i.Status == StatusActive
+}
+
+func (i IdentityData) IsSuspended() bool {
+	return i.Status == StatusSuspended
+}
+
+func (i IdentityData) IsRevoked() bool {
+	return i.Status == StatusRevoked
+}
+
+// RevokedAt returns the height at which the principal was revoked, or 0 if the principal has not been revoked.
+func (i IdentityData) RevokedAt() int64 {
+	if !i.IsRevoked() {
+		return 0
+	}
+
+	return i.StatusChangedAt
+}
+
+// KeyAt returns the public key that was assigned to the principal at the given block height. A height of 0 returns the
+// current key.
+func (i IdentityData) KeyAt(height int64) ed25519.PublicKey {
+	if height <= 0 {
+		return i.PublicKey
+	}
+
+	for _, key := range i.PreviousKeys {
+		if height <= key.RetiredAt {
+			return key.PublicKey
+		}
+	}
+
+	return i.PublicKey
+}
+
+// KeyRotatedAt returns true if the principal's key was rotated in the block at the given height.
+func (i IdentityData) KeyRotatedAt(height int64) bool {
+	return len(i.PreviousKeys) > 0 && i.PreviousKeys[len(i.PreviousKeys)-1].RetiredAt == height
+}
+
+// HasUsedKey returns true if the key is the principal's current key, or was previously assigned to the principal.
+func (i IdentityData) HasUsedKey(key ed25519.PublicKey) bool {
+	if i.PublicKey.Equal(key) {
+		return true
+	}
+
+	for _, retired := range i.PreviousKeys {
+		if retired.PublicKey.Equal(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // MarshalJSON Custom marshaller to encode public key as hex string
 func (i IdentityData) MarshalJSON() ([]byte, error) {
+	previousKeys := i.PreviousKeys
+	if previousKeys == nil {
+		previousKeys = make([]RetiredKey, 0)
+	}
+
 	return json.Marshal(struct {
-		PublicKey string `json:"public_key"`
-		Role      Role   `json:"role"`
+		PublicKey       string       `json:"public_key"`
+		Role            Role         `json:"role"`
+		PreviousKeys    []RetiredKey `json:"previous_keys"`
+		Status          Status       `json:"status,omitempty"`
+		StatusChangedAt int64        `json:"status_changed_at,omitempty"`
 	}{
-		PublicKey: hex.EncodeToString(i.PublicKey),
-		Role:      i.Role,
+		PublicKey:       hex.EncodeToString(i.PublicKey),
+		Role:            i.Role,
+		PreviousKeys:    previousKeys,
+		Status:          i.Status,
+		StatusChangedAt: i.StatusChangedAt,
 	})
 }
 
 // UnmarshalJSON Custom unmarshaller to decode public key from hex string
 func (i *IdentityData) UnmarshalJSON(data []byte) error {
 	var aux struct {
-		PublicKey string `json:"public_key"`
-		Role      Role   `json:"role"`
+		PublicKey       string       `json:"public_key"`
+		Role            Role         `json:"role"`
+		PreviousKeys    []RetiredKey `json:"previous_keys"`
+		Status          Status       `json:"status"`
+		StatusChangedAt int64        `json:"status_changed_at"`
 	}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
@@ -44,6 +139,42 @@ func (i *IdentityData) UnmarshalJSON(data []byte) error {
 
 	i.PublicKey = publicKey
 	i.Role = aux.Role
+	i.PreviousKeys = aux.PreviousKeys
+	i.Status = aux.Status
+	i.StatusChangedAt = aux.StatusChangedAt
+
+	return nil
+}
+
+// MarshalJSON Custom marshaller to encode public key as hex string
+func (k RetiredKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		PublicKey string `json:"public_key"`
+		RetiredAt int64  `json:"retired_at"`
+	}{
+		PublicKey: hex.EncodeToString(k.PublicKey),
+		RetiredAt: k.RetiredAt,
+	})
+}
+
+// UnmarshalJSON Custom unmarshaller to decode public key from hex string
+func (k *RetiredKey) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		PublicKey string `json:"public_key"`
+		RetiredAt int64  `json:"retired_at"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	publicKey, err := hex.DecodeString(aux.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	k.PublicKey = publicKey
+	k.RetiredAt = aux.RetiredAt
 
 	return nil
 }
@@ -0,0 +1,89 @@
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKeyAt(t *testing.T) {
+	first, second, current := generateKey(t), generateKey(t), generateKey(t)
+
+	data := IdentityData{
+		PublicKey: current,
+		Role:      RoleUser,
+		PreviousKeys: []RetiredKey{
+			{PublicKey: first, RetiredAt: 10},
+			{PublicKey: second, RetiredAt: 20},
+		},
+	}
+
+	require.Equal(t, current, data.KeyAt(0))
+	require.Equal(t, first, data.KeyAt(1))
+	require.Equal(t, first, data.KeyAt(10))
+	require.Equal(t, second, data.KeyAt(11))
+	require.Equal(t, second, data.KeyAt(20))
+	require.Equal(t, current, data.KeyAt(21))
+
+	require.True(t, data.KeyRotatedAt(20))
+	require.False(t, data.KeyRotatedAt(10))
+	require.False(t, data.KeyRotatedAt(21))
+
+	require.True(t, data.HasUsedKey(first))
+	require.True(t, data.HasUsedKey(current))
+	require.False(t, data.HasUsedKey(generateKey(t)))
+}
+
+func TestIdentityDataSerialisation(t *testing.T) {
+	data := IdentityData{
+		PublicKey: generateKey(t),
+		Role:      RoleAdmin,
+		PreviousKeys: []RetiredKey{
+			{PublicKey: generateKey(t), RetiredAt: 5},
+		},
+	}
+
+	marshalled, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	var decoded IdentityData
+	require.NoError(t, json.Unmarshal(marshalled, &decoded))
+	require.Equal(t, data, decoded)
+}
+
+func TestStatus(t *testing.T) {
+	legacy := IdentityData{PublicKey: generateKey(t), Role: RoleUser}
+	require.True(t, legacy.IsActive())
+	require.Zero(t, legacy.RevokedAt())
+
+	suspended := IdentityData{PublicKey: generateKey(t), Role: RoleUser, Status: StatusSuspended, StatusChangedAt: 7}
+	require.False(t, suspended.IsActive())
+	require.True(t, suspended.IsSuspended())
+	require.Zero(t, suspended.RevokedAt())
+
+	revoked := IdentityData{
+		PublicKey:       generateKey(t),
+		Role:            RoleUser,
+		PreviousKeys:    []RetiredKey{},
+		Status:          StatusRevoked,
+		StatusChangedAt: 12,
+	}
+	require.True(t, revoked.IsRevoked())
+	require.Equal(t, int64(12), revoked.RevokedAt())
+
+	marshalled, err := json.Marshal(revoked)
+	require.NoError(t, err)
+
+	var decoded IdentityData
+	require.NoError(t, json.Unmarshal(marshalled, &decoded))
+	require.Equal(t, revoked, decoded)
+}
+
+func generateKey(t *testing.T) ed25519.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoErrorf(t, err, "failed to generate key pair: %v", err)
+
+	return pub
+}
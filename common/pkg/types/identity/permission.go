@@ -0,0 +1,48 @@
+package identity
+
+type Permission string
+
+const (
+	// PermissionCreateEvents allows a principal to submit events to the events app.
+	PermissionCreateEvents Permission = "events.create"
+	// PermissionUseViewer allows a principal to authenticate with the viewer, and search and view events.
+	PermissionUseViewer Permission = "viewer.use"
+	// PermissionManagePolicy allows a principal to set the retention policy.
+	PermissionManagePolicy Permission = "retention_policy.manage"
+	// PermissionManageIdentities allows a principal to register new principals, and to rotate the keys and change
+	// the statuses of other principals.
+	PermissionManageIdentities Permission = "identity.manage"
+	// PermissionManageValidators allows a principal to add and remove validators, and to change their voting power.
+	PermissionManageValidators Permission = "validators.manage"
+	// PermissionRedactEvents allows a principal to order off-chain nodes to erase the data of events.
+	PermissionRedactEvents Permission = "events.redact"
+	// PermissionManageLegalHolds allows a principal to place and release legal holds, which protect events from
+	// deletion by the retention policy.
+	PermissionManageLegalHolds Permission = "retention_policy.hold"
+	// PermissionScheduleUpgrades allows a principal to schedule and cancel upgrades of the application.
+	PermissionScheduleUpgrades Permission = "upgrade.schedule"
+)
+
+// rolePermissions is the central permission matrix, consulted by each application and the viewer to decide whether a
+// principal may perform an action.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionCreateEvents,
+		PermissionUseViewer,
+		PermissionManagePolicy,
+		PermissionManageIdentities,
+		PermissionManageValidators,
+		PermissionRedactEvents,
+		PermissionManageLegalHolds,
+		PermissionScheduleUpgrades,
+	},
+	RoleUser:          {PermissionCreateEvents},
+	RoleAgent:         {PermissionCreateEvents},
+	RoleAuditor:       {PermissionUseViewer},
+	RolePolicyAdmin:   {PermissionManagePolicy},
+	RoleIdentityAdmin: {PermissionManageIdentities},
+}
+
+func (p Permission) String() string {
+	return string(p)
+}
@@ -9,3 +9,12 @@ func (p Principal) String() string {
 func (p Principal) Bytes() []byte {
 	return []byte(p.String())
 }
+
+// TreeKeyPrefix prefixes the keys under which identities are stored in the identity app's tree.
+const TreeKeyPrefix = "principal/"
+
+// TreeKey returns the key under which the principal's identity is stored in the identity app's tree, against which
+// proofs of the identity are generated.
+func (p Principal) TreeKey() []byte {
+	return []byte(TreeKeyPrefix + p)
+}
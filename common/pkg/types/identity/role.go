@@ -3,10 +3,40 @@ package identity
 type Role string
 
 const (
+	// RoleAdmin principals hold every permission.
 	RoleAdmin Role = "admin"
-	RoleUser  Role = "user"
+	// RoleUser is the legacy non-administrator role, and is equivalent to RoleAgent.
+	RoleUser Role = "user"
+	// RoleAgent principals may only submit events.
+	RoleAgent Role = "agent"
+	// RoleAuditor principals may only search and view events through the viewer.
+	RoleAuditor Role = "auditor"
+	// RolePolicyAdmin principals may manage the retention policy.
+	RolePolicyAdmin Role = "policy-admin"
+	// RoleIdentityAdmin principals may register principals, and manage the keys and statuses of existing principals.
+	RoleIdentityAdmin Role = "identity-admin"
 )
 
+// Roles contains every valid role, in the order they should be presented to users.
+var Roles = []Role{RoleAgent, RoleAuditor, RolePolicyAdmin, RoleIdentityAdmin, RoleAdmin, RoleUser}
+
+// IsValid returns true if the role is one of the roles known to the permission matrix.
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can returns true if the role has been granted the given permission. Unknown roles hold no permissions.
+func (r Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (r Role) String() string {
 	return string(r)
 }
@@ -0,0 +1,52 @@
+package identity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRoleIsValid(t *testing.T) {
+	for _, role := range Roles {
+		require.Truef(t, role.IsValid(), "role %s should be valid", role)
+	}
+
+	require.False(t, Role("").IsValid())
+	require.False(t, Role("superuser").IsValid())
+}
+
+func TestRolePermissions(t *testing.T) {
+	allPermissions := []Permission{
+		PermissionCreateEvents,
+		PermissionUseViewer,
+		PermissionManagePolicy,
+		PermissionManageIdentities,
+		PermissionManageValidators,
+		PermissionRedactEvents,
+		PermissionManageLegalHolds,
+		PermissionScheduleUpgrades,
+	}
+
+	for _, permission := range allPermissions {
+		require.Truef(t, RoleAdmin.Can(permission), "admin should hold %s", permission)
+		require.Falsef(t, Role("superuser").Can(permission), "unknown roles should not hold %s", permission)
+	}
+
+	require.True(t, RoleAgent.Can(PermissionCreateEvents))
+	require.False(t, RoleAgent.Can(PermissionUseViewer))
+	require.False(t, RoleAgent.Can(PermissionRedactEvents))
+	require.False(t, RolePolicyAdmin.Can(PermissionManageLegalHolds))
+
+	require.True(t, RoleUser.Can(PermissionCreateEvents))
+	require.False(t, RoleUser.Can(PermissionManageIdentities))
+
+	require.True(t, RoleAuditor.Can(PermissionUseViewer))
+	require.False(t, RoleAuditor.Can(PermissionCreateEvents))
+
+	require.True(t, RolePolicyAdmin.Can(PermissionManagePolicy))
+	require.False(t, RolePolicyAdmin.Can(PermissionManageIdentities))
+
+	require.True(t, RoleIdentityAdmin.Can(PermissionManageIdentities))
+	require.False(t, RoleIdentityAdmin.Can(PermissionManagePolicy))
+	require.False(t, RoleIdentityAdmin.Can(PermissionManageValidators))
+	require.False(t, RoleIdentityAdmin.Can(PermissionScheduleUpgrades))
+}
@@ -14,8 +14,27 @@ const (
 	RequestTypeSeed = "seed"
 	// RequestTypeRegister is used to register a new user
 	RequestTypeRegister = "register"
+	// RequestTypeRotateKey is used to replace the public key of an existing principal
+	RequestTypeRotateKey = "rotate_key"
+	// RequestTypeSuspend is used to temporarily prevent a principal from submitting transactions
+	RequestTypeSuspend = "suspend"
+	// RequestTypeReinstate is used to lift the suspension of a principal
+	RequestTypeReinstate = "reinstate"
+	// RequestTypeRevoke is used to permanently prevent a principal from submitting transactions
+	RequestTypeRevoke = "revoke"
 )
 
+// QueryPathSequence is the prefix of the query path returning the next sequence number expected of a principal, in the
+// form /sequence/{principal}.
+const QueryPathSequence = "/sequence/"
+
+// SequenceResponse is returned by the sequence query.
+type SequenceResponse struct {
+	Principal Principal `json:"principal"`
+	// Sequence is the sequence number that the next signed request from the principal must carry.
+	Sequence uint64 `json:"sequence"`
+}
+
 type PayloadSeed struct {
 	Principal Principal         `json:"principal"` // The principal to register as the admin user
 	Key       ed25519.PublicKey // The Ed25519 public key of the principal. Hex encoded in transit.
@@ -98,3 +117,51 @@ func (p *PayloadRegister) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+type PayloadRotateKey struct {
+	Principal Principal         `json:"principal"`  // The principal whose key is being replaced
+	Key       ed25519.PublicKey `json:"public_key"` // The new Ed25519 public key of the principal. Hex encoded in transit.
+}
+
+// MarshalJSON Custom Marshaller to encode public key as hex string
+func (p PayloadRotateKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Principal Principal `json:"principal"`
+		Key       string    `json:"public_key"`
+		Nonce     string    `json:"nonce"` // Prevents CometBFT's "tx already exists in cache" error.
+	}{
+		Principal: p.Principal,
+		Key:       hex.EncodeToString(p.Key),
+		Nonce:     uuid.New().String(),
+	})
+}
+
+// UnmarshalJSON Custom unmarshaller to decode public key from hex string
+func (p *PayloadRotateKey) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Principal Principal `json:"principal"`
+		Key       string    `json:"public_key"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	key, err := hex.DecodeString(aux.Key)
+	if err != nil {
+		return err
+	}
+
+	p.Principal = aux.Principal
+	p.Key = key
+
+	return nil
+}
+
+// PayloadStatusChange is used by the suspend, reinstate and revoke request types.
+type PayloadStatusChange struct {
+	Principal Principal `json:"principal"` // The principal whose status is being changed
+	Reason    string    `json:"reason"`    // A human-readable justification for the change, for auditing purposes
+	// Nonce is a unique identifier for the request. Prevents "tx already exists in cache" errors from Tendermint.
+	Nonce uuid.UUID `json:"nonce"`
+}
@@ -0,0 +1,16 @@
+package identity
+
+type Status string
+
+const (
+	// StatusActive principals may submit transactions as normal.
+	StatusActive Status = "active"
+	// StatusSuspended principals are temporarily prevented from submitting transactions, and may be reinstated.
+	StatusSuspended Status = "suspended"
+	// StatusRevoked principals are permanently prevented from submitting transactions.
+	StatusRevoked Status = "revoked"
+)
+
+func (s Status) String() string {
+	return string(s)
+}
@@ -0,0 +1,122 @@
+package offchain
+
+import (
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// MaxHoldCriteria is the maximum number of principals, channels or event IDs that a legal hold may match on.
+const MaxHoldCriteria = 100
+
+type (
+	// LegalHold prevents events matching its criteria from being deleted by the retention policy, for example while an
+	// incident is under investigation. Holds are never removed from the chain: once released or expired, they simply
+	// stop protecting events.
+	LegalHold struct {
+		// Id is assigned sequentially, starting from 1.
+		Id       uint64             `json:"id"`
+		Criteria HoldCriteria       `json:"criteria"`
+		Reason   string             `json:"reason"`
+		Author   identity.Principal `json:"author"`
+		// PlacedAt is the height of the block in which the hold was placed.
+		PlacedAt int64 `json:"placed_at"`
+		// ExpiresAt is the time after which the hold no longer protects events, or nil if the hold does not expire.
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+		// ReleasedAt is the height of the block in which the hold was released, or 0 if it has not been released.
+		ReleasedAt int64              `json:"released_at,omitempty"`
+		ReleasedBy identity.Principal `json:"released_by,omitempty"`
+	}
+
+	// HoldCriteria selects the events protected by a legal hold. An event matches if it satisfies every criterion that
+	// is set, and a list criterion is satisfied if the event matches any of its entries.
+	HoldCriteria struct {
+		Principals []identity.Principal `json:"principals,omitempty"`
+		Channels   []string             `json:"channels,omitempty"`
+		EventIds   []events.EventHash   `json:"event_ids,omitempty"`
+		// From and To bound the time at which events were received by the blockchain (inclusive).
+		From *time.Time `json:"from,omitempty"`
+		To   *time.Time `json:"to,omitempty"`
+	}
+
+	// LegalHolds contains every hold that has been placed, ordered by ID.
+	LegalHolds []LegalHold
+)
+
+func (c HoldCriteria) Validate() error {
+	if len(c.Principals) == 0 && len(c.Channels) == 0 && len(c.EventIds) == 0 && c.From == nil && c.To == nil {
+		return errors.New("at least one criterion must be set")
+	}
+
+	if len(c.Principals) > MaxHoldCriteria || len(c.Channels) > MaxHoldCriteria || len(c.EventIds) > MaxHoldCriteria {
+		return fmt.Errorf("each criterion may contain at most %d entries", MaxHoldCriteria)
+	}
+
+	if c.From != nil && c.To != nil && c.From.After(*c.To) {
+		return errors.New("from must not be after to")
+	}
+
+	return nil
+}
+
+// Matches returns true if the event satisfies every criterion that is set. Principals and channels are compared
+// case-insensitively, as they are by every event repository.
+func (c HoldCriteria) Matches(event events.StoredEvent) bool {
+	if len(c.Principals) > 0 && !containsFunc(c.Principals, func(p identity.Principal) bool {
+		return strings.EqualFold(p.String(), event.Metadata.Principal.String())
+	}) {
+		return false
+	}
+
+	if len(c.Channels) > 0 && !containsFunc(c.Channels, func(channel string) bool {
+		return strings.EqualFold(channel, event.EventWithData.Event.System.Channel)
+	}) {
+		return false
+	}
+
+	if len(c.EventIds) > 0 && !containsFunc(c.EventIds, func(id events.EventHash) bool {
+		return id.String() == event.Metadata.EventId.String()
+	}) {
+		return false
+	}
+
+	if c.From != nil && event.Metadata.ReceivedTime.Before(*c.From) {
+		return false
+	}
+
+	if c.To != nil && event.Metadata.ReceivedTime.After(*c.To) {
+		return false
+	}
+
+	return true
+}
+
+// IsActive returns true if the hold has not been released, and has not expired by the given time.
+func (h LegalHold) IsActive(now time.Time) bool {
+	return h.ReleasedAt == 0 && (h.ExpiresAt == nil || now.Before(*h.ExpiresAt))
+}
+
+// Active returns the holds that are active at the given time.
+func (h LegalHolds) Active(now time.Time) LegalHolds {
+	active := make(LegalHolds, 0)
+	for _, hold := range h {
+		if hold.IsActive(now) {
+			active = append(active, hold)
+		}
+	}
+
+	return active
+}
+
+func containsFunc[T any](values []T, f func(T) bool) bool {
+	for _, value := range values {
+		if f(value) {
+			return true
+		}
+	}
+
+	return false
+}
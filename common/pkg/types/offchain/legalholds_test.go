@@ -0,0 +1,65 @@
+package offchain
+
+import (
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHoldCriteriaValidate(t *testing.T) {
+	from, to := time.Unix(100, 0), time.Unix(200, 0)
+
+	require.Error(t, HoldCriteria{}.Validate())
+	require.NoError(t, HoldCriteria{Channels: []string{"Security"}}.Validate())
+	require.NoError(t, HoldCriteria{From: &from, To: &to}.Validate())
+	require.Error(t, HoldCriteria{From: &to, To: &from}.Validate())
+	require.Error(t, HoldCriteria{Principals: make([]identity.Principal, MaxHoldCriteria+1)}.Validate())
+}
+
+func TestHoldCriteriaMatches(t *testing.T) {
+	event := events.StoredEvent{
+		EventWithData: events.EventWithData{
+			Event: events.Event{System: events.System{Channel: "Security"}},
+		},
+		Metadata: events.Metadata{
+			EventId:      events.EventHash{0x01},
+			ReceivedTime: time.Unix(150, 0),
+			Principal:    "agent",
+		},
+	}
+
+	from, to := time.Unix(100, 0), time.Unix(200, 0)
+	late := time.Unix(175, 0)
+
+	require.True(t, HoldCriteria{Principals: []identity.Principal{"other", "agent"}}.Matches(event))
+	require.True(t, HoldCriteria{Channels: []string{"Security"}, From: &from, To: &to}.Matches(event))
+	require.True(t, HoldCriteria{EventIds: []events.EventHash{{0x01}}}.Matches(event))
+
+	// Principals and channels are compared case-insensitively
+	require.True(t, HoldCriteria{Principals: []identity.Principal{"Agent"}}.Matches(event))
+	require.True(t, HoldCriteria{Channels: []string{"SECURITY"}}.Matches(event))
+
+	// Every criterion that is set must be satisfied
+	require.False(t, HoldCriteria{Principals: []identity.Principal{"agent"}, Channels: []string{"System"}}.Matches(event))
+	require.False(t, HoldCriteria{EventIds: []events.EventHash{{0x02}}}.Matches(event))
+	require.False(t, HoldCriteria{From: &late}.Matches(event))
+}
+
+func TestLegalHoldsActive(t *testing.T) {
+	now := time.Unix(1000, 0)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	holds := LegalHolds{
+		{Id: 1},
+		{Id: 2, ExpiresAt: &past},
+		{Id: 3, ExpiresAt: &future},
+		{Id: 4, ReleasedAt: 10},
+	}
+
+	active := holds.Active(now)
+	require.Len(t, active, 2)
+	require.Equal(t, uint64(1), active[0].Id)
+	require.Equal(t, uint64(3), active[1].Id)
+}
@@ -14,8 +14,17 @@ type (
 		Policy    RetentionPolicy    `json:"policy"`
 		Author    identity.Principal `json:"author"`
 		AppliedAt time.Time          `json:"applied_at"`
+		// Version is incremented each time the policy is amended, starting from 1 for the initial policy.
+		Version uint64 `json:"version"`
+		// EffectiveHeight is the block height from which this version of the policy is enforced.
+		EffectiveHeight int64 `json:"effective_height"`
+		// ProposalId is the ID of the proposal that introduced this version, or 0 for the initial policy.
+		ProposalId uint64 `json:"proposal_id,omitempty"`
 	}
 
+	// PolicyHistory contains every version of the retention policy, ordered by version.
+	PolicyHistory []StoredPolicy
+
 	RetentionPolicy struct {
 		Filters []Filter `yaml:"filters" json:"filters"`
 	}
@@ -111,7 +120,49 @@ func (p RetentionPolicy) Validate() error {
 }
 
 func (p StoredPolicy) Equal(other StoredPolicy) bool {
-	return p.Policy.Equal(other.Policy) && p.Author == other.Author && p.AppliedAt.Equal(other.AppliedAt)
+	return p.Policy.Equal(other.Policy) &&
+		p.Author == other.Author &&
+		p.AppliedAt.Equal(other.AppliedAt) &&
+		p.Version == other.Version &&
+		p.EffectiveHeight == other.EffectiveHeight &&
+		p.ProposalId == other.ProposalId
+}
+
+// At returns the version of the policy that was in effect at the given block height, or nil if no policy had taken
+// effect by then.
+func (h PolicyHistory) At(height int64) *StoredPolicy {
+	var effective *StoredPolicy
+	for i := range h {
+		if h[i].EffectiveHeight <= height {
+			effective = &h[i]
+		}
+	}
+
+	return effective
+}
+
+// Latest returns the most recent version of the policy, which may not have taken effect yet, or nil if no policy
+// has been set.
+func (h PolicyHistory) Latest() *StoredPolicy {
+	if len(h) == 0 {
+		return nil
+	}
+
+	return &h[len(h)-1]
+}
+
+func (h PolicyHistory) Equal(other PolicyHistory) bool {
+	if len(h) != len(other) {
+		return false
+	}
+
+	for i := range h {
+		if !h[i].Equal(other[i]) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (p RetentionPolicy) Equal(other RetentionPolicy) bool {
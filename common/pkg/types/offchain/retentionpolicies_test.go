@@ -0,0 +1,22 @@
+package offchain
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPolicyHistoryAt(t *testing.T) {
+	history := PolicyHistory{
+		{Version: 1, EffectiveHeight: 10},
+		{Version: 2, EffectiveHeight: 20},
+		{Version: 3, EffectiveHeight: 20},
+	}
+
+	require.Nil(t, history.At(9))
+	require.Equal(t, uint64(1), history.At(10).Version)
+	require.Equal(t, uint64(1), history.At(19).Version)
+	require.Equal(t, uint64(3), history.At(20).Version)
+	require.Equal(t, uint64(3), history.Latest().Version)
+
+	require.Nil(t, PolicyHistory{}.Latest())
+}
@@ -10,4 +10,13 @@ const (
 	CodePolicyAlreadySet
 	CodePolicyNotSet
 	CodeInvalidPolicy
+	CodeInvalidQueryPath
+	CodeProposalNotFound
+	CodeProposalClosed
+	CodeNotEligibleVoter
+	CodeAlreadyVoted
+	CodeTreeUninitialized
+	CodeInvalidHold
+	CodeHoldNotFound
+	CodeHoldReleased
 )
@@ -0,0 +1,115 @@
+package retention
+
+import (
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+)
+
+type ProposalStatus string
+
+const (
+	// ProposalStatusPending proposals are still accepting votes.
+	ProposalStatusPending ProposalStatus = "pending"
+	// ProposalStatusApproved proposals reached the approval threshold, and their policy takes effect at
+	// ActivationHeight.
+	ProposalStatusApproved ProposalStatus = "approved"
+	// ProposalStatusRejected proposals received enough rejections that the approval threshold can no longer be met.
+	ProposalStatusRejected ProposalStatus = "rejected"
+)
+
+// Proposal is an amendment to the retention policy that must be approved by a quorum of the principals that were
+// permitted to manage the policy at the time the proposal was made.
+type Proposal struct {
+	Id         uint64                   `json:"id"`
+	Policy     offchain.RetentionPolicy `json:"policy"`
+	Proposer   identity.Principal       `json:"proposer"`
+	ProposedAt int64                    `json:"proposed_at"`
+	// Voters is the snapshot of principals eligible to vote on the proposal.
+	Voters []identity.Principal `json:"voters"`
+	// Threshold is the number of approvals required for the proposal to be approved.
+	Threshold  int                  `json:"threshold"`
+	Approvals  []identity.Principal `json:"approvals"`
+	Rejections []identity.Principal `json:"rejections"`
+	Status     ProposalStatus       `json:"status"`
+	// ClosedAt is the height of the block in which the proposal was approved or rejected.
+	ClosedAt int64 `json:"closed_at,omitempty"`
+	// ActivationHeight is the height from which the proposed policy takes effect, if the proposal was approved.
+	ActivationHeight int64 `json:"activation_height,omitempty"`
+}
+
+// QuorumThreshold returns the number of approvals required from the given number of eligible voters: a simple
+// majority.
+func QuorumThreshold(voters int) int {
+	return voters/2 + 1
+}
+
+// NewProposal creates a pending proposal. The proposer is counted as the first approval, and so the proposal may be
+// approved immediately if they are the only eligible voter.
+func NewProposal(
+	id uint64,
+	policy offchain.RetentionPolicy,
+	proposer identity.Principal,
+	voters []identity.Principal,
+	height int64,
+) Proposal {
+	proposal := Proposal{
+		Id:         id,
+		Policy:     policy,
+		Proposer:   proposer,
+		ProposedAt: height,
+		Voters:     voters,
+		Threshold:  QuorumThreshold(len(voters)),
+		Approvals:  []identity.Principal{},
+		Rejections: []identity.Principal{},
+		Status:     ProposalStatusPending,
+	}
+
+	return proposal.WithVote(proposer, true, height)
+}
+
+func (p Proposal) IsPending() bool {
+	return p.Status == ProposalStatusPending
+}
+
+func (p Proposal) IsEligible(principal identity.Principal) bool {
+	return contains(p.Voters, principal)
+}
+
+func (p Proposal) HasVoted(principal identity.Principal) bool {
+	return contains(p.Approvals, principal) || contains(p.Rejections, principal)
+}
+
+// WithVote returns a copy of the proposal with the vote recorded, and the status updated if the vote closed the
+// proposal. The receiver is not modified. Callers must check that the voter is eligible and has not already voted.
+func (p Proposal) WithVote(principal identity.Principal, approve bool, height int64) Proposal {
+	updated := p
+	updated.Approvals = append(make([]identity.Principal, 0, len(p.Approvals)+1), p.Approvals...)
+	updated.Rejections = append(make([]identity.Principal, 0, len(p.Rejections)+1), p.Rejections...)
+
+	if approve {
+		updated.Approvals = append(updated.Approvals, principal)
+	} else {
+		updated.Rejections = append(updated.Rejections, principal)
+	}
+
+	if len(updated.Approvals) >= updated.Threshold {
+		updated.Status = ProposalStatusApproved
+		updated.ClosedAt = height
+		updated.ActivationHeight = height + PolicyActivationDelay
+	} else if len(updated.Rejections) > len(updated.Voters)-updated.Threshold {
+		updated.Status = ProposalStatusRejected
+		updated.ClosedAt = height
+	}
+
+	return updated
+}
+
+func contains(principals []identity.Principal, principal identity.Principal) bool {
+	for _, p := range principals {
+		if p == principal {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,56 @@
+package retention
+
+import (
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQuorumThreshold(t *testing.T) {
+	require.Equal(t, 1, QuorumThreshold(1))
+	require.Equal(t, 2, QuorumThreshold(2))
+	require.Equal(t, 2, QuorumThreshold(3))
+	require.Equal(t, 3, QuorumThreshold(4))
+	require.Equal(t, 3, QuorumThreshold(5))
+}
+
+func TestProposalSoleVoterApprovesImmediately(t *testing.T) {
+	proposal := NewProposal(1, offchain.RetentionPolicy{}, "alice", []identity.Principal{"alice"}, 5)
+
+	require.Equal(t, ProposalStatusApproved, proposal.Status)
+	require.Equal(t, int64(5), proposal.ClosedAt)
+	require.Equal(t, 5+PolicyActivationDelay, proposal.ActivationHeight)
+}
+
+func TestProposalApproval(t *testing.T) {
+	voters := []identity.Principal{"alice", "bob", "carol"}
+	proposal := NewProposal(1, offchain.RetentionPolicy{}, "alice", voters, 5)
+
+	require.True(t, proposal.IsPending())
+	require.True(t, proposal.HasVoted("alice"))
+	require.False(t, proposal.HasVoted("bob"))
+	require.True(t, proposal.IsEligible("carol"))
+	require.False(t, proposal.IsEligible("mallory"))
+
+	approved := proposal.WithVote("bob", true, 7)
+	require.Equal(t, ProposalStatusApproved, approved.Status)
+	require.Equal(t, 7+PolicyActivationDelay, approved.ActivationHeight)
+
+	// The original proposal must not be modified
+	require.True(t, proposal.IsPending())
+	require.Len(t, proposal.Approvals, 1)
+}
+
+func TestProposalRejection(t *testing.T) {
+	voters := []identity.Principal{"alice", "bob", "carol", "dave"}
+	proposal := NewProposal(1, offchain.RetentionPolicy{}, "alice", voters, 5)
+
+	proposal = proposal.WithVote("bob", false, 6)
+	require.True(t, proposal.IsPending())
+
+	proposal = proposal.WithVote("carol", false, 7)
+	require.Equal(t, ProposalStatusRejected, proposal.Status)
+	require.Equal(t, int64(7), proposal.ClosedAt)
+	require.Zero(t, proposal.ActivationHeight)
+}
@@ -3,18 +3,95 @@ package retention
 import (
 	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
 	"github.com/google/uuid"
+	"time"
 )
 
 const (
 	AppName = "retention_policy"
 
-	// RequestTypeSetPolicy is used to set the chain's retention policy
+	// RequestTypeSetPolicy is used to set the chain's initial retention policy. Once a policy has been set, it can
+	// only be amended through a proposal.
 	RequestTypeSetPolicy = "set_policy"
+	// RequestTypeProposePolicy is used to propose an amendment to the retention policy
+	RequestTypeProposePolicy = "propose_policy"
+	// RequestTypeApprovePolicy is used to vote in favour of a pending proposal
+	RequestTypeApprovePolicy = "approve_policy"
+	// RequestTypeRejectPolicy is used to vote against a pending proposal
+	RequestTypeRejectPolicy = "reject_policy"
+	// RequestTypePlaceHold is used to place a legal hold, protecting matching events from deletion by the policy
+	RequestTypePlaceHold = "place_hold"
+	// RequestTypeReleaseHold is used to release a legal hold before it expires
+	RequestTypeReleaseHold = "release_hold"
 )
 
+const (
+	// QueryPathPolicy returns the policy that is in effect at the latest block height. Proofs are of the policy
+	// history, from which the policy in effect at the height of the response is derived.
+	QueryPathPolicy = "/"
+	// QueryPathHistory returns every version of the policy, including versions that are yet to take effect
+	QueryPathHistory = "/history"
+	// QueryPathProposals returns every proposal, in the order they were made
+	QueryPathProposals = "/proposals"
+	// QueryPathHolds returns every legal hold, including those that have been released or have expired
+	QueryPathHolds = "/holds"
+)
+
+const (
+	// TreeKeyHistory is the key of the policy history in the retention policy app's tree
+	TreeKeyHistory = "history"
+	// TreeKeyProposals is the key of the list of proposals in the retention policy app's tree
+	TreeKeyProposals = "proposals"
+	// TreeKeyHolds is the key of the list of legal holds in the retention policy app's tree
+	TreeKeyHolds = "holds"
+)
+
+// PolicyActivationDelay is the number of blocks after a proposal is approved before the amended policy takes effect,
+// giving off-chain infrastructure time to observe the change before it must be enforced.
+const PolicyActivationDelay int64 = 10
+
+// MaxHoldReasonLength is the maximum length of the reason given for a legal hold, in bytes.
+const MaxHoldReasonLength = 1024
+
 type SetPolicyRequest struct {
 	Policy offchain.RetentionPolicy `json:"policy"`
 	Nonce  uuid.UUID                `json:"nonce"`
 }
 
 type SetPolicyResponse struct{}
+
+type ProposePolicyRequest struct {
+	Policy offchain.RetentionPolicy `json:"policy"`
+	Nonce  uuid.UUID                `json:"nonce"`
+}
+
+// VoteRequest is used by both the approve_policy and reject_policy request types.
+type VoteRequest struct {
+	ProposalId uint64    `json:"proposal_id"`
+	Nonce      uuid.UUID `json:"nonce"`
+}
+
+// ProposalResponse is returned by the propose_policy, approve_policy and reject_policy request types, and contains the
+// state of the proposal after the request was applied.
+type ProposalResponse struct {
+	Proposal Proposal `json:"proposal"`
+}
+
+type PlaceHoldRequest struct {
+	Criteria offchain.HoldCriteria `json:"criteria"`
+	// Reason is a human-readable justification for the hold, such as an incident reference.
+	Reason string `json:"reason"`
+	// ExpiresAt is the time after which the hold no longer protects events. If nil, the hold must be released.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Nonce     uuid.UUID  `json:"nonce"`
+}
+
+type ReleaseHoldRequest struct {
+	HoldId uint64    `json:"hold_id"`
+	Nonce  uuid.UUID `json:"nonce"`
+}
+
+// HoldResponse is returned by the place_hold and release_hold request types, and contains the state of the hold after
+// the request was applied.
+type HoldResponse struct {
+	Hold offchain.LegalHold `json:"hold"`
+}
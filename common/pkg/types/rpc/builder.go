@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/google/uuid"
 )
 
 type Builder struct {
@@ -15,7 +16,9 @@ type Builder struct {
 	principal identity.Principal
 	key       ed25519.PrivateKey
 
-	app string
+	app      string
+	chainId  string
+	sequence uint64
 }
 
 func NewBuilder() *Builder {
@@ -33,6 +36,20 @@ func (b *Builder) App(app string) *Builder {
 	return b
 }
 
+// ChainID sets the ID of the chain that the request is destined for, which is bound by the signature on signed
+// requests.
+func (b *Builder) ChainID(chainId string) *Builder {
+	b.chainId = chainId
+	return b
+}
+
+// Sequence sets the sequence number of the signing principal, which is bound by the signature on signed requests. It
+// must be the next sequence number expected by the chain, as returned by the identity app's sequence query.
+func (b *Builder) Sequence(sequence uint64) *Builder {
+	b.sequence = sequence
+	return b
+}
+
 func (b *Builder) Signed(principal identity.Principal, key ed25519.PrivateKey) *Builder {
 	b.signed = true
 	b.principal = principal
@@ -50,12 +67,16 @@ func (b *Builder) buildSigned() (SignedPayload, error) {
 		return SignedPayload{}, errors.New("Data was not called on builder")
 	}
 
+	if b.chainId == "" {
+		return SignedPayload{}, errors.New("ChainID was not called on builder")
+	}
+
 	wrapped, err := wrap(b.requestType, b.data)
 	if err != nil {
 		return SignedPayload{}, err
 	}
 
-	return sign(wrapped, b.principal, b.key)
+	return signV1(b.chainId, b.app, wrapped, uuid.New().String(), b.sequence, b.principal, b.key)
 }
 
 func (b *Builder) buildUnsigned() (UnsignedPayload, error) {
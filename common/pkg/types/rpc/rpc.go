@@ -4,6 +4,8 @@ import (
 	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 )
 
@@ -20,16 +22,26 @@ type UnsignedPayload struct {
 
 type SignedPayload struct {
 	Payload   `json:"payload"`
-	Principal identity.Principal `json:"principal"` // Who is making the request
-	Signature string             `json:"signature"` // Hex-encoded Ed25519 signature of the data
+	Principal identity.Principal `json:"principal"`         // Who is making the request
+	Signature string             `json:"signature"`         // Hex-encoded Ed25519 signature of the sign bytes
+	Version   SignatureVersion   `json:"version,omitempty"` // Format of the sign bytes. Omitted for legacy signatures.
+	Nonce     string             `json:"nonce,omitempty"`   // Unique value bound by the signature. Unused by legacy signatures.
+	// Sequence is the principal's sequence number at the time of signing, which must match the next sequence number
+	// expected by the chain. Bound by the signature, preventing replays. Checked on legacy requests that carry it,
+	// but not bound by legacy signatures, which therefore offer no replay protection.
+	Sequence uint64 `json:"sequence,omitempty"`
 }
 
 const Codespace = "rpc"
 
+var ErrUnsupportedSignatureVersion = errors.New("unsupported signature version")
+
 const (
 	CodeOk = iota
 	CodeUnknownRequestType
 	CodeInvalidSignature
+	CodeLegacySignatureRejected
+	CodeUnsupportedSignatureVersion
 )
 
 func wrap(payloadType RequestType, payload any) (Payload, error) {
@@ -50,6 +62,28 @@ func unsigned(payload Payload) UnsignedPayload {
 	}
 }
 
+// signV1 signs the payload using the version 1 sign bytes, binding it to the chain, app, nonce and sequence number.
+func signV1(
+	chainId, app string,
+	payload Payload,
+	nonce string,
+	sequence uint64,
+	signer identity.Principal,
+	signerKey ed25519.PrivateKey,
+) (SignedPayload, error) {
+	signature := ed25519.Sign(signerKey, SignBytes(chainId, app, payload.Type, payload.Data, nonce, sequence))
+
+	return SignedPayload{
+		Payload:   payload,
+		Principal: signer,
+		Signature: hex.EncodeToString(signature),
+		Version:   SignatureVersionV1,
+		Nonce:     nonce,
+		Sequence:  sequence,
+	}, nil
+}
+
+// sign produces a legacy signature, covering the payload data only.
 func sign(payload Payload, signer identity.Principal, signerKey ed25519.PrivateKey) (SignedPayload, error) {
 	signature := ed25519.Sign(signerKey, payload.Data)
 
@@ -60,6 +94,31 @@ func sign(payload Payload, signer identity.Principal, signerKey ed25519.PrivateK
 	}, nil
 }
 
+// IsLegacy returns true if the payload carries a legacy signature, covering the payload data only.
+func (p *SignedPayload) IsLegacy() bool {
+	return p.Version == SignatureVersionLegacy
+}
+
+// Verify validates the signature against the given public key, using the sign bytes format declared by the payload.
+// Version 1 signatures must have been produced for the given chain and app. Legacy signatures are validated against
+// the payload data only: callers should use IsLegacy to flag or reject them.
+func (p *SignedPayload) Verify(publicKey ed25519.PublicKey, chainId, app string) (bool, error) {
+	switch p.Version {
+	case SignatureVersionLegacy:
+		return p.ValidateSignature(publicKey)
+	case SignatureVersionV1:
+		signature, err := hex.DecodeString(p.Signature)
+		if err != nil {
+			return false, err
+		}
+
+		return ed25519.Verify(publicKey, SignBytes(chainId, app, p.Type, p.Data, p.Nonce, p.Sequence), signature), nil
+	default:
+		return false, fmt.Errorf("%w: %d", ErrUnsupportedSignatureVersion, p.Version)
+	}
+}
+
+// ValidateSignature validates a legacy signature, covering the payload data only.
 func (p *SignedPayload) ValidateSignature(publicKey ed25519.PublicKey) (bool, error) {
 	signature, err := hex.DecodeString(p.Signature)
 	if err != nil {
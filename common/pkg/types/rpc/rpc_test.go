@@ -101,3 +101,105 @@ func TestInvalidSignature(t *testing.T) {
 	require.NoErrorf(t, err, "failed to validate signature: %v", err)
 	require.Falsef(t, valid, "signature validation succeeded when it should have failed")
 }
+
+func TestSignatureV1(t *testing.T) {
+	adminPub, adminPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoErrorf(t, err, "failed to generate key pair: %v", err)
+
+	marshalled, err := NewBuilder().
+		App(identity.AppName).
+		ChainID("test-chain").
+		Sequence(7).
+		Data(identity.RequestTypeRegister, identity.PayloadRegister{Principal: "new_user", Role: identity.RoleAgent}).
+		Signed("admin", adminPriv).
+		Marshal()
+	require.NoError(t, err)
+
+	var muxed MuxedRequest
+	require.NoError(t, json.Unmarshal(marshalled, &muxed))
+
+	var payload SignedPayload
+	require.NoError(t, json.Unmarshal(muxed.Data, &payload))
+	require.False(t, payload.IsLegacy())
+	require.NotEmpty(t, payload.Nonce)
+	require.Equal(t, uint64(7), payload.Sequence)
+
+	valid, err := payload.Verify(adminPub, "test-chain", identity.AppName)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// Replay on a different chain
+	valid, err = payload.Verify(adminPub, "other-chain", identity.AppName)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Replay against a different app
+	valid, err = payload.Verify(adminPub, "test-chain", "events")
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Replay under a different request type
+	retyped := payload
+	retyped.Type = identity.RequestTypeSeed
+	valid, err = retyped.Verify(adminPub, "test-chain", identity.AppName)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Replay with a different nonce
+	renonced := payload
+	renonced.Nonce = "abc"
+	valid, err = renonced.Verify(adminPub, "test-chain", identity.AppName)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Replay with a different sequence number
+	resequenced := payload
+	resequenced.Sequence = 8
+	valid, err = resequenced.Verify(adminPub, "test-chain", identity.AppName)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Downgrade to a legacy signature
+	downgraded := payload
+	downgraded.Version = SignatureVersionLegacy
+	valid, err = downgraded.Verify(adminPub, "test-chain", identity.AppName)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestSignatureLegacyAccepted(t *testing.T) {
+	adminPub, adminPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoErrorf(t, err, "failed to generate key pair: %v", err)
+
+	wrapped, err := wrap(identity.RequestTypeRegister, identity.PayloadRegister{Principal: "new_user"})
+	require.NoError(t, err)
+
+	payload, err := sign(wrapped, "admin", adminPriv)
+	require.NoError(t, err)
+	require.True(t, payload.IsLegacy())
+
+	valid, err := payload.Verify(adminPub, "test-chain", identity.AppName)
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestSignatureUnsupportedVersion(t *testing.T) {
+	adminPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoErrorf(t, err, "failed to generate key pair: %v", err)
+
+	payload := SignedPayload{Version: 99}
+	_, err = payload.Verify(adminPub, "test-chain", identity.AppName)
+	require.ErrorIs(t, err, ErrUnsupportedSignatureVersion)
+}
+
+func TestBuilderRequiresChainID(t *testing.T) {
+	_, adminPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoErrorf(t, err, "failed to generate key pair: %v", err)
+
+	_, err = NewBuilder().
+		App(identity.AppName).
+		Data(identity.RequestTypeRegister, identity.PayloadRegister{Principal: "new_user"}).
+		Signed("admin", adminPriv).
+		Marshal()
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// SignatureVersion identifies the format of the bytes covered by the signature on a SignedPayload.
+type SignatureVersion uint32
+
+const (
+	// SignatureVersionLegacy signatures cover Payload.Data only. They do not bind the request type, app or chain, and
+	// are accepted only for a transition period.
+	SignatureVersionLegacy SignatureVersion = 0
+	// SignatureVersionV1 signatures cover the bytes produced by SignBytes.
+	SignatureVersionV1 SignatureVersion = 1
+)
+
+// LegacyRejectionAppVersion is the first app version at which legacy signatures are rejected, ending the transition
+// period. The transition ends at the same height on every node, as chains adopt the version through an upgrade once
+// every client signs with SignatureVersionV1.
+const LegacyRejectionAppVersion uint64 = 3
+
+// signDomainV1 separates version 1 sign bytes from any other message signed with the same key.
+const signDomainV1 = "wineventchain/signed-payload/v1"
+
+// SignBytes returns the canonical bytes covered by a version 1 signature. The domain tag, chain ID, app name, request
+// type, nonce, principal sequence number and payload data are each written as an 8-byte big-endian length followed by
+// the raw bytes, so that no field can be shifted into its neighbour.
+func SignBytes(chainId, app string, requestType RequestType, data []byte, nonce string, sequence uint64) []byte {
+	sequenceBytes := binary.BigEndian.AppendUint64(nil, sequence)
+
+	var buf bytes.Buffer
+	for _, field := range [][]byte{
+		[]byte(signDomainV1),
+		[]byte(chainId),
+		[]byte(app),
+		[]byte(requestType),
+		[]byte(nonce),
+		sequenceBytes,
+		data,
+	} {
+		_ = binary.Write(&buf, binary.BigEndian, uint64(len(field))) // Writes to a bytes.Buffer cannot fail
+		buf.Write(field)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,14 @@
+package upgrade
+
+const (
+	Codespace string = "upgrade"
+
+	CodeOk                 uint32 = 0
+	CodeUnknownRequestType uint32 = iota + 6000
+	CodeUnknownError
+	CodeUnauthorized
+	CodeInvalidPlan
+	CodeUpgradeAlreadyScheduled
+	CodeNoUpgradeScheduled
+	CodeInvalidQueryPath
+)
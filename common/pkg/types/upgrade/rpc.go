@@ -0,0 +1,74 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+const (
+	AppName = "upgrade"
+
+	// RequestTypeSchedule is used to schedule an upgrade of the application at a future height
+	RequestTypeSchedule = "schedule"
+	// RequestTypeCancel is used to cancel the scheduled upgrade before its height is reached
+	RequestTypeCancel = "cancel"
+)
+
+const (
+	// QueryPathState returns the scheduled upgrade, if any, and every upgrade that has been applied
+	QueryPathState = "/"
+)
+
+// MaxPlanNameLength is the maximum length of the name of an upgrade plan, in bytes.
+const MaxPlanNameLength = 64
+
+// Plan schedules an upgrade. Nodes that are not running a binary with a handler for the plan's height and version halt
+// at the height, before processing the block, so that they can be restarted with the upgraded binary.
+type Plan struct {
+	Name string `json:"name"`
+	// Height is the height of the first block processed with the upgraded state.
+	Height int64 `json:"height"`
+	// Version is the app version of the state from Height onwards.
+	Version uint64 `json:"version"`
+}
+
+// AppliedUpgrade is a plan that has been carried out.
+type AppliedUpgrade struct {
+	Plan
+	// PreviousVersion is the app version of the state before the upgrade.
+	PreviousVersion uint64 `json:"previous_version"`
+}
+
+type ScheduleRequest struct {
+	Plan  Plan      `json:"plan"`
+	Nonce uuid.UUID `json:"nonce"`
+}
+
+type CancelRequest struct {
+	Nonce uuid.UUID `json:"nonce"`
+}
+
+// StateResponse is returned by the state query.
+type StateResponse struct {
+	Scheduled *Plan            `json:"scheduled,omitempty"`
+	Applied   []AppliedUpgrade `json:"applied"`
+}
+
+// Validate checks that the plan can be scheduled on a chain whose latest finalized block is at the given height, with
+// the given app version.
+func (p Plan) Validate(height int64, appVersion uint64) error {
+	if p.Name == "" || len(p.Name) > MaxPlanNameLength {
+		return fmt.Errorf("name must be between 1 and %d bytes long", MaxPlanNameLength)
+	}
+
+	if p.Height <= height+1 {
+		return fmt.Errorf("height must be after the next block, %d", height+1)
+	}
+
+	if p.Version <= appVersion {
+		return errors.New("version must be greater than the current app version")
+	}
+
+	return nil
+}
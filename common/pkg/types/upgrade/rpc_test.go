@@ -0,0 +1,19 @@
+package upgrade
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPlanValidate(t *testing.T) {
+	plan := Plan{Name: "v2", Height: 100, Version: 2}
+	require.NoError(t, plan.Validate(50, 1))
+
+	// The plan must take effect after the next block, so that it can be committed before its height
+	require.Error(t, plan.Validate(99, 1))
+	require.NoError(t, plan.Validate(98, 1))
+
+	require.Error(t, plan.Validate(50, 2))
+	require.Error(t, Plan{Height: 100, Version: 2}.Validate(50, 1))
+	require.Error(t, Plan{Name: string(make([]byte, MaxPlanNameLength+1)), Height: 100, Version: 2}.Validate(50, 1))
+}
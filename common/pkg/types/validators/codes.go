@@ -0,0 +1,17 @@
+package validators
+
+const (
+	Codespace string = "validators"
+
+	CodeOk                 uint32 = 0
+	CodeUnknownRequestType uint32 = iota + 5000
+	CodeUnknownError
+	CodeUnauthorized
+	CodeInvalidKey
+	CodeInvalidPower
+	CodeValidatorAlreadyExists
+	CodeValidatorNotFound
+	CodeValidatorUpdatePending
+	CodeLastValidator
+	CodeInvalidQueryPath
+)
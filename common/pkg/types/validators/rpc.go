@@ -0,0 +1,69 @@
+package validators
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/google/uuid"
+)
+
+const (
+	AppName = "validators"
+
+	// RequestTypeAdd is used to add a new validator to the validator set
+	RequestTypeAdd = "add"
+	// RequestTypeRemove is used to remove a validator from the validator set
+	RequestTypeRemove = "remove"
+	// RequestTypeSetPower is used to change the voting power of an existing validator
+	RequestTypeSetPower = "set_power"
+)
+
+const (
+	// QueryPathValidators returns the current validator set, ordered by address
+	QueryPathValidators = "/"
+)
+
+// PayloadValidator is used by the add, remove and set_power request types. Power is ignored by remove requests.
+type PayloadValidator struct {
+	PubKey ed25519.PublicKey // The Ed25519 public key of the validator. Hex encoded in transit.
+	Power  int64
+	// Nonce is a unique identifier for the request. Prevents "tx already exists in cache" errors from Tendermint.
+	Nonce uuid.UUID
+}
+
+// MarshalJSON Custom marshaller to encode public key as hex string
+func (p PayloadValidator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		PubKey string    `json:"pub_key"`
+		Power  int64     `json:"power"`
+		Nonce  uuid.UUID `json:"nonce"`
+	}{
+		PubKey: hex.EncodeToString(p.PubKey),
+		Power:  p.Power,
+		Nonce:  p.Nonce,
+	})
+}
+
+// UnmarshalJSON Custom unmarshaller to decode public key from hex string
+func (p *PayloadValidator) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		PubKey string    `json:"pub_key"`
+		Power  int64     `json:"power"`
+		Nonce  uuid.UUID `json:"nonce"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	pubKey, err := hex.DecodeString(aux.PubKey)
+	if err != nil {
+		return err
+	}
+
+	p.PubKey = pubKey
+	p.Power = aux.Power
+	p.Nonce = aux.Nonce
+
+	return nil
+}
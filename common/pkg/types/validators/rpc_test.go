@@ -0,0 +1,46 @@
+package validators
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPayloadValidatorRoundTrip(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	payload := PayloadValidator{
+		PubKey: pub,
+		Power:  10,
+		Nonce:  uuid.New(),
+	}
+
+	marshalled, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	var decoded PayloadValidator
+	require.NoError(t, json.Unmarshal(marshalled, &decoded))
+	require.Equal(t, payload, decoded)
+}
+
+func TestValidatorRoundTrip(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	validator := Validator{
+		Address: "0123456789ABCDEF0123456789ABCDEF01234567",
+		PubKey:  pub,
+		Power:   1,
+	}
+
+	marshalled, err := json.Marshal(validator)
+	require.NoError(t, err)
+
+	var decoded Validator
+	require.NoError(t, json.Unmarshal(marshalled, &decoded))
+	require.Equal(t, validator, decoded)
+}
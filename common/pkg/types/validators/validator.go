@@ -0,0 +1,52 @@
+package validators
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Validator is a member of the validator set.
+type Validator struct {
+	// Address is the upper-case hex encoded CometBFT address of the validator, derived from its public key.
+	Address string
+	PubKey  ed25519.PublicKey
+	Power   int64
+}
+
+// MarshalJSON Custom marshaller to encode public key as hex string
+func (v Validator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Address string `json:"address"`
+		PubKey  string `json:"pub_key"`
+		Power   int64  `json:"power"`
+	}{
+		Address: v.Address,
+		PubKey:  hex.EncodeToString(v.PubKey),
+		Power:   v.Power,
+	})
+}
+
+// UnmarshalJSON Custom unmarshaller to decode public key from hex string
+func (v *Validator) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Address string `json:"address"`
+		PubKey  string `json:"pub_key"`
+		Power   int64  `json:"power"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	pubKey, err := hex.DecodeString(aux.PubKey)
+	if err != nil {
+		return err
+	}
+
+	v.Address = aux.Address
+	v.PubKey = pubKey
+	v.Power = aux.Power
+
+	return nil
+}
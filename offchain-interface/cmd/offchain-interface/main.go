@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"github.com/RyanW02/wineventchain/common/pkg/broadcast"
 	"github.com/RyanW02/wineventchain/common/pkg/types/events"
 	"github.com/RyanW02/wineventchain/offchain-interface/internal/config"
@@ -9,8 +10,11 @@ import (
 	"github.com/RyanW02/wineventchain/offchain-interface/internal/viewer"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/blockchain"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/harmoniser"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/redaction"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository/boltdb"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository/mongodb"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository/postgres"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/retention"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/state"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/transport"
@@ -49,8 +53,7 @@ func main() {
 	}
 	defer stateStore.Close(context.Background())
 
-	db := connectMongo(cfg, logger)
-	repository := buildRepository(logger.With(zap.String("module", "repository")), db)
+	repository := buildRepository(cfg, logger.With(zap.String("module", "repository")))
 
 	retentionAgent := retention.NewAgent(
 		cfg,
@@ -60,6 +63,14 @@ func main() {
 	)
 	go retentionAgent.StartLoop(shutdownOrchestrator.Subscribe())
 
+	redactionAgent := redaction.NewAgent(
+		cfg,
+		logger.With(zap.String("module", "redaction_agent")),
+		blockchainClient,
+		repository,
+	)
+	go redactionAgent.StartLoop(shutdownOrchestrator.Subscribe())
+
 	// Join transport cluster for off-chain interface node communication
 	var transportClient transport.EventTransport
 	if len(cfg.Transport.Peers) > 0 {
@@ -252,11 +263,50 @@ func connectMongo(cfg config.Config, logger *zap.Logger) *mongo.Database {
 	return client.Database(cfg.MongoDB.DatabaseName)
 }
 
-func buildRepository(logger *zap.Logger, db *mongo.Database) repository.Repository {
-	repo := mongodb.NewMongoRepository(logger, db)
-	if err := repo.InitSchema(context.Background()); err != nil {
-		logger.Fatal("failed to initialize MongoDB schema", zap.Error(err))
+func connectPostgres(cfg config.Config, logger *zap.Logger) *sql.DB {
+	db, err := sql.Open("postgres", cfg.Postgres.URI)
+	if err != nil {
+		logger.Fatal("failed to connect to PostgreSQL", zap.Error(err))
 	}
 
-	return repo
+	// Ping server
+	if err := db.PingContext(context.Background()); err != nil {
+		logger.Fatal("failed to ping PostgreSQL server", zap.Error(err))
+	}
+
+	return db
+}
+
+func buildRepository(cfg config.Config, logger *zap.Logger) repository.Repository {
+	switch cfg.Repository.ConvertCase() {
+	// Configuration files written before the repository was configurable do not set it
+	case config.RepositoryTypeMongoDB, "":
+		repo := mongodb.NewMongoRepository(logger, connectMongo(cfg, logger))
+		if err := repo.InitSchema(context.Background()); err != nil {
+			logger.Fatal("failed to initialize MongoDB schema", zap.Error(err))
+		}
+
+		return repo
+	case config.RepositoryTypePostgres:
+		repo := postgres.NewPostgresRepository(logger, connectPostgres(cfg, logger))
+		if err := repo.InitSchema(context.Background()); err != nil {
+			logger.Fatal("failed to migrate PostgreSQL schema", zap.Error(err))
+		}
+
+		return repo
+	case config.RepositoryTypeBolt:
+		repo, err := boltdb.Open(logger, cfg.Bolt.Path)
+		if err != nil {
+			logger.Fatal("failed to open bolt database", zap.Error(err), zap.String("path", cfg.Bolt.Path))
+		}
+
+		if err := repo.InitSchema(); err != nil {
+			logger.Fatal("failed to initialize bolt schema", zap.Error(err))
+		}
+
+		return repo
+	default:
+		logger.Fatal("unknown repository type", zap.Stringer("repository", cfg.Repository))
+		return nil
+	}
 }
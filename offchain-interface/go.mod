@@ -2,6 +2,11 @@ module github.com/RyanW02/wineventchain/offchain-interface
 
 go 1.21.3
 
+require (
+	github.com/lib/pq v1.10.7
+	go.etcd.io/bbolt v1.3.8
+)
+
 require (
 	dario.cat/mergo v1.0.0 // indirect
 	github.com/Azure/go-ansiterm v0.0.0-20230124172434-306776ec8161 // indirect
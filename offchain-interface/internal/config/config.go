@@ -16,11 +16,15 @@ type (
 		Server         Server         `json:"server" envPrefix:"SERVER_"`
 		ViewerServer   ViewerServer   `json:"viewer_server" envPrefix:"VIEWER_SERVER_"`
 		Blockchain     Blockchain     `json:"blockchain" envPrefix:"BLOCKCHAIN_"`
+		Repository     RepositoryType `json:"repository" env:"REPOSITORY" envDefault:"mongodb"`
 		MongoDB        MongoDB        `json:"mongodb" envPrefix:"MONGODB_"`
+		Postgres       Postgres       `json:"postgres" envPrefix:"POSTGRES_"`
+		Bolt           Bolt           `json:"bolt" envPrefix:"BOLT_"`
 		State          State          `json:"state" envPrefix:"STATE_"`
 		Transport      Transport      `json:"transport" envPrefix:"TRANSPORT_"`
 		Backfill       Backfill       `json:"backfill" envPrefix:"BACKFILL_"`
 		EventRetention EventRetention `json:"event_retention" envPrefix:"EVENT_RETENTION_"`
+		Redaction      Redaction      `json:"redaction" envPrefix:"REDACTION_"`
 	}
 
 	Server struct {
@@ -39,6 +43,12 @@ type (
 	Blockchain struct {
 		NodeAddresses []string `json:"node_addresses" env:"NODE_ADDRESSES" envSeparator:","`
 		MinimumNodes  int      `json:"minimum_nodes" env:"MINIMUM_NODES" envDefault:"1"`
+		// TrustHeight and TrustHash identify a block header obtained from a trusted source, from which a light client
+		// verifies the headers that proofs are checked against. If unset, headers are verified against the validator
+		// set reported by the nodes themselves.
+		TrustHeight int64                    `json:"trust_height" env:"TRUST_HEIGHT"`
+		TrustHash   string                   `json:"trust_hash" env:"TRUST_HASH"`
+		TrustPeriod types.MarshalledDuration `json:"trust_period" env:"TRUST_PERIOD" envDefault:"168h"`
 	}
 
 	MongoDB struct {
@@ -46,6 +56,18 @@ type (
 		DatabaseName string `json:"database_name" env:"DATABASE_NAME"`
 	}
 
+	Postgres struct {
+		URI string `json:"uri" env:"URI"`
+	}
+
+	// Bolt configures the embedded database, which stores events in a single file without a database server.
+	Bolt struct {
+		Path string `json:"path" env:"PATH" envDefault:"events.db"`
+	}
+
+	// RepositoryType selects the database in which events are stored off-chain.
+	RepositoryType string
+
 	State struct {
 		Path string `json:"path" env:"PATH" envDefault:"state.db"`
 	}
@@ -82,12 +104,22 @@ type (
 		ScanInterval types.MarshalledDuration `json:"scan_interval" env:"SCAN_INTERVAL" envDefault:"1h"`
 		ScanTimeout  types.MarshalledDuration `json:"scan_timeout" env:"SCAN_TIMEOUT" envDefault:"30m"`
 	}
+
+	Redaction struct {
+		RunAtStartup bool                     `json:"run_at_startup" env:"RUN_AT_STARTUP" envDefault:"true"`
+		ScanInterval types.MarshalledDuration `json:"scan_interval" env:"SCAN_INTERVAL" envDefault:"5m"`
+		ScanTimeout  types.MarshalledDuration `json:"scan_timeout" env:"SCAN_TIMEOUT" envDefault:"5m"`
+	}
 )
 
 const (
 	NetworkTypeWAN   NetworkType = "wan"
 	NetworkTypeLAN   NetworkType = "lan"
 	NetworkTypeLocal NetworkType = "local"
+
+	RepositoryTypeMongoDB  RepositoryType = "mongodb"
+	RepositoryTypePostgres RepositoryType = "postgres"
+	RepositoryTypeBolt     RepositoryType = "bolt"
 )
 
 func Load() (Config, error) {
@@ -121,3 +153,11 @@ func (n NetworkType) ConvertCase() NetworkType {
 func (n NetworkType) String() string {
 	return string(n)
 }
+
+func (r RepositoryType) ConvertCase() RepositoryType {
+	return RepositoryType(strings.ToLower(r.String()))
+}
+
+func (r RepositoryType) String() string {
+	return string(r)
+}
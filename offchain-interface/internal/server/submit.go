@@ -6,6 +6,7 @@ import (
 	"crypto/ed25519"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/RyanW02/wineventchain/common/pkg/types/events"
 	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
@@ -57,14 +58,54 @@ func (s *Server[T, U]) StoreEvent(ctx context.Context, req types.SubmitRequest)
 		}
 	}
 
-	// Validate signature
+	// Fetch event data from blockchain
+	event, height, err := s.blockchain.GetEventByTxWithHeight(req.TxHash, req.EventId)
+	if err != nil {
+		if errors.Is(err, blockchain.ErrEventNotFound) {
+			return NewHttpError(http.StatusNotFound, "event not found")
+		}
+
+		s.logger.Error("failed to get event by tx", zap.Error(err))
+		return NewHttpError(http.StatusInternalServerError, "failed to get event by tx")
+	}
+
+	// Revoked or suspended principals may no longer create events, but the data of events created before the status
+	// change is still accepted. The chain rejects events that follow the status change within its block.
+	if height > principal.StatusChangedAt {
+		if principal.IsRevoked() {
+			s.logger.Warn("got event data submitted by revoked principal", zap.String("principal", req.Principal))
+			return NewHttpError(http.StatusForbidden, "principal has been revoked")
+		} else if principal.IsSuspended() {
+			s.logger.Warn("got event data submitted by suspended principal", zap.String("principal", req.Principal))
+			return NewHttpError(http.StatusForbidden, "principal has been suspended")
+		}
+	}
+
+	// Apply the same validation as the chain, which only validates events from the app version that introduced it, so
+	// that the data of events accepted before then is still accepted. The received time of an event is the time of the
+	// block that stores it, against which the chain checks the creation time.
+	appVersion, err := s.blockchain.GetAppVersionAt(height)
+	if err != nil {
+		s.logger.Error("failed to get app version", zap.Int64("height", height), zap.Error(err))
+		return NewHttpError(http.StatusInternalServerError, "failed to get app version")
+	}
+
+	if appVersion >= events.ValidationAppVersion {
+		if err := event.ScrubbedEvent.Validate(event.Metadata.ReceivedTime, events.MaxBlockTimeSkew); err != nil {
+			s.logger.Warn("got event data for invalid event", zap.Stringer("event_id", req.EventId), zap.Error(err))
+			return NewHttpError(http.StatusBadRequest, fmt.Sprintf("invalid event: %s", err.Error()))
+		}
+	}
+
+	// Validate signature. The principal's key may have been rotated since the event was created, so validate against
+	// the key that was valid at the height of the block containing the event.
 	signature, err := hex.DecodeString(req.Signature)
 	if err != nil {
 		return NewHttpError(http.StatusBadRequest, "invalid signature")
 	}
 
 	hash := req.EventData.Hash()
-	signatureValid := ed25519.Verify(principal.PublicKey, hash[:], signature)
+	signatureValid := ed25519.Verify(principal.KeyAt(height), hash[:], signature)
 	if !signatureValid {
 		s.logger.Warn(
 			"got invalid event data signature",
@@ -76,17 +117,6 @@ func (s *Server[T, U]) StoreEvent(ctx context.Context, req types.SubmitRequest)
 		return NewHttpError(http.StatusForbidden, "signature is invalid")
 	}
 
-	// Fetch event data from blockchain
-	event, err := s.blockchain.GetEventByTx(req.TxHash)
-	if err != nil {
-		if errors.Is(err, blockchain.ErrEventNotFound) {
-			return NewHttpError(http.StatusNotFound, "event not found")
-		}
-
-		s.logger.Error("failed to get event by tx", zap.Error(err))
-		return NewHttpError(http.StatusInternalServerError, "failed to get event by tx")
-	}
-
 	// Check we are talking about the same event
 	if !bytes.Equal(event.Metadata.EventId, req.EventId) {
 		s.logger.Warn(
@@ -27,7 +27,7 @@ func (s *Server) challengeHandler(c *gin.Context) {
 		return
 	}
 
-	identity, err := s.blockchainClient.GetIdentity(body.Principal)
+	identityData, err := s.blockchainClient.GetIdentity(body.Principal)
 	if err != nil {
 		if errors.Is(err, blockchain.ErrPrincipalNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "principal not found"})
@@ -39,8 +39,16 @@ func (s *Server) challengeHandler(c *gin.Context) {
 		}
 	}
 
-	if !identity.IsAdmin() {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "principal is not an admin"})
+	if identityData.IsRevoked() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "principal has been revoked"})
+		return
+	} else if identityData.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "principal has been suspended"})
+		return
+	}
+
+	if !identityData.Can(identity.PermissionUseViewer) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "principal is not permitted to use the viewer"})
 		return
 	}
 
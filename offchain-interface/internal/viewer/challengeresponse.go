@@ -25,20 +25,28 @@ func (s *Server) challengeResponseHandler(c *gin.Context) {
 		return
 	}
 
-	identity, err := s.blockchainClient.GetIdentity(body.Principal)
+	identityData, err := s.blockchainClient.GetIdentity(body.Principal)
 	if err != nil {
 		s.logger.Error("failed to retrieve identity", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve identity"})
 		return
 	}
 
-	if !identity.IsAdmin() {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "principal is not an admin"})
+	if identityData.IsRevoked() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "principal has been revoked"})
+		return
+	} else if identityData.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "principal has been suspended"})
+		return
+	}
+
+	if !identityData.Can(identity.PermissionUseViewer) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "principal is not permitted to use the viewer"})
 		return
 	}
 
 	// Validate signature
-	if !ed25519.Verify(identity.PublicKey, body.Challenge, body.Response) {
+	if !ed25519.Verify(identityData.PublicKey, body.Challenge, body.Response) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid private key"})
 		return
 	}
@@ -0,0 +1,46 @@
+package viewer
+
+import (
+	"context"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type legalHoldResponse struct {
+	offchain.LegalHold
+	// ProtectedCount is the number of stored events that the hold protects from deletion.
+	ProtectedCount int `json:"protected_count"`
+}
+
+func (s *Server) listLegalHoldsHandler(c *gin.Context) {
+	holds, err := s.blockchainClient.GetLegalHolds()
+	if err != nil {
+		s.logger.Error("failed to get legal holds", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get legal holds"})
+		return
+	}
+
+	ctx, cancelFunc := context.WithTimeout(c, time.Second*10)
+	defer cancelFunc()
+
+	active := holds.Active(time.Now())
+	res := make([]legalHoldResponse, len(active))
+	for i, hold := range active {
+		count, err := s.repository.Events().CountHeldEvents(ctx, hold)
+		if err != nil {
+			s.logger.Error("failed to count events protected by legal hold", zap.Error(err), zap.Uint64("hold_id", hold.Id))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count protected events"})
+			return
+		}
+
+		res[i] = legalHoldResponse{
+			LegalHold:      hold,
+			ProtectedCount: count,
+		}
+	}
+
+	c.JSON(http.StatusOK, res)
+}
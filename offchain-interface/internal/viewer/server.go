@@ -74,6 +74,8 @@ func (s *Server) Run() {
 	eventsGroup.GET("/by-id/:id", s.authenticate, s.getEventHandler)
 	eventsGroup.GET("/stream", s.eventWebsocketHandler)
 
+	s.router.GET("/holds", s.authenticate, s.listLegalHoldsHandler)
+
 	s.logger.Info("Starting viewer server", zap.String("address", s.config.ViewerServer.Address))
 
 	if err := s.router.Run(s.config.ViewerServer.Address); err != nil {
@@ -1,12 +1,12 @@
 package blockchain
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/RyanW02/wineventchain/common/pkg/pool"
-	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	"github.com/RyanW02/wineventchain/common/pkg/types/events"
 	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
 	"github.com/RyanW02/wineventchain/common/pkg/types/retention"
@@ -14,11 +14,13 @@ import (
 	"github.com/RyanW02/wineventchain/offchain-interface/internal/config"
 	"github.com/RyanW02/wineventchain/offchain-interface/pkg/state"
 	"github.com/cometbft/cometbft/crypto/merkle"
-	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cometbft/cometbft/light"
 	"github.com/cometbft/cometbft/rpc/client/http"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	cmttypes "github.com/cometbft/cometbft/types"
 	"go.uber.org/zap"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,17 +29,27 @@ type RoundRobinClient struct {
 	logger       *zap.Logger
 	pool         *pool.Pool[http.HTTP]
 	proofRuntime *merkle.ProofRuntime
+
+	// headers caches verified block headers, by height
+	headers  map[int64]*cmttypes.Header
+	headerMu sync.Mutex
+
+	// light verifies headers from the configured trusted header. Created on first use, and nil until then.
+	light   *light.Client
+	lightMu sync.Mutex
 }
 
 var (
 	ErrABCIQueryFailed = fmt.Errorf("ABCI query failed")
 	ErrEventNotFound   = errors.New("tx not found")
-
-	ErrNotEnoughNodes = errors.New("not enough nodes to satisfy minimum nodes requirement")
-	ErrPolicyMismatch = errors.New("policies do not match")
+	ErrInvalidHeight   = errors.New("tx height does not match event")
 )
 
 func NewRoundRobinClient(config config.Config, logger *zap.Logger, clients []http.HTTP) *RoundRobinClient {
+	if config.Blockchain.TrustHeight <= 0 {
+		logger.Warn("No trusted header configured, proofs will be verified against validator sets reported by the nodes")
+	}
+
 	return &RoundRobinClient{
 		config: config,
 		logger: logger,
@@ -53,6 +65,7 @@ func NewRoundRobinClient(config config.Config, logger *zap.Logger, clients []htt
 			},
 			DestructorFunc: http.HTTP.Stop,
 		}),
+		headers: make(map[int64]*cmttypes.Header),
 	}
 }
 
@@ -60,19 +73,55 @@ func (c *RoundRobinClient) Close() {
 	c.pool.Close()
 }
 
-func (c *RoundRobinClient) GetEventByTx(txHash []byte) (events.EventWithMetadata, error) {
-	metadata, err := c.GetEventMetadataByTx(txHash)
+// GetEventByTx fetches the event with the given ID, which must have been created by the given transaction.
+func (c *RoundRobinClient) GetEventByTx(txHash []byte, eventId events.EventHash) (events.EventWithMetadata, error) {
+	event, _, err := c.GetEventByTxWithHeight(txHash, eventId)
+	return event, err
+}
+
+// GetEventByTxWithHeight fetches the event with the given ID, which must have been created by the given transaction,
+// along with the height of the block that the transaction was included in.
+func (c *RoundRobinClient) GetEventByTxWithHeight(txHash []byte, eventId events.EventHash) (events.EventWithMetadata, int64, error) {
+	metadata, height, err := c.GetEventMetadataByTx(txHash)
 	if err != nil {
-		return events.EventWithMetadata{}, err
+		return events.EventWithMetadata{}, 0, err
+	}
+
+	// A batch transaction creates multiple events, so find the event being requested
+	for _, m := range metadata {
+		if !bytes.Equal(m.EventId, eventId) {
+			continue
+		}
+
+		event, err := c.GetEventById(m.EventId)
+		if err != nil {
+			return events.EventWithMetadata{}, 0, err
+		}
+
+		// The height is reported by the node without a proof, but is bound by the proven event ID, which is derived
+		// from the height at which the event was stored
+		eventId, err := events.NewEventHash(uint64(height), event.Metadata.Principal, event.Event)
+		if err != nil {
+			return events.EventWithMetadata{}, 0, err
+		}
+
+		if !bytes.Equal(eventId, event.Metadata.EventId) {
+			return events.EventWithMetadata{}, 0, fmt.Errorf("%w: event %s was not stored at height %d", ErrInvalidHeight, event.Metadata.EventId, height)
+		}
+
+		return event, height, nil
 	}
 
-	return c.GetEventById(metadata.EventId)
+	return events.EventWithMetadata{}, 0, ErrEventNotFound
 }
 
-func (c *RoundRobinClient) GetEventMetadataByTx(txHash []byte) (events.Metadata, error) {
+// GetEventMetadataByTx fetches the metadata of every event created by the given transaction, in order, along with the
+// height of the block that the transaction was included in. Neither is proven: the height must be checked against the
+// ID of the proven event, as in GetEventByTxWithHeight.
+func (c *RoundRobinClient) GetEventMetadataByTx(txHash []byte) ([]events.Metadata, int64, error) {
 	conn, err := c.pool.Get()
 	if err != nil {
-		return events.Metadata{}, err
+		return nil, 0, err
 	}
 
 	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
@@ -82,27 +131,30 @@ func (c *RoundRobinClient) GetEventMetadataByTx(txHash []byte) (events.Metadata,
 	if err != nil {
 		var rpcError *rpctypes.RPCError
 		if errors.As(err, &rpcError) && strings.Contains(rpcError.Data, "not found") {
-			return events.Metadata{}, ErrEventNotFound
+			return nil, 0, ErrEventNotFound
 		}
 
-		return events.Metadata{}, err
+		return nil, 0, err
 	}
 
 	var res events.CreateResponse
 	if err := json.Unmarshal(tx.TxResult.Data, &res); err != nil {
-		return events.Metadata{}, err
+		return nil, 0, err
 	}
 
-	return res.Metadata, nil
+	return res.All(), tx.Height, nil
 }
 
+// GetEventById fetches the event with the given ID. The event, or its absence, is verified by a proof chain from the
+// events tree to the app hash of a block header, so that a single malicious node cannot forge or hide events.
 func (c *RoundRobinClient) GetEventById(eventId events.EventHash) (events.EventWithMetadata, error) {
 	conn, err := c.pool.Get()
 	if err != nil {
 		return events.EventWithMetadata{}, err
 	}
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	// Allow time for the header committing to the latest state to be created
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancelFunc()
 
 	// Create data payload to route to the correct sub-app
@@ -118,31 +170,35 @@ func (c *RoundRobinClient) GetEventById(eventId events.EventHash) (events.EventW
 		return events.EventWithMetadata{}, err
 	}
 
-	if res.Response.Code != 0 {
-		if res.Response.Codespace == events.Codespace && res.Response.Code == events.CodeEventNotFound {
-			return events.EventWithMetadata{}, ErrEventNotFound
-		}
-
+	notFound := res.Response.Codespace == events.Codespace && res.Response.Code == events.CodeEventNotFound
+	if res.Response.Code != 0 && !notFound {
 		return events.EventWithMetadata{}, fmt.Errorf(
 			"%w, code: %s:%d, log: %s, info: %s",
 			ErrABCIQueryFailed, res.Response.Codespace, res.Response.Code, res.Response.Log, res.Response.Info,
 		)
 	}
 
-	var event events.EventWithMetadata
-	if err := json.Unmarshal(res.Response.Value, &event); err != nil {
+	// Validate proof, and use the proven value in place of the value returned by the node
+	value, err := c.verifyQuery(ctx, conn, res.Response, events.AppName, eventId)
+	if err != nil {
 		return events.EventWithMetadata{}, err
 	}
 
-	// Validate proof
-	if err := proof.ValidateProofOps(res.Response.ProofOps); err != nil {
+	if value == nil {
+		return events.EventWithMetadata{}, ErrEventNotFound
+	}
+
+	var event events.EventWithMetadata
+	if err := json.Unmarshal(value, &event); err != nil {
 		return events.EventWithMetadata{}, err
 	}
 
 	return event, nil
 }
 
-// SearchEvents searches for events between the given block heights (inclusive of lowerHeight, exclusive of upperHeight)
+// SearchEvents searches for events between the given block heights (inclusive of lowerHeight, exclusive of upperHeight).
+// Results are paginated by transaction, and the returned total is the number of matching transactions: a batch
+// transaction contributes all of its events to the page.
 func (c *RoundRobinClient) SearchEvents(lowerHeight, upperHeight int64, page, limit int) ([]state.MissingEvent, int, error) {
 	conn, err := c.pool.Get()
 	if err != nil {
@@ -170,99 +226,119 @@ func (c *RoundRobinClient) SearchEvents(lowerHeight, upperHeight int64, page, li
 			continue // Attempt to return all the transactions we can
 		}
 
-		missingEvents = append(missingEvents, state.MissingEvent{
-			EventId:      decoded.Metadata.EventId,
-			ReceivedTime: time.Now(),
-			BlockHeight:  tx.Height,
-		})
+		for _, metadata := range decoded.All() {
+			missingEvents = append(missingEvents, state.MissingEvent{
+				EventId:      metadata.EventId,
+				ReceivedTime: time.Now(),
+				BlockHeight:  tx.Height,
+			})
+		}
 	}
 
 	return missingEvents, res.TotalCount, nil
 }
 
-// GetRetentionPolicy fetches the retention policy from all available blockchain nodes and compares them, to ensure
-// that a malicious node is not returning a different policy to others.
+// GetRetentionPolicy fetches the retention policy in effect at the latest block height, or nil if a policy has not been
+// set. The policy is derived from the verified policy history, so may be fetched from any single node.
 func (c *RoundRobinClient) GetRetentionPolicy() (*offchain.StoredPolicy, error) {
-	clients := c.pool.GetAll(false)
+	history, height, err := c.getVerifiedPolicyHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	return history.At(height), nil
+}
+
+// GetRetentionPolicyHistory fetches every version of the retention policy. The history is verified by a proof chain
+// from the retention policy tree to the app hash of a block header, so may be fetched from any single node.
+func (c *RoundRobinClient) GetRetentionPolicyHistory() (offchain.PolicyHistory, error) {
+	history, _, err := c.getVerifiedPolicyHistory()
+	return history, err
+}
 
-	if len(clients) < c.config.Blockchain.MinimumNodes {
-		return nil, fmt.Errorf("%w, expected: %d, actual: %d", ErrNotEnoughNodes, c.config.Blockchain.MinimumNodes, len(clients))
+// getVerifiedPolicyHistory fetches and verifies the policy history, and returns it alongside the height of the state
+// that it reflects.
+func (c *RoundRobinClient) getVerifiedPolicyHistory() (offchain.PolicyHistory, int64, error) {
+	conn, err := c.pool.Get()
+	if err != nil {
+		return nil, 0, err
 	}
 
-	var errs []error
-	policies := make([]*offchain.StoredPolicy, 0, len(clients))
-	for _, client := range clients {
-		// Don't use defer cancelFunc in loop
-		ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
-		policy, err := c.getRetentionPolicyForClient(ctx, client)
-		cancelFunc()
-		if err != nil {
-			c.logger.Error("Failed to get retention policy", zap.Error(err), zap.String("client", client.Remote()))
-			errs = append(errs, err)
-			continue
-		}
+	// Allow time for the header committing to the latest state to be created
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelFunc()
 
-		policies = append(policies, policy)
+	data := rpc.MuxedRequest{App: retention.AppName}
+	marshalled, err := json.Marshal(data)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if len(policies) < c.config.Blockchain.MinimumNodes || len(policies) == 0 {
-		return nil, errors.Join(
-			append(errs,
-				fmt.Errorf("%w, expected: %d, actual: %d", ErrNotEnoughNodes, c.config.Blockchain.MinimumNodes, len(policies)),
-			)...,
-		)
+	res, err := conn.ABCIQueryWithOptions(ctx, retention.QueryPathHistory, marshalled, ABCIQueryOptions)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Ensure all policies are the same
-	if len(policies) == 1 {
-		return policies[0], nil
+	if res.Response.Code != retention.CodeOk {
+		return nil, 0, fmt.Errorf("%w, code: %s:%d, log: %s", ErrABCIQueryFailed, res.Response.Codespace, res.Response.Code, res.Response.Log)
 	}
 
-	firstPolicy := policies[0]
-	for i := 1; i < len(policies); i++ {
-		policy := policies[i]
+	// Validate proof, and use the proven value in place of the value returned by the node
+	value, err := c.verifyQuery(ctx, conn, res.Response, retention.AppName, []byte(retention.TreeKeyHistory))
+	if err != nil {
+		return nil, 0, err
+	}
 
-		if policy == nil && firstPolicy == nil {
-			continue
-		} else if policy == nil || firstPolicy == nil {
-			return nil, fmt.Errorf("%w, policy[0]: %v\npolicy[%d]: %v", ErrPolicyMismatch, firstPolicy, i, policy)
-		} else {
-			if !firstPolicy.Equal(*policy) {
-				return nil, fmt.Errorf("%w, policy[0]: %v\npolicy[%d]: %v", ErrPolicyMismatch, firstPolicy, i, policy)
-			}
+	history := make(offchain.PolicyHistory, 0)
+	if value != nil {
+		if err := json.Unmarshal(value, &history); err != nil {
+			return nil, 0, err
 		}
 	}
 
-	return firstPolicy, nil
+	return history, res.Response.Height, nil
 }
 
-func (c *RoundRobinClient) getRetentionPolicyForClient(ctx context.Context, client http.HTTP) (*offchain.StoredPolicy, error) {
-	options := rpcclient.ABCIQueryOptions{
-		Height: 0,
-		Prove:  false,
+// GetLegalHolds fetches every legal hold, including those that have been released or have expired. The holds are
+// verified by a proof chain from the retention policy tree to the app hash of a block header, so that a single malicious
+// node cannot hide holds and cause protected events to be deleted.
+func (c *RoundRobinClient) GetLegalHolds() (offchain.LegalHolds, error) {
+	conn, err := c.pool.Get()
+	if err != nil {
+		return nil, err
 	}
 
+	// Allow time for the header committing to the latest state to be created
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelFunc()
+
 	data := rpc.MuxedRequest{App: retention.AppName}
 	marshalled, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.ABCIQueryWithOptions(ctx, "/", marshalled, options)
+	res, err := conn.ABCIQueryWithOptions(ctx, retention.QueryPathHolds, marshalled, ABCIQueryOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Response.Codespace == retention.Codespace && res.Response.Code == retention.CodeOk {
-		var policy offchain.StoredPolicy
-		if err := json.Unmarshal(res.Response.Value, &policy); err != nil {
+	if res.Response.Code != retention.CodeOk {
+		return nil, fmt.Errorf("%w, code: %s:%d, log: %s", ErrABCIQueryFailed, res.Response.Codespace, res.Response.Code, res.Response.Log)
+	}
+
+	// Validate proof, and use the proven value in place of the value returned by the node
+	value, err := c.verifyQuery(ctx, conn, res.Response, retention.AppName, []byte(retention.TreeKeyHolds))
+	if err != nil {
+		return nil, err
+	}
+
+	holds := make(offchain.LegalHolds, 0)
+	if value != nil {
+		if err := json.Unmarshal(value, &holds); err != nil {
 			return nil, err
 		}
-
-		return &policy, nil
-	} else if res.Response.Codespace == retention.Codespace && res.Response.Code == retention.CodePolicyNotSet {
-		return nil, nil
-	} else {
-		return nil, fmt.Errorf("%w, code: %s:%d, log: %s", ErrABCIQueryFailed, res.Response.Codespace, res.Response.Code, res.Response.Log)
 	}
+
+	return holds, nil
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
 	"github.com/pkg/errors"
@@ -13,13 +12,16 @@ import (
 
 var ErrPrincipalNotFound = errors.New("identity not found")
 
+// GetIdentity fetches the identity of the given principal. The identity, or its absence, is verified by a proof chain
+// from the identity tree to the app hash of a block header, so that a single malicious node cannot forge identities.
 func (c *RoundRobinClient) GetIdentity(principal identity.Principal) (identity.IdentityData, error) {
 	conn, err := c.pool.Get()
 	if err != nil {
 		return identity.IdentityData{}, err
 	}
 
-	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	// Allow time for the header committing to the latest state to be created
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancelFunc()
 
 	// Create data payload to route to the correct sub-app
@@ -29,35 +31,31 @@ func (c *RoundRobinClient) GetIdentity(principal identity.Principal) (identity.I
 		return identity.IdentityData{}, err
 	}
 
-	res, err := conn.ABCIQuery(ctx, fmt.Sprintf("/%s", principal.String()), dataMarshalled)
+	res, err := conn.ABCIQueryWithOptions(ctx, fmt.Sprintf("/%s", principal.String()), dataMarshalled, ABCIQueryOptions)
 	if err != nil {
 		return identity.IdentityData{}, err
 	}
 
-	if res.Response.Code != identity.CodeOk {
-		if res.Response.Codespace == identity.Codespace && res.Response.Code == identity.CodeNotFound {
-			return identity.IdentityData{}, ErrPrincipalNotFound
-		} else if res.Response.Code != identity.CodeOk {
-			return identity.IdentityData{}, fmt.Errorf(
-				"unexpected error fetching principal (code %s-%d): %s, %s",
-				res.Response.Codespace, res.Response.Code, res.Response.Info, res.Response.Log,
-			)
-		} else {
-			return identity.IdentityData{}, errors.Wrapf(
-				ErrABCIQueryFailed,
-				"code: %s:%d, log: %s, info: %s",
-				res.Response.Codespace, res.Response.Code, res.Response.Log, res.Response.Info,
-			)
-		}
+	notFound := res.Response.Codespace == identity.Codespace && res.Response.Code == identity.CodeNotFound
+	if res.Response.Code != identity.CodeOk && !notFound {
+		return identity.IdentityData{}, fmt.Errorf(
+			"unexpected error fetching principal (code %s-%d): %s, %s",
+			res.Response.Codespace, res.Response.Code, res.Response.Info, res.Response.Log,
+		)
 	}
 
-	var identityData identity.IdentityData
-	if err := json.Unmarshal(res.Response.Value, &identityData); err != nil {
+	// Validate proof, and use the proven value in place of the value returned by the node
+	value, err := c.verifyQuery(ctx, conn, res.Response, identity.AppName, principal.TreeKey())
+	if err != nil {
 		return identity.IdentityData{}, err
 	}
 
-	// Validate proof
-	if err := proof.ValidateProofOps(res.Response.ProofOps); err != nil {
+	if value == nil {
+		return identity.IdentityData{}, ErrPrincipalNotFound
+	}
+
+	var identityData identity.IdentityData
+	if err := json.Unmarshal(value, &identityData); err != nil {
 		return identity.IdentityData{}, err
 	}
 
@@ -0,0 +1,123 @@
+package blockchain
+
+import (
+	"github.com/cometbft/cometbft/light/store"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"sort"
+	"sync"
+)
+
+// memoryLightStore holds the light blocks trusted by the light client in memory. Trust is re-established from the
+// configured trusted header each time the interface starts.
+type memoryLightStore struct {
+	mu     sync.RWMutex
+	blocks map[int64]*cmttypes.LightBlock
+}
+
+var _ store.Store = (*memoryLightStore)(nil)
+
+func newMemoryLightStore() *memoryLightStore {
+	return &memoryLightStore{
+		blocks: make(map[int64]*cmttypes.LightBlock),
+	}
+}
+
+func (s *memoryLightStore) SaveLightBlock(lb *cmttypes.LightBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[lb.Height] = lb
+	return nil
+}
+
+func (s *memoryLightStore) DeleteLightBlock(height int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blocks, height)
+	return nil
+}
+
+func (s *memoryLightStore) LightBlock(height int64) (*cmttypes.LightBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lb, ok := s.blocks[height]
+	if !ok {
+		return nil, store.ErrLightBlockNotFound
+	}
+
+	return lb, nil
+}
+
+func (s *memoryLightStore) LastLightBlockHeight() (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	heights := s.heights()
+	if len(heights) == 0 {
+		return -1, nil
+	}
+
+	return heights[len(heights)-1], nil
+}
+
+func (s *memoryLightStore) FirstLightBlockHeight() (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	heights := s.heights()
+	if len(heights) == 0 {
+		return -1, nil
+	}
+
+	return heights[0], nil
+}
+
+func (s *memoryLightStore) LightBlockBefore(height int64) (*cmttypes.LightBlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	heights := s.heights()
+	for i := len(heights) - 1; i >= 0; i-- {
+		if heights[i] < height {
+			return s.blocks[heights[i]], nil
+		}
+	}
+
+	return nil, store.ErrLightBlockNotFound
+}
+
+// Prune removes the oldest light blocks until at most size remain.
+func (s *memoryLightStore) Prune(size uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	heights := s.heights()
+	for i := 0; i < len(heights)-int(size); i++ {
+		delete(s.blocks, heights[i])
+	}
+
+	return nil
+}
+
+func (s *memoryLightStore) Size() uint16 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return uint16(len(s.blocks))
+}
+
+// heights returns the heights of the stored light blocks in ascending order. The caller must hold the lock.
+func (s *memoryLightStore) heights() []int64 {
+	heights := make([]int64, 0, len(s.blocks))
+	for height := range s.blocks {
+		heights = append(heights, height)
+	}
+
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] < heights[j]
+	})
+
+	return heights
+}
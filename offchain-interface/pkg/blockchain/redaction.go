@@ -0,0 +1,123 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"time"
+)
+
+var ErrUnprovenRedaction = errors.New("listed redaction order could not be proven")
+
+// GetRedaction fetches the redaction order recorded for the given event, or nil if the event has not been redacted. The
+// order, or its absence, is verified by a proof chain from the events tree to the app hash of a block header, so that a
+// single malicious node cannot cause event data to be erased.
+func (c *RoundRobinClient) GetRedaction(eventId events.EventHash) (*events.Redaction, error) {
+	conn, err := c.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	// Allow time for the header committing to the latest state to be created
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelFunc()
+
+	data := rpc.MuxedRequest{App: events.AppName}
+	dataMarshalled, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := conn.ABCIQueryWithOptions(ctx, events.QueryPathRedaction+eventId.String(), dataMarshalled, ABCIQueryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	notFound := res.Response.Codespace == events.Codespace && res.Response.Code == events.CodeRedactionNotFound
+	if res.Response.Code != events.CodeOk && !notFound {
+		return nil, fmt.Errorf(
+			"%w, code: %s:%d, log: %s, info: %s",
+			ErrABCIQueryFailed, res.Response.Codespace, res.Response.Code, res.Response.Log, res.Response.Info,
+		)
+	}
+
+	// Validate proof, and use the proven value in place of the value returned by the node
+	value, err := c.verifyQuery(ctx, conn, res.Response, events.AppName, events.RedactionTreeKey(eventId))
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	var redaction events.Redaction
+	if err := json.Unmarshal(value, &redaction); err != nil {
+		return nil, err
+	}
+
+	return &redaction, nil
+}
+
+// ListRedactions lists a page of the redaction orders recorded between the given block heights (inclusive), starting
+// from the given hex encoded cursor, or the first page if the cursor is empty. Each order is verified by a proof chain
+// to the app hash of a block header, and replaced by the proven order.
+func (c *RoundRobinClient) ListRedactions(lowerHeight, upperHeight int64, cursor string) (events.RedactionListResponse, error) {
+	conn, err := c.pool.Get()
+	if err != nil {
+		return events.RedactionListResponse{}, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelFunc()
+
+	data := rpc.MuxedRequest{App: events.AppName}
+	dataMarshalled, err := json.Marshal(data)
+	if err != nil {
+		return events.RedactionListResponse{}, err
+	}
+
+	path := fmt.Sprintf("%s%d/%d", events.QueryPathRedactionsByHeight, lowerHeight, upperHeight)
+	if cursor != "" {
+		path += fmt.Sprintf("?%s=%s", events.QueryParamCursor, cursor)
+	}
+
+	res, err := conn.ABCIQueryWithOptions(ctx, path, dataMarshalled, ABCIQueryOptions)
+	if err != nil {
+		return events.RedactionListResponse{}, err
+	}
+
+	if res.Response.Code != events.CodeOk {
+		return events.RedactionListResponse{}, fmt.Errorf(
+			"%w, code: %s:%d, log: %s, info: %s",
+			ErrABCIQueryFailed, res.Response.Codespace, res.Response.Code, res.Response.Log, res.Response.Info,
+		)
+	}
+
+	var list events.RedactionListResponse
+	if err := json.Unmarshal(res.Response.Value, &list); err != nil {
+		return events.RedactionListResponse{}, err
+	}
+
+	for i, listed := range list.Redactions {
+		key := events.RedactionTreeKey(listed.Redaction.EventId)
+
+		value, err := c.verifyListed(ctx, conn, res.Response, listed.Proof, events.AppName, key)
+		if err != nil {
+			return events.RedactionListResponse{}, err
+		}
+
+		if value == nil {
+			return events.RedactionListResponse{}, fmt.Errorf("%w: %s", ErrUnprovenRedaction, listed.Redaction.EventId)
+		}
+
+		if err := json.Unmarshal(value, &list.Redactions[i].Redaction); err != nil {
+			return events.RedactionListResponse{}, err
+		}
+	}
+
+	return list, nil
+}
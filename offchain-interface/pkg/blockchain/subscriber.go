@@ -108,16 +108,19 @@ func (c *RoundRobinClient) subscribe(
 				continue
 			}
 
-			eventIdDecoded, err := hex.DecodeString(eventIdSlice[0])
-			if err != nil {
-				logger.Error("Failed to decode event ID from hex", zap.Error(err), zap.String("event_id_hex", eventIdSlice[0]))
-				continue
-			}
+			// Batch transactions emit one event per created event, so there may be multiple event IDs
+			for _, eventIdHex := range eventIdSlice {
+				eventIdDecoded, err := hex.DecodeString(eventIdHex)
+				if err != nil {
+					logger.Error("Failed to decode event ID from hex", zap.Error(err), zap.String("event_id_hex", eventIdHex))
+					continue
+				}
 
-			eventId := events.EventHash(eventIdDecoded)
-			missingEvent := state.NewMissingEvent(eventId, time.Now(), blockHeight)
+				eventId := events.EventHash(eventIdDecoded)
+				missingEvent := state.NewMissingEvent(eventId, time.Now(), blockHeight)
 
-			eventCh <- missingEvent
+				eventCh <- missingEvent
+			}
 		}
 
 		if newBlockCh == nil && chainEventCh == nil {
@@ -0,0 +1,285 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/light"
+	"github.com/cometbft/cometbft/light/provider"
+	lighthttp "github.com/cometbft/cometbft/light/provider/http"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/cometbft/cometbft/rpc/client/http"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// headerPollInterval is the time between attempts to fetch a header that has not yet been committed
+	headerPollInterval = 500 * time.Millisecond
+	// headerCacheSize is the number of verified headers retained, before the cache is cleared
+	headerCacheSize = 1024
+	// defaultTrustPeriod is the period for which a trusted header can be used to verify later headers, if not set by
+	// the configuration
+	defaultTrustPeriod = 168 * time.Hour
+)
+
+var (
+	ErrHeaderUnavailable    = errors.New("block header unavailable")
+	ErrValidatorSetMismatch = errors.New("validator set does not match block header")
+)
+
+// verifyQuery verifies the proof chain carried by a query response, from the sub-application's tree to the app hash
+// in the header of the following block, and returns the proven value, or nil if the proof is of the key's absence.
+func (c *RoundRobinClient) verifyQuery(
+	ctx context.Context,
+	queried *http.HTTP,
+	res abci.ResponseQuery,
+	appName string,
+	key []byte,
+) ([]byte, error) {
+	// The state at height H is committed to by the header of block H+1
+	header, err := c.verifiedHeader(ctx, queried, res.Height+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return proof.VerifyChain(res.ProofOps, appName, key, header.AppHash, header.Version.App)
+}
+
+// verifyListed verifies the proof of an entry returned by a listing query, chained to the app hash through the
+// multiplex proof of the response, and returns the proven value.
+func (c *RoundRobinClient) verifyListed(
+	ctx context.Context,
+	queried *http.HTTP,
+	res abci.ResponseQuery,
+	entryProof crypto.ProofOp,
+	appName string,
+	key []byte,
+) ([]byte, error) {
+	res.ProofOps = proof.ListedProofOps(entryProof, res.ProofOps)
+	return c.verifyQuery(ctx, queried, res, appName, key)
+}
+
+// GetAppVersionAt returns the app version under which the transactions of the given block were processed. Upgrades
+// take effect from the start of their block, and are reported in the header of the following block, which is verified.
+func (c *RoundRobinClient) GetAppVersionAt(height int64) (uint64, error) {
+	conn, err := c.pool.Get()
+	if err != nil {
+		return 0, err
+	}
+
+	// Allow time for the following block to be created
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelFunc()
+
+	header, err := c.verifiedHeader(ctx, conn, height+1)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.Version.App, nil
+}
+
+// verifiedHeader returns the header of the given block. If a trusted header is configured, the header is verified by a
+// light client from the trusted header, cross-checked against every node. Otherwise, where possible, the header is
+// fetched from a node other than the one that was queried, so that a single malicious node cannot supply both a forged
+// proof and the header that it is verified against, and its commit must be signed by the validator set reported for
+// the block.
+func (c *RoundRobinClient) verifiedHeader(ctx context.Context, queried *http.HTTP, height int64) (*cmttypes.Header, error) {
+	c.headerMu.Lock()
+	header, ok := c.headers[height]
+	c.headerMu.Unlock()
+
+	if ok {
+		return header, nil
+	}
+
+	node := queried
+	for _, client := range c.pool.GetAll(false) {
+		if client.Remote() != queried.Remote() {
+			client := client
+			node = &client
+			break
+		}
+	}
+
+	signedHeader, err := c.waitForCommit(ctx, node, height)
+	if err != nil {
+		return nil, err
+	}
+
+	lightClient, err := c.lightClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if lightClient != nil {
+		c.lightMu.Lock()
+		lightBlock, err := lightClient.VerifyLightBlockAtHeight(ctx, height, time.Now())
+		c.lightMu.Unlock()
+
+		if err != nil {
+			return nil, fmt.Errorf("error verifying header of block %d: %w", height, err)
+		}
+
+		header = lightBlock.Header
+	} else {
+		if node == queried {
+			c.logger.Warn("Verifying proof against header from the queried node, as no other node is available")
+		}
+
+		if err := verifyReportedCommit(ctx, node, signedHeader); err != nil {
+			return nil, err
+		}
+
+		header = signedHeader.Header
+	}
+
+	c.headerMu.Lock()
+	if len(c.headers) >= headerCacheSize {
+		c.headers = make(map[int64]*cmttypes.Header)
+	}
+	c.headers[height] = header
+	c.headerMu.Unlock()
+
+	return header, nil
+}
+
+// verifyReportedCommit checks that the header's commit is signed by the validator set that the node reports for the
+// block, and that the validator set is the one committed to by the header. Without a trusted header, this only
+// establishes that the header is consistent with the node's view of the chain.
+func verifyReportedCommit(ctx context.Context, node *http.HTTP, signedHeader cmttypes.SignedHeader) error {
+	if err := signedHeader.ValidateBasic(signedHeader.ChainID); err != nil {
+		return err
+	}
+
+	validators, err := fetchValidators(ctx, node, signedHeader.Height)
+	if err != nil {
+		return err
+	}
+
+	validatorSet, err := cmttypes.ValidatorSetFromExistingValidators(validators)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(validatorSet.Hash(), signedHeader.ValidatorsHash) {
+		return fmt.Errorf("%w: block %d", ErrValidatorSetMismatch, signedHeader.Height)
+	}
+
+	if err := validatorSet.VerifyCommitLight(signedHeader.ChainID, signedHeader.Commit.BlockID, signedHeader.Height, signedHeader.Commit); err != nil {
+		return fmt.Errorf("invalid commit for block %d: %w", signedHeader.Height, err)
+	}
+
+	return nil
+}
+
+// lightClient returns the light client that verifies headers from the configured trusted header, creating it on first
+// use. Returns nil if no trusted header is configured.
+func (c *RoundRobinClient) lightClient(ctx context.Context) (*light.Client, error) {
+	trust := c.config.Blockchain
+	if trust.TrustHeight <= 0 {
+		return nil, nil
+	}
+
+	c.lightMu.Lock()
+	defer c.lightMu.Unlock()
+
+	if c.light != nil {
+		return c.light, nil
+	}
+
+	trustHash, err := hex.DecodeString(trust.TrustHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust hash: %w", err)
+	}
+
+	period := trust.TrustPeriod.Duration()
+	if period <= 0 {
+		period = defaultTrustPeriod
+	}
+
+	live := c.pool.GetAll(false)
+	if len(live) == 0 {
+		return nil, fmt.Errorf("%w: no nodes available", ErrHeaderUnavailable)
+	}
+
+	// The chain ID reported by the node is bound by the trusted hash, so need not be trusted
+	status, err := live[0].Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chainId := status.NodeInfo.Network
+
+	// The first node is the primary source of headers, and the others are witnesses that cross-check them
+	var providers []provider.Provider
+	for _, node := range c.pool.GetAll(true) {
+		node := node
+		providers = append(providers, lighthttp.NewWithClient(chainId, &node))
+	}
+
+	var options []light.Option
+	if len(providers) == 1 {
+		options = append(options, light.SequentialVerification())
+	}
+
+	lightClient, err := light.NewClient(
+		ctx,
+		chainId,
+		light.TrustOptions{Period: period, Height: trust.TrustHeight, Hash: trustHash},
+		providers[0],
+		providers[1:],
+		newMemoryLightStore(),
+		options...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating light client: %w", err)
+	}
+
+	c.light = lightClient
+	return lightClient, nil
+}
+
+// waitForCommit fetches the signed header of the given block, waiting for the block to be committed if required: the
+// header that commits to the latest state is not created until the next block.
+func (c *RoundRobinClient) waitForCommit(ctx context.Context, node *http.HTTP, height int64) (cmttypes.SignedHeader, error) {
+	for {
+		res, err := node.Commit(ctx, &height)
+		if err == nil {
+			return res.SignedHeader, nil
+		}
+
+		c.logger.Debug("Waiting for block to be committed", zap.Int64("height", height), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return cmttypes.SignedHeader{}, fmt.Errorf("%w: height %d: %w", ErrHeaderUnavailable, height, err)
+		case <-time.After(headerPollInterval):
+		}
+	}
+}
+
+func fetchValidators(ctx context.Context, node *http.HTTP, height int64) ([]*cmttypes.Validator, error) {
+	var validators []*cmttypes.Validator
+
+	page, perPage := 1, 100
+	for {
+		res, err := node.Validators(ctx, &height, &page, &perPage)
+		if err != nil {
+			return nil, err
+		}
+
+		validators = append(validators, res.Validators...)
+		if len(res.Validators) == 0 || len(validators) >= res.Total {
+			return validators, nil
+		}
+
+		page++
+	}
+}
@@ -0,0 +1,107 @@
+package redaction
+
+import (
+	"context"
+	"github.com/RyanW02/wineventchain/offchain-interface/internal/config"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/blockchain"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"go.uber.org/zap"
+	"math"
+	"time"
+)
+
+// Agent watches the blockchain for redaction orders, and erases the data of the targeted events from the repository.
+type Agent struct {
+	config           config.Config
+	logger           *zap.Logger
+	blockchainClient *blockchain.RoundRobinClient
+	repository       repository.Repository
+
+	// nextHeight is the lowest block height that has not yet been scanned for redaction orders. It is not persisted,
+	// as redacting an event is idempotent, so every order is re-applied after a restart.
+	nextHeight int64
+}
+
+func NewAgent(
+	config config.Config,
+	logger *zap.Logger,
+	blockchainClient *blockchain.RoundRobinClient,
+	repository repository.Repository,
+) *Agent {
+	return &Agent{
+		config:           config,
+		logger:           logger,
+		blockchainClient: blockchainClient,
+		repository:       repository,
+	}
+}
+
+func (a *Agent) StartLoop(shutdownCh chan chan error) {
+	ticker := time.NewTicker(a.config.Redaction.ScanInterval.Duration())
+
+	if a.config.Redaction.RunAtStartup {
+		if err := a.scanAndRedact(); err != nil {
+			a.logger.Error("Failed to run redaction scan at startup", zap.Error(err))
+		}
+	}
+
+	for {
+		select {
+		case ch := <-shutdownCh:
+			ch <- nil
+			return
+		case <-ticker.C:
+			if err := a.scanAndRedact(); err != nil {
+				a.logger.Error("Failed to run redaction scan", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (a *Agent) scanAndRedact() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), a.config.Redaction.ScanTimeout.Duration())
+	defer cancelFunc()
+
+	a.logger.Debug("Scanning for redaction orders", zap.Int64("from_height", a.nextHeight))
+
+	// Every order in a block is committed at once, so once an order at height H has been seen, no further orders can
+	// appear at or below H. The next height is only advanced once the scan completes, so that a failed scan is retried.
+	nextHeight := a.nextHeight
+	var cursor string
+	var redacted int
+	for {
+		list, err := a.blockchainClient.ListRedactions(a.nextHeight, math.MaxInt64, cursor)
+		if err != nil {
+			return err
+		}
+
+		// Each listed order has been proven by the client, so can be acted upon directly
+		for _, listed := range list.Redactions {
+			redaction := listed.Redaction
+			if err := a.repository.Events().Redact(ctx, redaction); err != nil {
+				return err
+			}
+
+			a.logger.Info(
+				"Redacted event data",
+				zap.Stringer("event_id", redaction.EventId),
+				zap.Int64("height", redaction.Height),
+				zap.String("author", redaction.Author.String()),
+			)
+
+			redacted++
+			nextHeight = redaction.Height + 1
+		}
+
+		if list.Next == "" {
+			break
+		}
+
+		cursor = list.Next
+	}
+
+	a.nextHeight = nextHeight
+
+	a.logger.Debug("Redaction scan complete", zap.Int("redacted_count", redacted))
+	return nil
+}
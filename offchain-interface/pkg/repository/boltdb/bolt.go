@@ -0,0 +1,66 @@
+package boltdb
+
+import (
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+	"time"
+)
+
+// BoltRepository stores events in a single bbolt database file, for nodes that are deployed without a database server.
+type BoltRepository struct {
+	db         *bolt.DB
+	events     *BoltEventRepository
+	challenges *BoltChallengeRepository
+}
+
+var _ repository.Repository = (*BoltRepository)(nil)
+
+// Open opens the database file at path, creating it if it does not exist.
+func Open(logger *zap.Logger, path string) (*BoltRepository, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second * 10})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBoltRepository(logger, db), nil
+}
+
+func NewBoltRepository(logger *zap.Logger, db *bolt.DB) *BoltRepository {
+	return &BoltRepository{
+		db:         db,
+		events:     NewBoltEventRepository(logger, db),
+		challenges: NewBoltChallengeRepository(logger, db),
+	}
+}
+
+// InitSchema creates the buckets used by the repository, if they do not already exist.
+func (b *BoltRepository) InitSchema() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range allBuckets() {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *BoltRepository) Events() repository.EventRepository {
+	return b.events
+}
+
+func (b *BoltRepository) Challenges() repository.ChallengeRepository {
+	return b.challenges
+}
+
+func (b *BoltRepository) TestConnection() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return nil
+	})
+}
+
+func (b *BoltRepository) Close() error {
+	return b.db.Close()
+}
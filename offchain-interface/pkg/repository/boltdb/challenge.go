@@ -0,0 +1,103 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+	"time"
+)
+
+// BoltChallengeRepository stores challenges keyed by principal and challenge, with the time at which the challenge was
+// issued as the value.
+type BoltChallengeRepository struct {
+	logger *zap.Logger
+	db     *bolt.DB
+}
+
+func NewBoltChallengeRepository(logger *zap.Logger, db *bolt.DB) *BoltChallengeRepository {
+	return &BoltChallengeRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+var _ repository.ChallengeRepository = (*BoltChallengeRepository)(nil)
+
+func challengeKey(principal identity.Principal, challenge []byte) []byte {
+	return append(valuePrefix([]byte(principal)), challenge...)
+}
+
+func (b *BoltChallengeRepository) AddChallenge(ctx context.Context, principal identity.Principal, challenge []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		timestamp := binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano()))
+		return tx.Bucket(bucketChallenges).Put(challengeKey(principal, challenge), timestamp)
+	})
+}
+
+func (b *BoltChallengeRepository) GetAndRemoveChallenge(
+	ctx context.Context,
+	principal identity.Principal,
+	challenge []byte,
+	challengeLifetime time.Duration,
+) (isValid bool, err error) {
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		key := challengeKey(principal, challenge)
+
+		value := tx.Bucket(bucketChallenges).Get(key)
+		if value == nil {
+			return nil
+		}
+
+		issuedAt := time.Unix(0, int64(binary.BigEndian.Uint64(value)))
+		isValid = issuedAt.After(time.Now().Add(-challengeLifetime))
+
+		return tx.Bucket(bucketChallenges).Delete(key)
+	})
+
+	return
+}
+
+func (b *BoltChallengeRepository) DropExpiredChallenges(ctx context.Context, challengeLifetime time.Duration) error {
+	cutoff := time.Now().Add(-challengeLifetime)
+
+	var count int
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketChallenges)
+
+		// Keys are collected first, as deleting while iterating with a cursor may skip keys
+		var expired [][]byte
+		if err := bucket.ForEach(func(key, value []byte) error {
+			if time.Unix(0, int64(binary.BigEndian.Uint64(value))).Before(cutoff) {
+				expired = append(expired, bytes.Clone(key))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		count = len(expired)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		b.logger.Debug("no expired challenges to remove")
+		return nil
+	}
+
+	b.logger.Info("removed expired challenges", zap.Int("count", count))
+	return nil
+}
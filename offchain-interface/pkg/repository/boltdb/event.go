@@ -0,0 +1,492 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type BoltEventRepository struct {
+	logger *zap.Logger
+	db     *bolt.DB
+}
+
+// Compile-time type validation
+var _ repository.EventRepository = (*BoltEventRepository)(nil)
+
+func NewBoltEventRepository(logger *zap.Logger, db *bolt.DB) *BoltEventRepository {
+	return &BoltEventRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (b *BoltEventRepository) GetEventById(ctx context.Context, id events.EventHash) (event events.StoredEvent, found bool, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		event, found, err = getEvent(tx, id)
+		return err
+	})
+
+	return
+}
+
+func (b *BoltEventRepository) GetEventsById(ctx context.Context, ids []events.EventHash) ([]events.StoredEvent, error) {
+	var stored []events.StoredEvent
+	err := b.db.View(func(tx *bolt.Tx) error {
+		for _, id := range ids {
+			event, found, err := getEvent(tx, id)
+			if err != nil {
+				return err
+			}
+
+			if found {
+				stored = append(stored, event)
+			}
+		}
+
+		return nil
+	})
+
+	return stored, err
+}
+
+func (b *BoltEventRepository) GetEventByTx(ctx context.Context, txHash []byte) (event events.StoredEvent, found bool, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		prefix := valuePrefix(txHash)
+
+		key, _ := tx.Bucket(indexBucket(repository.PropertyTxHash)).Cursor().Seek(prefix)
+		if key == nil || !bytes.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		event, err = loadEvent(tx, trailingSeq(key))
+		found = err == nil
+		return err
+	})
+
+	return
+}
+
+func (b *BoltEventRepository) SearchEvents(ctx context.Context, filters []repository.Filter, limit, page int, first *events.EventHash) ([]events.StoredEvent, error) {
+	// Matches validates the filter before comparing it against the event
+	for _, filter := range filters {
+		if _, err := filter.Matches(events.StoredEvent{}); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []events.StoredEvent
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// Lookup position of [first] event. If the event is not found, return the first page of results
+		var firstSeq *uint64
+		if first != nil {
+			if seq := tx.Bucket(bucketEventIds).Get(*first); seq != nil {
+				firstSeq = utils.Ptr(trailingSeq(seq))
+			}
+		}
+
+		skip := page * limit
+		return scanCandidates(tx, filters, func(seq uint64) (bool, error) {
+			if firstSeq != nil && seq > *firstSeq {
+				return true, nil
+			}
+
+			event, err := loadEvent(tx, seq)
+			if err != nil {
+				return false, err
+			}
+
+			for _, filter := range filters {
+				if matches, err := filter.Matches(event); err != nil || !matches {
+					return true, err
+				}
+			}
+
+			if skip > 0 {
+				skip--
+				return true, nil
+			}
+
+			results = append(results, event)
+			return limit <= 0 || len(results) < limit, nil
+		})
+	})
+
+	return results, err
+}
+
+func (b *BoltEventRepository) EventCount(ctx context.Context) (count int, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(bucketEvents).Stats().KeyN
+		return nil
+	})
+
+	return
+}
+
+func (b *BoltEventRepository) Store(ctx context.Context, event events.StoredEvent) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketEventIds).Get(event.Metadata.EventId) != nil {
+			return repository.ErrEventAlreadyStored
+		}
+
+		return putEvent(tx, event)
+	})
+}
+
+func (b *BoltEventRepository) CountHeldEvents(ctx context.Context, hold offchain.LegalHold) (count int, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEvents).ForEach(func(_, value []byte) error {
+			var event events.StoredEvent
+			if err := json.Unmarshal(value, &event); err != nil {
+				return err
+			}
+
+			if hold.Criteria.Matches(event) {
+				count++
+			}
+
+			return nil
+		})
+	})
+
+	return
+}
+
+func (b *BoltEventRepository) Redact(ctx context.Context, redaction events.Redaction) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		seq := tx.Bucket(bucketEventIds).Get(redaction.EventId)
+		if seq == nil {
+			// Store a placeholder, so that the event data is not later backfilled
+			return putEvent(tx, events.StoredEvent{
+				EventWithData: events.EventWithData{EventData: events.EventData{}},
+				Metadata:      events.Metadata{EventId: redaction.EventId},
+				Redaction:     &redaction,
+			})
+		}
+
+		event, err := loadEvent(tx, trailingSeq(seq))
+		if err != nil {
+			return err
+		}
+
+		// The indexed properties are unchanged, so only the event itself is rewritten
+		event.EventWithData.EventData = events.EventData{}
+		event.Redaction = &redaction
+
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(bucketEvents).Put(seq, encoded)
+	})
+}
+
+func (b *BoltEventRepository) DropExpiredEvents(ctx context.Context, policy offchain.RetentionPolicy, holds offchain.LegalHolds) error {
+	plan, err := buildRetentionPlan(policy, time.Now())
+	if err != nil {
+		return err
+	}
+
+	b.logger.Info("Fetching events outside of retention policy to drop")
+
+	var expired []events.StoredEvent
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		expired, err = plan.expiredEvents(tx)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(expired) == 0 {
+		b.logger.Info("No events to drop")
+		return nil
+	}
+
+	b.logger.Info("Found events outside of retention policy", zap.Int("count", len(expired)))
+
+	// Delete the events, except for those protected by a legal hold
+	var deleted int
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		for _, event := range expired {
+			if isHeld(event, holds) {
+				continue
+			}
+
+			removed, err := deleteEvent(tx, event.Metadata.EventId)
+			if err != nil {
+				return err
+			}
+
+			if removed {
+				deleted++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b.logger.Info(
+		"Deleted events outside of retention policy",
+		zap.Int("deleted_count", deleted),
+		zap.Int("expected_count", len(expired)),
+		zap.Int("hold_count", len(holds)),
+	)
+
+	return nil
+}
+
+func isHeld(event events.StoredEvent, holds offchain.LegalHolds) bool {
+	for _, hold := range holds {
+		if hold.Criteria.Matches(event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getEvent(tx *bolt.Tx, id events.EventHash) (events.StoredEvent, bool, error) {
+	seq := tx.Bucket(bucketEventIds).Get(id)
+	if seq == nil {
+		return events.StoredEvent{}, false, nil
+	}
+
+	event, err := loadEvent(tx, trailingSeq(seq))
+	if err != nil {
+		return events.StoredEvent{}, false, err
+	}
+
+	return event, true, nil
+}
+
+func loadEvent(tx *bolt.Tx, seq uint64) (events.StoredEvent, error) {
+	value := tx.Bucket(bucketEvents).Get(seqKey(seq))
+	if value == nil {
+		return events.StoredEvent{}, fmt.Errorf("index refers to missing event %d", seq)
+	}
+
+	var event events.StoredEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return events.StoredEvent{}, err
+	}
+
+	return event, nil
+}
+
+func putEvent(tx *bolt.Tx, event events.StoredEvent) error {
+	seq, err := tx.Bucket(bucketEvents).NextSequence()
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(bucketEvents).Put(seqKey(seq), encoded); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(bucketEventIds).Put(event.Metadata.EventId, seqKey(seq)); err != nil {
+		return err
+	}
+
+	for bucket, key := range indexKeys(event, seq) {
+		if err := tx.Bucket([]byte(bucket)).Put(key, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteEvent deletes the event and its index entries, returning false if the event is not stored.
+func deleteEvent(tx *bolt.Tx, id events.EventHash) (bool, error) {
+	seqBytes := tx.Bucket(bucketEventIds).Get(id)
+	if seqBytes == nil {
+		return false, nil
+	}
+
+	seq := trailingSeq(seqBytes)
+	event, err := loadEvent(tx, seq)
+	if err != nil {
+		return false, err
+	}
+
+	for bucket, key := range indexKeys(event, seq) {
+		if err := tx.Bucket([]byte(bucket)).Delete(key); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Bucket(bucketEventIds).Delete(id); err != nil {
+		return false, err
+	}
+
+	return true, tx.Bucket(bucketEvents).Delete(seqKey(seq))
+}
+
+// scanCandidates calls fn with the sequence numbers of the events that may match the filters, newest first, until fn
+// returns false. The candidates are read from the index of an equality filter if there is one, or else from the
+// time index, bounded by any timestamp filters.
+func scanCandidates(tx *bolt.Tx, filters []repository.Filter, fn func(seq uint64) (bool, error)) error {
+	for _, filter := range filters {
+		if filter.Property == repository.PropertyEventId {
+			eventId, err := hex.DecodeString(filter.Value)
+			if err != nil {
+				return fmt.Errorf("%w: invalid event ID", repository.ErrInvalidFilter)
+			}
+
+			if seq := tx.Bucket(bucketEventIds).Get(eventId); seq != nil {
+				_, err := fn(trailingSeq(seq))
+				return err
+			}
+
+			return nil
+		}
+	}
+
+	for _, filter := range filters {
+		value, ok, err := lookupValue(filter)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return scanReverse(tx.Bucket(indexBucket(filter.Property)), valuePrefix(value), nil, nil, fn)
+		}
+	}
+
+	// Timestamp filters are exclusive bounds on the time index
+	var after, before []byte
+	for _, filter := range filters {
+		if filter.Property != repository.PropertyTimestamp {
+			continue
+		}
+
+		timestamp, err := time.Parse(utils.HtmlDateTimeFormat, filter.Value)
+		if err != nil {
+			return fmt.Errorf("%w: invalid timestamp", repository.ErrInvalidFilter)
+		}
+
+		if filter.Operator == repository.OperatorAfter {
+			after = timeKey(timestamp)
+		} else {
+			before = timeKey(timestamp)
+		}
+	}
+
+	return scanReverse(tx.Bucket(bucketTimeIndex), nil, after, before, fn)
+}
+
+// scanReverse calls fn with the sequence numbers of the keys with the prefix in reverse order, until fn returns false.
+// Keys whose remainder after the prefix is not greater than after, or not less than before, are skipped.
+func scanReverse(bucket *bolt.Bucket, prefix, after, before []byte, fn func(seq uint64) (bool, error)) error {
+	cursor := bucket.Cursor()
+
+	// Position the cursor on the last key before the upper bound
+	upper := append(bytes.Clone(prefix), before...)
+	var key []byte
+	if len(before) > 0 {
+		key, _ = cursor.Seek(upper)
+		if key == nil {
+			key, _ = cursor.Last()
+		}
+
+		for key != nil && bytes.Compare(key, upper) >= 0 {
+			key, _ = cursor.Prev()
+		}
+	} else if next := nextPrefix(prefix); next != nil {
+		key, _ = cursor.Seek(next)
+		if key == nil {
+			key, _ = cursor.Last()
+		} else {
+			key, _ = cursor.Prev()
+		}
+	} else {
+		key, _ = cursor.Last()
+	}
+
+	lower := append(bytes.Clone(prefix), after...)
+	for ; key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Prev() {
+		if len(after) > 0 && bytes.Compare(key[:len(lower)], lower) <= 0 {
+			return nil
+		}
+
+		next, err := fn(trailingSeq(key))
+		if err != nil || !next {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nextPrefix returns the smallest key greater than every key with the prefix, or nil if there is no such key.
+func nextPrefix(prefix []byte) []byte {
+	next := bytes.Clone(prefix)
+	for i := len(next) - 1; i >= 0; i-- {
+		if next[i] < 0xff {
+			next[i]++
+			return next[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// lookupValue returns the indexed value that the events matched by an equality filter have, or false if the filter
+// cannot be answered from a property index.
+func lookupValue(filter repository.Filter) ([]byte, bool, error) {
+	if filter.Operator != repository.OperatorEqual {
+		return nil, false, nil
+	}
+
+	switch filter.Property {
+	case repository.PropertyTxHash:
+		txHash, err := hex.DecodeString(filter.Value)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: invalid tx hash", repository.ErrInvalidFilter)
+		}
+
+		return txHash, true, nil
+	case repository.PropertyPrincipal:
+		return []byte(filter.Value), true, nil
+	case repository.PropertyEventType:
+		eventType, err := strconv.Atoi(filter.Value)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: invalid event type ID", repository.ErrInvalidFilter)
+		}
+
+		return []byte(strconv.Itoa(eventType)), true, nil
+	case repository.ProviderName, repository.PropertyChannel:
+		return []byte(strings.ToLower(filter.Value)), true, nil
+	case repository.PropertyProviderGuid, repository.PropertyCorrelation:
+		guid, err := uuid.Parse(filter.Value)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: invalid GUID", repository.ErrInvalidFilter)
+		}
+
+		return guid[:], true, nil
+	default:
+		return nil, false, nil
+	}
+}
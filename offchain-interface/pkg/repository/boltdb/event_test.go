@@ -0,0 +1,256 @@
+package boltdb
+
+import (
+	"context"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type EventTestSuite struct {
+	BoltSuite
+	testData []events.StoredEvent
+	now      time.Time
+}
+
+func TestEventSuite(t *testing.T) {
+	suite.Run(t, new(EventTestSuite))
+}
+
+// SetupTest stores 30 events, one minute apart, alternating between principals and channels.
+func (suite *EventTestSuite) SetupTest() {
+	suite.BoltSuite.SetupTest()
+
+	suite.now = time.Now().Truncate(time.Minute)
+	suite.testData = nil
+
+	principals := []string{"p1", "p2", "p3"}
+	for i := 0; i < 30; i++ {
+		channel := ChannelSecurity
+		if i%2 == 1 {
+			channel = ChannelSystem
+		}
+
+		eventTime := suite.now.Add(-time.Minute * time.Duration(i))
+		suite.testData = append(suite.testData, generateStoredEvent(i, eventTime, principals[i%3], channel, 4624+i%2, nil))
+	}
+
+	// Store oldest first, in the order the events would be received
+	for i := len(suite.testData) - 1; i >= 0; i-- {
+		suite.store(suite.testData[i : i+1])
+	}
+}
+
+func (suite *EventTestSuite) search(filters []repository.Filter, limit, page int, first *events.EventHash) []events.EventHash {
+	results, err := suite.repository.Events().SearchEvents(context.Background(), filters, limit, page, first)
+	suite.Require().NoError(err)
+
+	return hashes(results)
+}
+
+func (suite *EventTestSuite) TestStoreDuplicate() {
+	err := suite.repository.Events().Store(context.Background(), suite.testData[0])
+	suite.Require().ErrorIs(err, repository.ErrEventAlreadyStored)
+
+	count, err := suite.repository.Events().EventCount(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(len(suite.testData), count)
+}
+
+func (suite *EventTestSuite) TestGetEvent() {
+	expected := suite.testData[3]
+
+	event, found, err := suite.repository.Events().GetEventById(context.Background(), expected.Metadata.EventId)
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+	suite.Require().Equal(expected.Metadata.EventId, event.Metadata.EventId)
+	suite.Require().Equal(expected.EventWithData, event.EventWithData)
+	suite.Require().True(expected.Metadata.ReceivedTime.Equal(event.Metadata.ReceivedTime))
+
+	event, found, err = suite.repository.Events().GetEventByTx(context.Background(), expected.TxHash)
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+	suite.Require().Equal(expected.Metadata.EventId, event.Metadata.EventId)
+
+	_, found, err = suite.repository.Events().GetEventById(context.Background(), generateHash(1000))
+	suite.Require().NoError(err)
+	suite.Require().False(found)
+
+	stored, err := suite.repository.Events().GetEventsById(context.Background(), []events.EventHash{
+		suite.testData[0].Metadata.EventId,
+		generateHash(1000),
+		suite.testData[1].Metadata.EventId,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(hashes(suite.testData[:2]), hashes(stored))
+}
+
+func (suite *EventTestSuite) TestSearchNewestFirst() {
+	suite.Require().Equal(hashes(suite.testData[:10]), suite.search(nil, 10, 0, nil))
+	suite.Require().Equal(hashes(suite.testData[10:20]), suite.search(nil, 10, 1, nil))
+
+	// Events stored after the first event of the first page are excluded from later pages
+	first := suite.testData[0].Metadata.EventId
+	suite.store([]events.StoredEvent{generateStoredEvent(100, suite.now.Add(time.Minute), "p1", ChannelSecurity, 1, nil)})
+	suite.Require().Equal(hashes(suite.testData[10:20]), suite.search(nil, 10, 1, &first))
+	suite.Require().Len(suite.search(nil, 10, 0, nil), 10)
+	suite.Require().NotEqual(hashes(suite.testData[:10]), suite.search(nil, 10, 0, nil))
+}
+
+func (suite *EventTestSuite) TestSearchIndexedProperties() {
+	var expected []events.StoredEvent
+	for i, event := range suite.testData {
+		if i%3 == 0 && i%2 == 1 {
+			expected = append(expected, event)
+		}
+	}
+
+	// Channels are matched case-insensitively
+	filters := []repository.Filter{
+		{Property: repository.PropertyPrincipal, Operator: repository.OperatorEqual, Value: "p1"},
+		{Property: repository.PropertyChannel, Operator: repository.OperatorEqual, Value: "SYSTEM"},
+	}
+	suite.Require().Equal(hashes(expected), suite.search(filters, 100, 0, nil))
+
+	filters = []repository.Filter{
+		{Property: repository.PropertyEventType, Operator: repository.OperatorEqual, Value: "4625"},
+		{Property: repository.PropertyPrincipal, Operator: repository.OperatorEqual, Value: "p1"},
+	}
+	suite.Require().Equal(hashes(expected), suite.search(filters, 100, 0, nil))
+
+	filters = []repository.Filter{
+		{Property: repository.PropertyTxHash, Operator: repository.OperatorEqual, Value: suite.testData[7].TxHash.String()},
+	}
+	suite.Require().Equal(hashes(suite.testData[7:8]), suite.search(filters, 100, 0, nil))
+
+	filters = []repository.Filter{
+		{Property: repository.PropertyEventId, Operator: repository.OperatorEqual, Value: suite.testData[8].Metadata.EventId.String()},
+	}
+	suite.Require().Equal(hashes(suite.testData[8:9]), suite.search(filters, 100, 0, nil))
+
+	filters = []repository.Filter{
+		{Property: repository.ProviderName, Operator: repository.OperatorEqual, Value: "test-provider"},
+	}
+	suite.Require().Equal(hashes(suite.testData[:5]), suite.search(filters, 5, 0, nil))
+}
+
+func (suite *EventTestSuite) TestSearchGuids() {
+	provider := events.NewGuid(uuid.New())
+
+	event := generateStoredEvent(100, suite.now.Add(-time.Hour*24), "p1", ChannelSecurity, 1, provider)
+	event.EventWithData.System.Correlation.ActivityId = events.NewGuid(uuid.New())
+	suite.store([]events.StoredEvent{event})
+
+	filters := []repository.Filter{
+		{Property: repository.PropertyProviderGuid, Operator: repository.OperatorEqual, Value: provider.String()},
+	}
+	suite.Require().Equal(hashes([]events.StoredEvent{event}), suite.search(filters, 10, 0, nil))
+
+	filters = []repository.Filter{
+		{Property: repository.PropertyCorrelation, Operator: repository.OperatorEqual, Value: event.EventWithData.System.Correlation.ActivityId.String()},
+	}
+	suite.Require().Equal(hashes([]events.StoredEvent{event}), suite.search(filters, 10, 0, nil))
+}
+
+func (suite *EventTestSuite) TestSearchTimestamp() {
+	format := func(t time.Time) string {
+		return t.Format(utils.HtmlDateTimeFormat)
+	}
+
+	// Bounds are exclusive
+	filters := []repository.Filter{
+		{Property: repository.PropertyTimestamp, Operator: repository.OperatorBefore, Value: format(suite.now.Add(-time.Minute * 5))},
+		{Property: repository.PropertyTimestamp, Operator: repository.OperatorAfter, Value: format(suite.now.Add(-time.Minute * 10))},
+	}
+	suite.Require().Equal(hashes(suite.testData[6:10]), suite.search(filters, 100, 0, nil))
+
+	// Timestamp bounds also apply when another index is used
+	filters = append(filters, repository.Filter{
+		Property: repository.PropertyChannel, Operator: repository.OperatorEqual, Value: ChannelSecurity,
+	})
+	suite.Require().Equal(hashes([]events.StoredEvent{suite.testData[6], suite.testData[8]}), suite.search(filters, 100, 0, nil))
+}
+
+func (suite *EventTestSuite) TestSearchInvalidFilter() {
+	filters := []repository.Filter{
+		{Property: repository.PropertyChannel, Operator: repository.OperatorAfter, Value: ChannelSecurity},
+	}
+
+	_, err := suite.repository.Events().SearchEvents(context.Background(), filters, 10, 0, nil)
+	suite.Require().ErrorIs(err, repository.ErrInvalidFilter)
+}
+
+func (suite *EventTestSuite) TestRedact() {
+	redaction := events.Redaction{
+		EventId: suite.testData[0].Metadata.EventId,
+		Reason:  "erasure request",
+		Author:  "admin",
+		Height:  10,
+	}
+	suite.Require().NoError(suite.repository.Events().Redact(context.Background(), redaction))
+
+	event, found, err := suite.repository.Events().GetEventById(context.Background(), redaction.EventId)
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+	suite.Require().Empty(event.EventWithData.EventData)
+	suite.Require().Equal(&redaction, event.Redaction)
+
+	// The redacted event remains searchable by its indexed properties
+	filters := []repository.Filter{
+		{Property: repository.PropertyTxHash, Operator: repository.OperatorEqual, Value: suite.testData[0].TxHash.String()},
+	}
+	suite.Require().Equal(hashes(suite.testData[:1]), suite.search(filters, 10, 0, nil))
+}
+
+func (suite *EventTestSuite) TestRedactBeforeStore() {
+	event := generateStoredEvent(100, suite.now, "p1", ChannelSecurity, 1, nil)
+
+	redaction := events.Redaction{EventId: event.Metadata.EventId, Reason: "erasure request", Author: "admin", Height: 10}
+	suite.Require().NoError(suite.repository.Events().Redact(context.Background(), redaction))
+
+	// The placeholder prevents the event data from being backfilled
+	err := suite.repository.Events().Store(context.Background(), event)
+	suite.Require().ErrorIs(err, repository.ErrEventAlreadyStored)
+
+	stored, found, err := suite.repository.Events().GetEventById(context.Background(), event.Metadata.EventId)
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+	suite.Require().Empty(stored.EventWithData.EventData)
+	suite.Require().Equal(&redaction, stored.Redaction)
+
+	// Placeholders have no received time, and are ordered after every other event
+	results := suite.search(nil, 100, 0, nil)
+	suite.Require().Len(results, len(suite.testData)+1)
+	suite.Require().Equal(event.Metadata.EventId, results[len(results)-1])
+}
+
+func (suite *EventTestSuite) TestChallenges() {
+	ctx := context.Background()
+	challenges := suite.repository.Challenges()
+
+	suite.Require().NoError(challenges.AddChallenge(ctx, "p1", []byte("challenge")))
+
+	valid, err := challenges.GetAndRemoveChallenge(ctx, "p2", []byte("challenge"), time.Minute)
+	suite.Require().NoError(err)
+	suite.Require().False(valid)
+
+	valid, err = challenges.GetAndRemoveChallenge(ctx, "p1", []byte("challenge"), time.Minute)
+	suite.Require().NoError(err)
+	suite.Require().True(valid)
+
+	// Challenges may only be used once
+	valid, err = challenges.GetAndRemoveChallenge(ctx, "p1", []byte("challenge"), time.Minute)
+	suite.Require().NoError(err)
+	suite.Require().False(valid)
+
+	suite.Require().NoError(challenges.AddChallenge(ctx, "p1", []byte("expired")))
+	suite.Require().NoError(challenges.DropExpiredChallenges(ctx, 0))
+
+	valid, err = challenges.GetAndRemoveChallenge(ctx, "p1", []byte("expired"), time.Hour)
+	suite.Require().NoError(err)
+	suite.Require().False(valid)
+}
@@ -0,0 +1,139 @@
+package boltdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Events are stored in bucketEvents keyed by sequence number, which is assigned in the order that events are stored.
+// bucketEventIds maps event IDs to sequence numbers.
+//
+// The time index and each property index are keyed by the indexed value, followed by the received time and sequence
+// number of the event, so that the events with a given value are iterated newest first by iterating in reverse.
+var (
+	bucketEvents     = []byte("events")
+	bucketEventIds   = []byte("event_ids")
+	bucketTimeIndex  = []byte("index_timestamp")
+	bucketChallenges = []byte("challenges")
+)
+
+// indexedProperties are the filter properties with an index of their own. Event IDs are indexed by bucketEventIds,
+// and timestamps by bucketTimeIndex.
+var indexedProperties = []repository.FilterProperty{
+	repository.PropertyTxHash,
+	repository.PropertyPrincipal,
+	repository.PropertyEventType,
+	repository.ProviderName,
+	repository.PropertyProviderGuid,
+	repository.PropertyCorrelation,
+	repository.PropertyChannel,
+}
+
+func indexBucket(property repository.FilterProperty) []byte {
+	return []byte("index_" + property)
+}
+
+func allBuckets() [][]byte {
+	buckets := [][]byte{bucketEvents, bucketEventIds, bucketTimeIndex, bucketChallenges}
+	for _, property := range indexedProperties {
+		buckets = append(buckets, indexBucket(property))
+	}
+
+	return buckets
+}
+
+func seqKey(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seq)
+}
+
+// timeKey encodes the time so that keys sort chronologically. Placeholders for redacted events that have not been
+// stored have no time, and sort before every other event.
+func timeKey(t time.Time) []byte {
+	if t.IsZero() {
+		return make([]byte, 8)
+	}
+
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano())^(1<<63))
+}
+
+// valuePrefix returns the prefix shared by the index keys of the given value. Values are length-prefixed, so that no
+// value's prefix is the prefix of another value.
+func valuePrefix(value []byte) []byte {
+	prefix := binary.BigEndian.AppendUint16(nil, uint16(len(value)))
+	return append(prefix, value...)
+}
+
+// indexKey returns the key of the event in an index, which ends with its sequence number.
+func indexKey(prefix []byte, event events.StoredEvent, seq uint64) []byte {
+	key := bytes.Clone(prefix)
+	key = append(key, timeKey(event.Metadata.ReceivedTime)...)
+	return append(key, seqKey(seq)...)
+}
+
+// trailingSeq returns the sequence number at the end of an index key, or stored in bucketEventIds.
+func trailingSeq(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key[len(key)-8:])
+}
+
+// indexedValue returns the value of the property of the event, as indexed, or false if the event has no value for the
+// property. Values are normalised in the same way as by repository.Filter, so that an index lookup returns a superset of
+// the events matched by a filter.
+func indexedValue(property repository.FilterProperty, event events.StoredEvent) ([]byte, bool) {
+	system := event.EventWithData.System
+	switch property {
+	case repository.PropertyTxHash:
+		return event.TxHash, len(event.TxHash) > 0
+	case repository.PropertyPrincipal:
+		return []byte(event.Metadata.Principal), event.Metadata.Principal != ""
+	case repository.PropertyEventType:
+		return []byte(strconv.Itoa(int(system.EventId))), !isPlaceholder(event)
+	case repository.ProviderName:
+		if system.Provider.Name == nil {
+			return nil, false
+		}
+
+		return []byte(strings.ToLower(*system.Provider.Name)), true
+	case repository.PropertyProviderGuid:
+		if system.Provider.Guid == nil {
+			return nil, false
+		}
+
+		return system.Provider.Guid[:], true
+	case repository.PropertyCorrelation:
+		if system.Correlation.ActivityId == nil {
+			return nil, false
+		}
+
+		return system.Correlation.ActivityId[:], true
+	case repository.PropertyChannel:
+		return []byte(strings.ToLower(system.Channel)), system.Channel != ""
+	default:
+		return nil, false
+	}
+}
+
+// isPlaceholder returns true if the event is a placeholder, stored in place of a redacted event that has not been
+// received, which carries only the event ID and the redaction.
+func isPlaceholder(event events.StoredEvent) bool {
+	return event.Redaction != nil && event.Metadata.ReceivedTime.IsZero()
+}
+
+// indexKeys returns the keys of the event in each index, keyed by bucket name.
+func indexKeys(event events.StoredEvent, seq uint64) map[string][]byte {
+	keys := map[string][]byte{
+		string(bucketTimeIndex): indexKey(nil, event, seq),
+	}
+
+	for _, property := range indexedProperties {
+		if value, ok := indexedValue(property, event); ok {
+			keys[string(indexBucket(property))] = indexKey(valuePrefix(value), event, seq)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,174 @@
+package boltdb
+
+import (
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPolicyType   = errors.New("invalid policy type in context")
+	ErrInvalidRuleGrouping = errors.New("invalid rule grouping")
+)
+
+// retentionPlan selects the events outside of a retention policy, with the same semantics as the aggregation pipeline
+// built by the MongoDB repository.
+type retentionPlan struct {
+	filters []timestampFilter
+	// volume is the number of events retained by the count-based filter, either in total or per principal, or 0 if
+	// the policy has no count-based filter.
+	volume      uint64
+	byPrincipal bool
+}
+
+// timestampFilter retains the events that it matches if they were received at or after the cutoff. Events that it
+// does not match are unaffected by it.
+type timestampFilter struct {
+	channel  *string
+	eventId  *events.EventId
+	provider *uuid.UUID
+	cutoff   time.Time
+}
+
+func buildRetentionPlan(policy types.RetentionPolicy, now time.Time) (retentionPlan, error) {
+	if err := policy.Validate(); err != nil {
+		return retentionPlan{}, errors.Wrap(err, "policy validation failed")
+	}
+
+	var plan retentionPlan
+	for _, filter := range policy.Filters {
+		if !isGlobal(filter) {
+			continue
+		}
+
+		// Infallible, since only timestamp policies are supported for global filters
+		if filter.PolicyAction.Type != types.PolicyTypeTimestamp {
+			return retentionPlan{}, errors.Wrapf(ErrInvalidPolicyType, "policy type: %s", filter.PolicyAction.Type)
+		}
+
+		compiled := timestampFilter{
+			channel: filter.Match.Channel,
+			eventId: filter.Match.EventId,
+			cutoff:  now.Add(-filter.PolicyAction.RetentionPeriod.Duration()),
+		}
+
+		if filter.Match.ProviderGuid != nil {
+			provider, err := uuid.Parse(*filter.Match.ProviderGuid)
+			if err != nil {
+				return retentionPlan{}, fmt.Errorf("invalid provider GUID %s: %w", *filter.Match.ProviderGuid, err)
+			}
+
+			compiled.provider = &provider
+		}
+
+		plan.filters = append(plan.filters, compiled)
+	}
+
+	countFilter := getCountFilter(policy)
+	if countFilter == nil {
+		return plan, nil
+	}
+
+	switch countFilter.PolicyAction.RuleGroup {
+	case types.RuleGroupingGlobal, "":
+	case types.RuleGroupingPrincipal:
+		plan.byPrincipal = true
+	default:
+		return retentionPlan{}, errors.Wrapf(ErrInvalidRuleGrouping, "rule grouping: %s", countFilter.PolicyAction.RuleGroup)
+	}
+
+	plan.volume = countFilter.PolicyAction.Volume
+	return plan, nil
+}
+
+// matches returns true if the event satisfies every criterion of the filter. As in the other repositories, channels are
+// compared case-insensitively.
+func (f timestampFilter) matches(event events.StoredEvent) bool {
+	system := event.EventWithData.System
+	if f.channel != nil && !strings.EqualFold(*f.channel, system.Channel) {
+		return false
+	}
+
+	if f.eventId != nil && (isPlaceholder(event) || *f.eventId != system.EventId) {
+		return false
+	}
+
+	if f.provider != nil && (system.Provider.Guid == nil || *f.provider != system.Provider.Guid.UUID()) {
+		return false
+	}
+
+	return true
+}
+
+// selects returns true if the event is outside of every timestamp filter. Placeholders for redacted events have no
+// received time, and so are never expired by a filter that matches them.
+func (p retentionPlan) selects(event events.StoredEvent) bool {
+	for _, filter := range p.filters {
+		if !filter.matches(event) {
+			continue
+		}
+
+		if isPlaceholder(event) || !event.Metadata.ReceivedTime.Before(filter.cutoff) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expiredEvents returns the events outside of the retention policy, newest first.
+func (p retentionPlan) expiredEvents(tx *bolt.Tx) ([]events.StoredEvent, error) {
+	var expired []events.StoredEvent
+	retained := make(map[identity.Principal]uint64)
+
+	err := scanReverse(tx.Bucket(bucketTimeIndex), nil, nil, nil, func(seq uint64) (bool, error) {
+		event, err := loadEvent(tx, seq)
+		if err != nil {
+			return false, err
+		}
+
+		if !p.selects(event) {
+			return true, nil
+		}
+
+		// Without a count-based filter, every selected event is expired. Otherwise, the newest events up to the volume
+		// are retained.
+		if p.volume > 0 {
+			var group identity.Principal
+			if p.byPrincipal {
+				group = event.Metadata.Principal
+			}
+
+			if retained[group] < p.volume {
+				retained[group]++
+				return true, nil
+			}
+		}
+
+		expired = append(expired, event)
+		return true, nil
+	})
+
+	return expired, err
+}
+
+func isGlobal(f types.Filter) bool {
+	return f.PolicyAction.Type == types.PolicyTypeTimestamp &&
+		(f.PolicyAction.RuleGroup == types.RuleGroupingGlobal || f.PolicyAction.RuleGroup == "")
+}
+
+func getCountFilter(p types.RetentionPolicy) *types.Filter {
+	for _, filter := range p.Filters {
+		if filter.PolicyAction.Type == types.PolicyTypeCount {
+			return &filter
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,455 @@
+package boltdb
+
+import (
+	"context"
+	"github.com/RyanW02/wineventchain/common/pkg/types"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository/repositorytest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type RetentionTestSuite struct {
+	BoltSuite
+}
+
+func TestRetentionSuite(t *testing.T) {
+	suite.Run(t, new(RetentionTestSuite))
+}
+
+// requireDropped stores the events, applies the policy, and asserts that exactly the expected events were dropped.
+func (suite *RetentionTestSuite) requireDropped(policy offchain.RetentionPolicy, testData []events.StoredEvent, expected []events.EventHash) {
+	suite.store(testData)
+	suite.Require().NoError(suite.repository.Events().DropExpiredEvents(context.Background(), policy, nil))
+
+	remaining := make(map[string]bool)
+	for _, eventId := range suite.remaining(testData) {
+		remaining[eventId.String()] = true
+	}
+
+	var dropped []events.EventHash
+	for _, event := range testData {
+		if !remaining[event.Metadata.EventId.String()] {
+			dropped = append(dropped, event.Metadata.EventId)
+		}
+	}
+
+	suite.Require().ElementsMatch(expected, dropped)
+}
+
+func (suite *RetentionTestSuite) TestRetainTimestamp() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		eventTime := time.Now()
+		if i < 30 {
+			eventTime = eventTime.Add(-24 * time.Hour)
+		}
+
+		testData = append(testData, generateStoredEvent(i, eventTime, "principal", ChannelSecurity, i, nil))
+	}
+
+	suite.requireDropped(policy, testData, hashes(testData[:30]))
+}
+
+func (suite *RetentionTestSuite) TestRetainVolume() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:      offchain.PolicyTypeCount,
+					RuleGroup: offchain.RuleGroupingGlobal,
+					Volume:    50,
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		testData = append(testData, generateStoredEvent(i, time.Now().Add(-time.Minute*time.Duration(i)), "principal", ChannelSecurity, i, nil))
+	}
+
+	// testData goes newest -> oldest, so last 50 items should be removed
+	suite.requireDropped(policy, testData, hashes(testData[50:]))
+}
+
+func (suite *RetentionTestSuite) TestRetainVolumeAndTimestamp() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:      offchain.PolicyTypeCount,
+					RuleGroup: offchain.RuleGroupingGlobal,
+					Volume:    50,
+				},
+			},
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 30),
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		eventTime := time.Now()
+		if i < 70 {
+			eventTime = eventTime.Add(-time.Hour*24*60 - time.Minute*time.Duration(i))
+		}
+
+		testData = append(testData, generateStoredEvent(i, eventTime, "principal", ChannelSecurity, i, nil))
+	}
+
+	// Last 30 are time.Now(), 0 -> 70 are newest -> oldest. Policy has 50 overflow capacity
+	suite.requireDropped(policy, testData, hashes(testData[50:70]))
+}
+
+func (suite *RetentionTestSuite) TestRetainVolumeGrouped() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:      offchain.PolicyTypeCount,
+					RuleGroup: offchain.RuleGroupingPrincipal,
+					Volume:    3, // 3 events per principal
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	principals := []string{"p1", "p2", "p3"}
+	for i := 0; i < 99; i++ {
+		testData = append(testData, generateStoredEvent(i, time.Now().Add(-time.Minute*time.Duration(i)), principals[i%3], ChannelSecurity, i, nil))
+	}
+
+	suite.requireDropped(policy, testData, hashes(testData[9:]))
+}
+
+func (suite *RetentionTestSuite) TestRetainChannelTimestamp() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				Match: offchain.Match{
+					Channel: utils.Ptr("SyStEm"), // Test case insensitivity
+				},
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 90),
+				},
+			},
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 30),
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		channel := ChannelSecurity
+		if i < 50 {
+			channel = ChannelSystem
+		}
+
+		testData = append(testData, generateStoredEvent(i, time.Now().Add(-time.Hour*24*60), "principal", channel, i, nil))
+	}
+
+	// Delete events with "Security" channel
+	suite.requireDropped(policy, testData, hashes(testData[50:]))
+}
+
+func (suite *RetentionTestSuite) TestRetainChannelMatches() {
+	for i, tc := range repositorytest.ChannelMatches {
+		policy := offchain.RetentionPolicy{
+			Filters: []offchain.Filter{
+				{
+					Match: offchain.Match{
+						Channel: utils.Ptr(tc.Filter),
+					},
+					PolicyAction: offchain.PolicyAction{
+						Type:            offchain.PolicyTypeTimestamp,
+						RuleGroup:       offchain.RuleGroupingGlobal,
+						RetentionPeriod: types.MarshalledDuration(time.Hour),
+					},
+				},
+			},
+		}
+
+		// Events are retained only while within a filter that matches them
+		event := generateStoredEvent(i, time.Now().Add(-time.Minute), "principal", tc.Event, i, nil)
+		suite.store([]events.StoredEvent{event})
+
+		suite.Require().NoError(suite.repository.Events().DropExpiredEvents(context.Background(), policy, nil))
+
+		_, found, err := suite.repository.Events().GetEventById(context.Background(), event.Metadata.EventId)
+		suite.Require().NoError(err)
+		suite.Require().Equal(tc.Matches, found, tc.Name)
+	}
+}
+
+func (suite *RetentionTestSuite) TestRetainProviderTimestamp() {
+	provider := events.NewGuid(uuid.New())
+
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				Match: offchain.Match{
+					ProviderGuid: utils.Ptr(provider.String()),
+				},
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 90),
+				},
+			},
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 30),
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		var eventProvider *events.Guid
+		if i >= 70 {
+			eventProvider = provider
+		}
+
+		testData = append(testData, generateStoredEvent(i, time.Now().Add(-time.Hour*24*60), "p", ChannelSecurity, i, eventProvider))
+	}
+
+	suite.requireDropped(policy, testData, hashes(testData[:70]))
+}
+
+func (suite *RetentionTestSuite) TestRetainMatchMultipleTimestamp() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				Match: offchain.Match{
+					Channel: utils.Ptr(ChannelSecurity),
+					EventId: utils.Ptr(events.EventId(12345)),
+				},
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 90),
+				},
+			},
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 30),
+				},
+			},
+		},
+	}
+
+	eventTime := time.Now().Add(-time.Hour * 24 * 60)
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		testData = append(testData, generateStoredEvent(i, eventTime, "p", ChannelSecurity, 12345, nil))
+	}
+
+	for i := 100; i < 130; i++ {
+		testData = append(testData, generateStoredEvent(i, eventTime, "p", ChannelSecurity, 1, nil))
+	}
+
+	for i := 130; i < 160; i++ {
+		testData = append(testData, generateStoredEvent(i, eventTime, "p", ChannelSystem, 12345, nil))
+	}
+
+	suite.requireDropped(policy, testData, hashes(testData[100:]))
+}
+
+func (suite *RetentionTestSuite) TestRetainChannelVolume() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				Match: offchain.Match{
+					Channel: utils.Ptr("SyStEm"), // Test case insensitivity
+				},
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour * 24 * 90),
+				},
+			},
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:      offchain.PolicyTypeCount,
+					RuleGroup: offchain.RuleGroupingGlobal,
+					Volume:    20,
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		channel := ChannelSecurity
+		if i < 50 {
+			channel = ChannelSystem
+		}
+
+		eventTime := time.Now().Add(-time.Hour*24*60 - time.Minute*time.Duration(i))
+		testData = append(testData, generateStoredEvent(i, eventTime, "principal", channel, i, nil))
+	}
+
+	// Delete 30 oldest events with "Security" channel - "System" channel is excluded, and there is capacity
+	// for 20 more non-matching events.
+	suite.requireDropped(policy, testData, hashes(testData[70:]))
+}
+
+func (suite *RetentionTestSuite) TestRedactionPlaceholdersRetained() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RetentionPeriod: types.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	placeholder := events.Redaction{EventId: generateHash(1000), Reason: "erasure request", Author: "admin", Height: 1}
+	suite.Require().NoError(suite.repository.Events().Redact(context.Background(), placeholder))
+
+	testData := []events.StoredEvent{generateStoredEvent(0, time.Now().Add(-time.Hour*2), "p", ChannelSecurity, 1, nil)}
+	suite.requireDropped(policy, testData, hashes(testData))
+
+	_, found, err := suite.repository.Events().GetEventById(context.Background(), placeholder.EventId)
+	suite.Require().NoError(err)
+	suite.Require().True(found)
+}
+
+func (suite *RetentionTestSuite) TestLegalHoldProtectsEvents() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 30 expired events, 10 of which belong to a principal under investigation
+	var testData []events.StoredEvent
+	for i := 0; i < 100; i++ {
+		eventTime := time.Now()
+		if i < 30 {
+			eventTime = eventTime.Add(-24 * time.Hour)
+		}
+
+		principal := "principal"
+		if i < 10 {
+			principal = "suspect"
+		}
+
+		testData = append(testData, generateStoredEvent(i, eventTime, principal, ChannelSecurity, i, nil))
+	}
+
+	suite.store(testData)
+
+	r := suite.repository.Events()
+
+	hold := offchain.LegalHold{
+		Id:       1,
+		Criteria: offchain.HoldCriteria{Principals: []identity.Principal{"suspect"}},
+	}
+
+	held, err := r.CountHeldEvents(ctx, hold)
+	suite.Require().NoError(err)
+	suite.Require().Equal(10, held)
+
+	suite.Require().NoError(r.DropExpiredEvents(ctx, policy, offchain.LegalHolds{hold}))
+
+	count, err := r.EventCount(ctx)
+	suite.Require().NoError(err)
+	suite.Require().Equal(80, count)
+
+	held, err = r.CountHeldEvents(ctx, hold)
+	suite.Require().NoError(err)
+	suite.Require().Equal(10, held)
+}
+
+func (suite *RetentionTestSuite) TestLegalHoldCaseInsensitive() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	var testData []events.StoredEvent
+	for i := 0; i < 30; i++ {
+		principal, channel := "principal", ChannelSecurity
+		if i < 10 {
+			principal = "suspect"
+		} else if i < 20 {
+			channel = ChannelSystem
+		}
+
+		testData = append(testData, generateStoredEvent(i, time.Now().Add(-24*time.Hour), principal, channel, i, nil))
+	}
+
+	suite.store(testData)
+
+	// Holds match principals and channels case-insensitively, as in the other repositories
+	holds := offchain.LegalHolds{
+		{Id: 1, Criteria: offchain.HoldCriteria{Principals: []identity.Principal{"SuSpEcT"}}},
+		{Id: 2, Criteria: offchain.HoldCriteria{Channels: []string{"SYSTEM"}}},
+	}
+
+	suite.Require().NoError(suite.repository.Events().DropExpiredEvents(context.Background(), policy, holds))
+	suite.Require().ElementsMatch(hashes(testData[:20]), suite.remaining(testData))
+}
+
+func hashes(testData []events.StoredEvent) []events.EventHash {
+	ids := make([]events.EventHash, len(testData))
+	for i, event := range testData {
+		ids[i] = event.Metadata.EventId
+	}
+
+	return ids
+}
@@ -0,0 +1,95 @@
+package boltdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ChannelSecurity = "security"
+	ChannelSystem   = "system"
+)
+
+// BoltSuite runs each test against a new database file.
+type BoltSuite struct {
+	suite.Suite
+	repository *BoltRepository
+}
+
+func (suite *BoltSuite) SetupTest() {
+	repository, err := Open(zap.NewNop(), filepath.Join(suite.T().TempDir(), "events.db"))
+	suite.Require().NoError(err)
+	suite.Require().NoError(repository.InitSchema())
+
+	suite.repository = repository
+}
+
+func (suite *BoltSuite) TearDownTest() {
+	suite.Require().NoError(suite.repository.Close())
+}
+
+func (suite *BoltSuite) store(testData []events.StoredEvent) {
+	for _, event := range testData {
+		suite.Require().NoError(suite.repository.Events().Store(context.Background(), event))
+	}
+}
+
+// remaining returns the IDs of the events that are stored.
+func (suite *BoltSuite) remaining(testData []events.StoredEvent) []events.EventHash {
+	var remaining []events.EventHash
+	for _, event := range testData {
+		_, found, err := suite.repository.Events().GetEventById(context.Background(), event.Metadata.EventId)
+		suite.Require().NoError(err)
+
+		if found {
+			remaining = append(remaining, event.Metadata.EventId)
+		}
+	}
+
+	return remaining
+}
+
+func generateStoredEvent(i int, eventTime time.Time, principal string, channel string, eventId int, provider *events.Guid) events.StoredEvent {
+	return events.StoredEvent{
+		EventWithData: events.EventWithData{
+			Event: events.Event{
+				System: events.System{
+					Provider: events.Provider{
+						Name: utils.Ptr("Test-Provider"),
+						Guid: provider,
+					},
+					EventId: events.EventId(eventId),
+					Channel: channel,
+				},
+			},
+			EventData: events.EventData{
+				{
+					Name:  utils.Ptr("data-point"),
+					Value: utils.Ptr("data-value"),
+				},
+			},
+		},
+		Metadata: events.Metadata{
+			EventId:      generateHash(i),
+			ReceivedTime: eventTime,
+			Principal:    identity.Principal(principal),
+		},
+		TxHash: generateHash(i),
+	}
+}
+
+func generateHash(i int) events.TxHash {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, uint64(i))
+
+	digest := sha256.Sum256(data)
+	return digest[:]
+}
@@ -183,11 +183,30 @@ func (m *MongoEventRepository) Store(ctx context.Context, event events.StoredEve
 	return nil
 }
 
+func (m *MongoEventRepository) CountHeldEvents(ctx context.Context, hold offchain.LegalHold) (int, error) {
+	opts := options.Count().SetCollation(collationCaseInsensitive)
+	count, err := m.collection.CountDocuments(ctx, buildHoldFilter(hold.Criteria), opts)
+	return int(count), err
+}
+
+func (m *MongoEventRepository) Redact(ctx context.Context, redaction events.Redaction) error {
+	filter := bson.D{{"metadata.event_id", redaction.EventId}}
+	update := bson.M{
+		"$set": bson.M{
+			"event.event_data": events.EventData{},
+			"redaction":        redaction,
+		},
+	}
+
+	_, err := m.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
 type policyScanResult struct {
 	Events []events.EventHash `bson:"events"`
 }
 
-func (m *MongoEventRepository) DropExpiredEvents(ctx context.Context, policy offchain.RetentionPolicy) error {
+func (m *MongoEventRepository) DropExpiredEvents(ctx context.Context, policy offchain.RetentionPolicy, holds offchain.LegalHolds) error {
 	if err := policy.Validate(); err != nil {
 		return err
 	}
@@ -201,8 +220,9 @@ func (m *MongoEventRepository) DropExpiredEvents(ctx context.Context, policy off
 	m.logger.Info("Fetching events outside of retention policy to drop")
 	m.logger.Debug("Built aggregation pipeline", zap.Any("pipeline", aggregate))
 
-	// Execute the aggregation pipeline to get a list of event IDs to delete
-	cursor, err := m.collection.Aggregate(ctx, aggregate)
+	// Execute the aggregation pipeline to get a list of event IDs to delete. The collation makes the pipeline compare
+	// channels case-insensitively, as in the other repositories.
+	cursor, err := m.collection.Aggregate(ctx, aggregate, options.Aggregate().SetCollation(collationCaseInsensitive))
 	if err != nil {
 		return err
 	}
@@ -220,9 +240,9 @@ func (m *MongoEventRepository) DropExpiredEvents(ctx context.Context, policy off
 
 	m.logger.Info("Found events outside of retention policy", zap.Int("count", len(results[0].Events)))
 
-	// Delete the events
-	filter := bson.M{"metadata.event_id": bson.M{"$in": results[0].Events}}
-	res, err := m.collection.DeleteMany(ctx, filter)
+	// Delete the events, except for those protected by a legal hold
+	filter := excludeHeld(bson.M{"metadata.event_id": bson.M{"$in": results[0].Events}}, holds)
+	res, err := m.collection.DeleteMany(ctx, filter, options.Delete().SetCollation(collationCaseInsensitive))
 	if err != nil {
 		return err
 	}
@@ -231,6 +251,7 @@ func (m *MongoEventRepository) DropExpiredEvents(ctx context.Context, policy off
 		"Deleted events outside of retention policy",
 		zap.Int64("deleted_count", res.DeletedCount),
 		zap.Int("expected_count", len(results[0].Events)),
+		zap.Int("hold_count", len(holds)),
 	)
 
 	return nil
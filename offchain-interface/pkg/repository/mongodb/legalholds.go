@@ -0,0 +1,55 @@
+package mongodb
+
+import (
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// buildHoldFilter builds a filter matching the events protected by a legal hold. Every criterion that is set must be
+// satisfied, and list criteria are satisfied by any of their entries.
+func buildHoldFilter(criteria types.HoldCriteria) bson.M {
+	filter := bson.M{}
+
+	if len(criteria.Principals) > 0 {
+		filter[KeyPrincipal] = bson.M{"$in": criteria.Principals}
+	}
+
+	if len(criteria.Channels) > 0 {
+		filter[KeyChannel] = bson.M{"$in": criteria.Channels}
+	}
+
+	if len(criteria.EventIds) > 0 {
+		filter[KeyEventId] = bson.M{"$in": criteria.EventIds}
+	}
+
+	if criteria.From != nil || criteria.To != nil {
+		timestamp := bson.M{}
+		if criteria.From != nil {
+			timestamp["$gte"] = primitive.NewDateTimeFromTime(*criteria.From)
+		}
+
+		if criteria.To != nil {
+			timestamp["$lte"] = primitive.NewDateTimeFromTime(*criteria.To)
+		}
+
+		filter[KeyTimestamp] = timestamp
+	}
+
+	return filter
+}
+
+// excludeHeld adds a clause to the filter that excludes events protected by any of the given legal holds.
+func excludeHeld(filter bson.M, holds types.LegalHolds) bson.M {
+	if len(holds) == 0 {
+		return filter
+	}
+
+	held := make(bson.A, len(holds))
+	for i, hold := range holds {
+		held[i] = buildHoldFilter(hold.Criteria)
+	}
+
+	filter["$nor"] = held
+	return filter
+}
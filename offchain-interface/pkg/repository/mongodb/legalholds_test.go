@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func TestBuildHoldFilter(t *testing.T) {
+	from := time.Unix(100, 0)
+
+	filter := buildHoldFilter(types.HoldCriteria{
+		Principals: []identity.Principal{"agent"},
+		Channels:   []string{"Security"},
+		EventIds:   []events.EventHash{{0x01}},
+		From:       &from,
+	})
+
+	require.Equal(t, bson.M{
+		"metadata.principal":         bson.M{"$in": []identity.Principal{"agent"}},
+		"event.event.system.channel": bson.M{"$in": []string{"Security"}},
+		"metadata.event_id":          bson.M{"$in": []events.EventHash{{0x01}}},
+		"metadata.received_time":     bson.M{"$gte": primitive.NewDateTimeFromTime(from)},
+	}, filter)
+}
+
+func TestExcludeHeld(t *testing.T) {
+	filter := bson.M{"metadata.event_id": bson.M{"$in": []events.EventHash{{0x01}}}}
+	require.Equal(t, filter, excludeHeld(filter, nil))
+
+	holds := types.LegalHolds{
+		{Id: 1, Criteria: types.HoldCriteria{Channels: []string{"Security"}}},
+		{Id: 2, Criteria: types.HoldCriteria{Principals: []identity.Principal{"agent"}}},
+	}
+
+	require.Equal(t, bson.M{
+		"metadata.event_id": bson.M{"$in": []events.EventHash{{0x01}}},
+		"$nor": bson.A{
+			bson.M{"event.event.system.channel": bson.M{"$in": []string{"Security"}}},
+			bson.M{"metadata.principal": bson.M{"$in": []identity.Principal{"agent"}}},
+		},
+	}, excludeHeld(filter, holds))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
 	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository/repositorytest"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -721,6 +722,110 @@ func (suite *RetentionTestSuite) TestRetainAdvancedVolumeGroupedAndMatch() {
 	requireHashes(suite.T(), cursor, expectedHashes)
 }
 
+func (suite *RetentionTestSuite) TestRetainChannelMatches() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger, err := zap.NewDevelopment()
+	suite.Require().NoErrorf(err, "Could not create logger")
+
+	r := &MongoEventRepository{
+		logger:     logger,
+		collection: suite.Collection(),
+	}
+
+	for i, tc := range repositorytest.ChannelMatches {
+		policy := offchain.RetentionPolicy{
+			Filters: []offchain.Filter{
+				{
+					Match: offchain.Match{
+						Channel: utils.Ptr(tc.Filter),
+					},
+					PolicyAction: offchain.PolicyAction{
+						Type:            offchain.PolicyTypeTimestamp,
+						RuleGroup:       offchain.RuleGroupingGlobal,
+						RetentionPeriod: types.MarshalledDuration(time.Hour),
+					},
+				},
+			},
+		}
+
+		// Events are retained only while within a filter that matches them
+		event := generateStoredEvent(suite.T(), i, time.Now().Add(-time.Minute), "principal", tc.Event, i, nil)
+		_, err := suite.Collection().InsertOne(ctx, event)
+		suite.Require().NoErrorf(err, "Could not insert test data: %s", err)
+
+		suite.Require().NoError(r.DropExpiredEvents(ctx, policy, nil))
+
+		_, found, err := r.GetEventById(ctx, event.Metadata.EventId)
+		suite.Require().NoError(err)
+		suite.Require().Equal(tc.Matches, found, tc.Name)
+	}
+}
+
+func (suite *RetentionTestSuite) TestLegalHoldProtectsEvents() {
+	policy := offchain.RetentionPolicy{
+		Filters: []offchain.Filter{
+			{
+				PolicyAction: offchain.PolicyAction{
+					Type:            offchain.PolicyTypeTimestamp,
+					RuleGroup:       offchain.RuleGroupingGlobal,
+					RetentionPeriod: types.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 30 expired events, 10 of which belong to a principal under investigation
+	var testData []any
+	for i := 0; i < 100; i++ {
+		eventTime := time.Now()
+		if i < 30 {
+			eventTime = eventTime.Add(-24 * time.Hour)
+		}
+
+		principal := "principal"
+		if i < 10 {
+			principal = "suspect"
+		}
+
+		testData = append(testData, generateStoredEvent(suite.T(), i, eventTime, principal, ChannelSecurity, i, nil))
+	}
+
+	_, err := suite.Collection().InsertMany(ctx, testData)
+	suite.Require().NoErrorf(err, "Could not insert test data: %s", err)
+
+	logger, err := zap.NewDevelopment()
+	suite.Require().NoErrorf(err, "Could not create logger")
+
+	r := &MongoEventRepository{
+		logger:     logger,
+		collection: suite.Collection(),
+	}
+
+	hold := offchain.LegalHold{
+		Id:       1,
+		Criteria: offchain.HoldCriteria{Principals: []identity.Principal{"suspect"}},
+	}
+
+	held, err := r.CountHeldEvents(ctx, hold)
+	suite.Require().NoError(err)
+	suite.Require().Equal(10, held)
+
+	suite.Require().NoError(r.DropExpiredEvents(ctx, policy, offchain.LegalHolds{hold}))
+
+	count, err := r.EventCount(ctx)
+	suite.Require().NoError(err)
+	suite.Require().Equal(80, count)
+
+	held, err = r.CountHeldEvents(ctx, hold)
+	suite.Require().NoError(err)
+	suite.Require().Equal(10, held)
+}
+
 func generateStoredEvent(t *testing.T, i int, eventTime time.Time, principal string, channel string, eventId int, provider *events.Guid) events.StoredEvent {
 	t.Helper()
 
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+type PostgresChallengeRepository struct {
+	logger *zap.Logger
+	db     *sql.DB
+}
+
+func NewPostgresChallengeRepository(logger *zap.Logger, db *sql.DB) *PostgresChallengeRepository {
+	return &PostgresChallengeRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+var _ repository.ChallengeRepository = (*PostgresChallengeRepository)(nil)
+
+func (p *PostgresChallengeRepository) AddChallenge(ctx context.Context, principal identity.Principal, challenge []byte) error {
+	_, err := p.db.ExecContext(
+		ctx,
+		"INSERT INTO challenges (principal, challenge, created_at) VALUES ($1, $2, $3)",
+		principal.String(), challenge, time.Now(),
+	)
+	return err
+}
+
+func (p *PostgresChallengeRepository) GetAndRemoveChallenge(
+	ctx context.Context,
+	principal identity.Principal,
+	challenge []byte,
+	challengeLifetime time.Duration,
+) (bool, error) {
+	var createdAt time.Time
+	err := p.db.QueryRowContext(ctx, `DELETE FROM challenges WHERE id = (
+		SELECT id FROM challenges WHERE principal = $1 AND challenge = $2 LIMIT 1
+	) RETURNING created_at`, principal.String(), challenge).Scan(&createdAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		} else {
+			return false, err
+		}
+	}
+
+	isValid := createdAt.After(time.Now().Add(-challengeLifetime))
+	return isValid, nil
+}
+
+func (p *PostgresChallengeRepository) DropExpiredChallenges(ctx context.Context, challengeLifetime time.Duration) error {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM challenges WHERE created_at < $1", time.Now().Add(-challengeLifetime))
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		p.logger.Debug("no expired challenges to remove")
+		return nil
+	}
+
+	p.logger.Info("removed expired challenges", zap.Int64("count", count))
+	return nil
+}
@@ -0,0 +1,423 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// codeUniqueViolation is the SQLSTATE returned when an insert violates a unique constraint.
+const codeUniqueViolation = "23505"
+
+const eventColumns = "event_id, tx_hash, principal, received_time, event, redaction"
+
+type PostgresEventRepository struct {
+	logger *zap.Logger
+	db     *sql.DB
+}
+
+// Compile-time type validation
+var _ repository.EventRepository = (*PostgresEventRepository)(nil)
+
+func NewPostgresEventRepository(logger *zap.Logger, db *sql.DB) *PostgresEventRepository {
+	return &PostgresEventRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row rowScanner) (events.StoredEvent, error) {
+	var (
+		eventId, txHash []byte
+		principal       sql.NullString
+		receivedTime    sql.NullTime
+		data, redaction []byte
+	)
+
+	if err := row.Scan(&eventId, &txHash, &principal, &receivedTime, &data, &redaction); err != nil {
+		return events.StoredEvent{}, err
+	}
+
+	event := events.StoredEvent{
+		Metadata: events.Metadata{
+			EventId:      eventId,
+			ReceivedTime: receivedTime.Time.UTC(),
+			Principal:    identity.Principal(principal.String),
+		},
+		TxHash: txHash,
+	}
+
+	if err := json.Unmarshal(data, &event.EventWithData); err != nil {
+		return events.StoredEvent{}, err
+	}
+
+	if redaction != nil {
+		if err := json.Unmarshal(redaction, &event.Redaction); err != nil {
+			return events.StoredEvent{}, err
+		}
+	}
+
+	return event, nil
+}
+
+func scanEvents(rows *sql.Rows) ([]events.StoredEvent, error) {
+	defer rows.Close()
+
+	var stored []events.StoredEvent
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		stored = append(stored, event)
+	}
+
+	return stored, rows.Err()
+}
+
+func (p *PostgresEventRepository) GetEventById(ctx context.Context, id events.EventHash) (events.StoredEvent, bool, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+eventColumns+" FROM events WHERE event_id = $1", []byte(id))
+
+	event, err := scanEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return events.StoredEvent{}, false, nil
+		} else {
+			return events.StoredEvent{}, false, err
+		}
+	}
+
+	return event, true, nil
+}
+
+func (p *PostgresEventRepository) GetEventsById(ctx context.Context, ids []events.EventHash) ([]events.StoredEvent, error) {
+	eventIds := make(pq.ByteaArray, len(ids))
+	for i, id := range ids {
+		eventIds[i] = id
+	}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT "+eventColumns+" FROM events WHERE event_id = ANY($1)", eventIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+func (p *PostgresEventRepository) GetEventByTx(ctx context.Context, txHash []byte) (events.StoredEvent, bool, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+eventColumns+" FROM events WHERE tx_hash = $1 LIMIT 1", txHash)
+
+	event, err := scanEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return events.StoredEvent{}, false, nil
+		} else {
+			return events.StoredEvent{}, false, err
+		}
+	}
+
+	return event, true, nil
+}
+
+func (p *PostgresEventRepository) SearchEvents(ctx context.Context, filters []repository.Filter, limit, page int, first *events.EventHash) ([]events.StoredEvent, error) {
+	// Lookup position of [first] event
+	var firstSeq *int64
+	if first != nil {
+		var seq int64
+		if err := p.db.QueryRowContext(ctx, "SELECT seq FROM events WHERE event_id = $1", []byte(*first)).Scan(&seq); err != nil {
+			// If the event is not found, return the first page of results
+			if !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+		} else {
+			firstSeq = &seq
+		}
+	}
+
+	q, err := buildFilter(filters, firstSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := fmt.Sprintf(
+		"SELECT %s FROM events WHERE %s ORDER BY %s LIMIT %s OFFSET %s",
+		eventColumns, q.clause(), orderNewestFirst, q.arg(limit), q.arg(page*limit),
+	)
+
+	rows, err := p.db.QueryContext(ctx, statement, q.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+func (p *PostgresEventRepository) EventCount(ctx context.Context) (int, error) {
+	var count int
+	err := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events").Scan(&count)
+	return count, err
+}
+
+func (p *PostgresEventRepository) Store(ctx context.Context, event events.StoredEvent) error {
+	data, err := json.Marshal(event.EventWithData)
+	if err != nil {
+		return err
+	}
+
+	var redaction []byte
+	if event.Redaction != nil {
+		if redaction, err = json.Marshal(event.Redaction); err != nil {
+			return err
+		}
+	}
+
+	system := event.EventWithData.System
+	_, err = p.db.ExecContext(ctx, `INSERT INTO events (
+		event_id, tx_hash, principal, received_time, channel, event_type_id, provider_name, provider_guid, correlation_id,
+		event, redaction
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		[]byte(event.Metadata.EventId),
+		[]byte(event.TxHash),
+		event.Metadata.Principal.String(),
+		event.Metadata.ReceivedTime,
+		system.Channel,
+		int(system.EventId),
+		system.Provider.Name,
+		guidValue(system.Provider.Guid),
+		guidValue(system.Correlation.ActivityId),
+		data,
+		redaction,
+	)
+	if err != nil {
+		// Check for duplicate key error
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == codeUniqueViolation {
+			return repository.ErrEventAlreadyStored
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresEventRepository) CountHeldEvents(ctx context.Context, hold offchain.LegalHold) (int, error) {
+	var q query
+	q.where(buildHoldCondition(&q, hold.Criteria))
+
+	var count int
+	err := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events WHERE "+q.clause(), q.args...).Scan(&count)
+	return count, err
+}
+
+func (p *PostgresEventRepository) Redact(ctx context.Context, redaction events.Redaction) error {
+	encoded, err := json.Marshal(redaction)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, `INSERT INTO events (event_id, event, redaction)
+		VALUES ($1, JSONB_BUILD_OBJECT('event_data', '[]'::JSONB), $2)
+		ON CONFLICT (event_id) DO UPDATE SET
+			event = JSONB_SET(events.event, '{event_data}', '[]'::JSONB),
+			redaction = EXCLUDED.redaction`,
+		[]byte(redaction.EventId),
+		encoded,
+	)
+	return err
+}
+
+func (p *PostgresEventRepository) DropExpiredEvents(ctx context.Context, policy offchain.RetentionPolicy, holds offchain.LegalHolds) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	statement, args, err := buildRetentionQuery(policy, time.Now())
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("Fetching events outside of retention policy to drop")
+	p.logger.Debug("Built retention query", zap.String("query", statement))
+
+	rows, err := p.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var eventIds pq.ByteaArray
+	for rows.Next() {
+		var eventId []byte
+		if err := rows.Scan(&eventId); err != nil {
+			return err
+		}
+
+		eventIds = append(eventIds, eventId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(eventIds) == 0 {
+		p.logger.Info("No events to drop")
+		return nil
+	}
+
+	p.logger.Info("Found events outside of retention policy", zap.Int("count", len(eventIds)))
+
+	// Delete the events, except for those protected by a legal hold
+	var q query
+	q.where(ColumnEventId + " = ANY(" + q.arg(eventIds) + ")")
+	excludeHeld(&q, holds)
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM events WHERE "+q.clause(), q.args...)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info(
+		"Deleted events outside of retention policy",
+		zap.Int64("deleted_count", deleted),
+		zap.Int("expected_count", len(eventIds)),
+		zap.Int("hold_count", len(holds)),
+	)
+
+	return nil
+}
+
+// buildFilter builds the conditions of an event search. As with the MongoDB repository, text is compared
+// case-insensitively.
+func buildFilter(filters []repository.Filter, first *int64) (query, error) {
+	var q query
+
+	if first != nil {
+		q.where("seq <= " + q.arg(*first))
+	}
+
+	for _, f := range filters {
+		switch f.Property {
+		case repository.PropertyEventId:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for event ID", repository.ErrInvalidFilter)
+			}
+
+			eventId, err := hex.DecodeString(f.Value)
+			if err != nil {
+				return q, fmt.Errorf("%w: invalid event ID", repository.ErrInvalidFilter)
+			}
+
+			q.where(ColumnEventId + " = " + q.arg(eventId))
+		case repository.PropertyTxHash:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for tx hash", repository.ErrInvalidFilter)
+			}
+
+			txHash, err := hex.DecodeString(f.Value)
+			if err != nil {
+				return q, fmt.Errorf("%w: invalid tx hash", repository.ErrInvalidFilter)
+			}
+
+			q.where("tx_hash = " + q.arg(txHash))
+		case repository.PropertyPrincipal:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for principal", repository.ErrInvalidFilter)
+			}
+
+			q.where("LOWER(" + ColumnPrincipal + ") = LOWER(" + q.arg(f.Value) + ")")
+		case repository.PropertyEventType:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for event type ID", repository.ErrInvalidFilter)
+			}
+
+			eventId, err := strconv.Atoi(f.Value)
+			if err != nil {
+				return q, fmt.Errorf("%w: invalid event type ID", repository.ErrInvalidFilter)
+			}
+
+			q.where(ColumnEventTypeId + " = " + q.arg(eventId))
+		case repository.PropertyTimestamp:
+			timestamp, err := time.Parse(utils.HtmlDateTimeFormat, f.Value)
+			if err != nil {
+				return q, fmt.Errorf("%w: invalid timestamp", repository.ErrInvalidFilter)
+			}
+
+			if f.Operator == repository.OperatorBefore {
+				q.where(ColumnTimestamp + " < " + q.arg(timestamp))
+			} else if f.Operator == repository.OperatorAfter {
+				q.where(ColumnTimestamp + " > " + q.arg(timestamp))
+			} else {
+				return q, fmt.Errorf("%w: invalid operator for timestamp", repository.ErrInvalidFilter)
+			}
+		case repository.ProviderName:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for provider", repository.ErrInvalidFilter)
+			}
+
+			q.where("LOWER(provider_name) = LOWER(" + q.arg(f.Value) + ")")
+		case repository.PropertyProviderGuid:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for provider", repository.ErrInvalidFilter)
+			}
+
+			uuid, err := uuid.Parse(f.Value)
+			if err != nil {
+				return q, fmt.Errorf("%w: invalid provider GUID", repository.ErrInvalidFilter)
+			}
+
+			q.where(ColumnProvider + " = " + q.arg(uuid.String()))
+		case repository.PropertyCorrelation:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for correlation", repository.ErrInvalidFilter)
+			}
+
+			uuid, err := uuid.Parse(f.Value)
+			if err != nil {
+				return q, fmt.Errorf("%w: invalid correlation GUID", repository.ErrInvalidFilter)
+			}
+
+			q.where("correlation_id = " + q.arg(uuid.String()))
+		case repository.PropertyChannel:
+			if f.Operator != repository.OperatorEqual {
+				return q, fmt.Errorf("%w: invalid operator for channel", repository.ErrInvalidFilter)
+			}
+
+			q.where("LOWER(" + ColumnChannel + ") = LOWER(" + q.arg(f.Value) + ")")
+		}
+	}
+
+	return q, nil
+}
+
+// guidValue converts an optional GUID to a value that can be stored in a nullable UUID column.
+func guidValue(guid *events.Guid) any {
+	if guid == nil {
+		return nil
+	}
+
+	return guid.String()
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// buildHoldCondition builds a condition matching the events protected by a legal hold. Every criterion that is set must
+// be satisfied, and list criteria are satisfied by any of their entries. As with the MongoDB repository, principals and
+// channels are matched case-insensitively.
+func buildHoldCondition(q *query, criteria types.HoldCriteria) string {
+	// Arguments are added to q, in which the condition is embedded
+	var hold query
+	if len(criteria.Principals) > 0 {
+		principals := make(pq.StringArray, len(criteria.Principals))
+		for i, principal := range criteria.Principals {
+			principals[i] = strings.ToLower(principal.String())
+		}
+
+		hold.where("LOWER(" + ColumnPrincipal + ") = ANY(" + q.arg(principals) + ")")
+	}
+
+	if len(criteria.Channels) > 0 {
+		channels := make(pq.StringArray, len(criteria.Channels))
+		for i, channel := range criteria.Channels {
+			channels[i] = strings.ToLower(channel)
+		}
+
+		hold.where("LOWER(" + ColumnChannel + ") = ANY(" + q.arg(channels) + ")")
+	}
+
+	if len(criteria.EventIds) > 0 {
+		eventIds := make(pq.ByteaArray, len(criteria.EventIds))
+		for i, eventId := range criteria.EventIds {
+			eventIds[i] = eventId
+		}
+
+		hold.where(ColumnEventId + " = ANY(" + q.arg(eventIds) + ")")
+	}
+
+	if criteria.From != nil {
+		hold.where(ColumnTimestamp + " >= " + q.arg(*criteria.From))
+	}
+
+	if criteria.To != nil {
+		hold.where(ColumnTimestamp + " <= " + q.arg(*criteria.To))
+	}
+
+	return hold.clause()
+}
+
+// excludeHeld adds a condition to the query that excludes events protected by any of the given legal holds.
+// Placeholders for redacted events lack the columns that criteria match on, and are never held.
+func excludeHeld(q *query, holds types.LegalHolds) {
+	for _, hold := range holds {
+		q.where("NOT COALESCE(" + buildHoldCondition(q, hold.Criteria) + ", FALSE)")
+	}
+}
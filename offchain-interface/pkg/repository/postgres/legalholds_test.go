@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBuildHoldCondition(t *testing.T) {
+	from := time.Unix(100, 0)
+
+	var q query
+	condition := buildHoldCondition(&q, types.HoldCriteria{
+		Principals: []identity.Principal{"Agent"},
+		Channels:   []string{"Security"},
+		EventIds:   []events.EventHash{{0x01}},
+		From:       &from,
+	})
+
+	require.Equal(t,
+		"(LOWER(principal) = ANY($1)) AND (LOWER(channel) = ANY($2)) AND (event_id = ANY($3)) AND (received_time >= $4)",
+		condition,
+	)
+	require.Equal(t, []any{
+		pq.StringArray{"agent"},
+		pq.StringArray{"security"},
+		pq.ByteaArray{{0x01}},
+		from,
+	}, q.args)
+}
+
+func TestExcludeHeld(t *testing.T) {
+	var q query
+	q.where("event_id = ANY(" + q.arg(pq.ByteaArray{{0x01}}) + ")")
+
+	excludeHeld(&q, nil)
+	require.Equal(t, "(event_id = ANY($1))", q.clause())
+
+	holds := types.LegalHolds{
+		{Id: 1, Criteria: types.HoldCriteria{Channels: []string{"Security"}}},
+		{Id: 2, Criteria: types.HoldCriteria{Principals: []identity.Principal{"agent"}}},
+	}
+
+	excludeHeld(&q, holds)
+	require.Equal(t,
+		"(event_id = ANY($1)) AND (NOT COALESCE((LOWER(channel) = ANY($2)), FALSE)) AND (NOT COALESCE((LOWER(principal) = ANY($3)), FALSE))",
+		q.clause(),
+	)
+	require.Len(t, q.args, 3)
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"go.uber.org/zap"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migrations are named <version>_<description>.sql, and are applied in version order. Applied migrations must never be
+// modified: changes to the schema are made by adding a new migration.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	version    int
+	name       string
+	statements string
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+
+		statements, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{
+			version:    version,
+			name:       entry.Name(),
+			statements: string(statements),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// InitSchema applies the migrations that have not yet been applied to the database, in a single transaction.
+func (p *PostgresRepository) InitSchema(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.version <= current {
+			continue
+		}
+
+		p.logger.Info("Applying migration", zap.String("name", migration.name))
+
+		if _, err := tx.ExecContext(ctx, migration.statements); err != nil {
+			return fmt.Errorf("error applying migration %s: %w", migration.name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", migration.version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,24 @@
+-- Placeholders for redacted events that have not been stored yet only carry the event ID, event and redaction, so the
+-- remaining columns are nullable
+CREATE TABLE events
+(
+    seq            BIGSERIAL PRIMARY KEY,
+    event_id       BYTEA NOT NULL UNIQUE,
+    tx_hash        BYTEA,
+    principal      TEXT,
+    received_time  TIMESTAMPTZ,
+    channel        TEXT,
+    event_type_id  INTEGER,
+    provider_name  TEXT,
+    provider_guid  UUID,
+    correlation_id UUID,
+    event          JSONB NOT NULL,
+    redaction      JSONB
+);
+
+CREATE INDEX events_tx_hash_idx ON events (tx_hash);
+CREATE INDEX events_received_time_idx ON events (received_time DESC);
+CREATE INDEX events_principal_idx ON events (LOWER(principal));
+CREATE INDEX events_channel_idx ON events (LOWER(channel));
+CREATE INDEX events_event_type_id_idx ON events (event_type_id);
+CREATE INDEX events_provider_guid_idx ON events (provider_guid);
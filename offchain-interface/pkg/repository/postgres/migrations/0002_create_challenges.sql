@@ -0,0 +1,9 @@
+CREATE TABLE challenges
+(
+    id         BIGSERIAL PRIMARY KEY,
+    principal  TEXT        NOT NULL,
+    challenge  BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX challenges_principal_idx ON challenges (principal);
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository"
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+	"time"
+)
+
+type PostgresRepository struct {
+	logger     *zap.Logger
+	db         *sql.DB
+	events     *PostgresEventRepository
+	challenges *PostgresChallengeRepository
+}
+
+var _ repository.Repository = (*PostgresRepository)(nil)
+
+func NewPostgresRepository(logger *zap.Logger, db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		logger:     logger,
+		db:         db,
+		events:     NewPostgresEventRepository(logger, db),
+		challenges: NewPostgresChallengeRepository(logger, db),
+	}
+}
+
+func (p *PostgresRepository) Events() repository.EventRepository {
+	return p.events
+}
+
+func (p *PostgresRepository) Challenges() repository.ChallengeRepository {
+	return p.challenges
+}
+
+func (p *PostgresRepository) TestConnection() error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancelFunc()
+
+	return p.db.PingContext(ctx)
+}
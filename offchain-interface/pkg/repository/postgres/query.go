@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// query accumulates the conditions of a WHERE clause, and the arguments that they reference by position.
+type query struct {
+	conditions []string
+	args       []any
+}
+
+// arg adds an argument to the query, returning the placeholder that references it.
+func (q *query) arg(value any) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *query) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// clause returns the conjunction of the conditions, which is true if there are none.
+func (q *query) clause() string {
+	if len(q.conditions) == 0 {
+		return "TRUE"
+	}
+
+	return "(" + strings.Join(q.conditions, ") AND (") + ")"
+}
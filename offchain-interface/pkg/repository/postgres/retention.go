@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"fmt"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	ColumnEventId   = "event_id"
+	ColumnTimestamp = "received_time"
+	ColumnPrincipal = "principal"
+
+	ColumnChannel     = "channel"
+	ColumnEventTypeId = "event_type_id"
+	ColumnProvider    = "provider_guid"
+)
+
+var (
+	ErrInvalidPolicyType   = errors.New("invalid policy type in context")
+	ErrInvalidRuleGrouping = errors.New("invalid rule grouping")
+)
+
+// Newest events first. Placeholders for redacted events have no timestamp, and are ordered last.
+const orderNewestFirst = ColumnTimestamp + " DESC NULLS LAST, seq DESC"
+
+// buildRetentionQuery builds a query selecting the IDs of the events outside of the retention policy, as of now. It is
+// equivalent to the aggregation pipeline built by the MongoDB repository.
+func buildRetentionQuery(policy types.RetentionPolicy, now time.Time) (string, []any, error) {
+	if err := policy.Validate(); err != nil {
+		return "", nil, errors.Wrap(err, "policy validation failed")
+	}
+
+	// Apply global filters
+	var q query
+	for _, filter := range policy.Filters {
+		if !isGlobal(filter) {
+			continue
+		}
+
+		// Infallible, since only timestamp policies are supported for global filters
+		if filter.PolicyAction.Type != types.PolicyTypeTimestamp {
+			return "", nil, errors.Wrapf(ErrInvalidPolicyType, "policy type: %s", filter.PolicyAction.Type)
+		}
+
+		// Events not matched by the filter are unaffected by it, while those matched must have expired
+		var unmatched []string
+		expired := []string{ColumnTimestamp + " < " + q.arg(now.Add(-filter.PolicyAction.RetentionPeriod.Duration()))}
+
+		// Channels are compared case-insensitively, as in the other repositories
+		if filter.Match.Channel != nil {
+			channel := q.arg(strings.ToLower(*filter.Match.Channel))
+			unmatched = append(unmatched, "LOWER("+ColumnChannel+") IS DISTINCT FROM "+channel)
+			expired = append(expired, "LOWER("+ColumnChannel+") = "+channel)
+		}
+
+		if filter.Match.EventId != nil {
+			eventId := q.arg(int(*filter.Match.EventId))
+			unmatched = append(unmatched, ColumnEventTypeId+" IS DISTINCT FROM "+eventId)
+			expired = append(expired, ColumnEventTypeId+" = "+eventId)
+		}
+
+		if filter.Match.ProviderGuid != nil {
+			parsed, err := uuid.Parse(*filter.Match.ProviderGuid)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid provider GUID %s: %w", *filter.Match.ProviderGuid, err)
+			}
+
+			guid := q.arg(parsed.String())
+			unmatched = append(unmatched, ColumnProvider+" IS DISTINCT FROM "+guid)
+			expired = append(expired, ColumnProvider+" = "+guid)
+		}
+
+		q.where(strings.Join(append(unmatched, "("+strings.Join(expired, " AND ")+")"), " OR "))
+	}
+
+	// Ranking only needed for count-based policies
+	countFilter := getCountFilter(policy)
+	if countFilter == nil {
+		return "SELECT " + ColumnEventId + " FROM events WHERE " + q.clause(), q.args, nil
+	}
+
+	// Global volume limit
+	if countFilter.PolicyAction.RuleGroup == types.RuleGroupingGlobal || countFilter.PolicyAction.RuleGroup == "" {
+		statement := fmt.Sprintf(
+			"SELECT %s FROM events WHERE %s ORDER BY %s OFFSET %s",
+			ColumnEventId, q.clause(), orderNewestFirst, q.arg(countFilter.PolicyAction.Volume),
+		)
+
+		return statement, q.args, nil
+	} else if countFilter.PolicyAction.RuleGroup == types.RuleGroupingPrincipal { // Keep last N events per principal
+		statement := fmt.Sprintf(
+			"SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS position FROM events WHERE %s) AS ranked WHERE position > %s",
+			ColumnEventId, ColumnEventId, ColumnPrincipal, orderNewestFirst, q.clause(), q.arg(countFilter.PolicyAction.Volume),
+		)
+
+		return statement, q.args, nil
+	} else {
+		return "", nil, errors.Wrapf(ErrInvalidRuleGrouping, "rule grouping: %s", countFilter.PolicyAction.RuleGroup)
+	}
+}
+
+func isGlobal(f types.Filter) bool {
+	return f.PolicyAction.Type == types.PolicyTypeTimestamp &&
+		(f.PolicyAction.RuleGroup == types.RuleGroupingGlobal || f.PolicyAction.RuleGroup == "")
+}
+
+func getCountFilter(p types.RetentionPolicy) *types.Filter {
+	for _, filter := range p.Filters {
+		if filter.PolicyAction.Type == types.PolicyTypeCount {
+			return &filter
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	types2 "github.com/RyanW02/wineventchain/common/pkg/types"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	"github.com/RyanW02/wineventchain/common/pkg/utils"
+	"github.com/RyanW02/wineventchain/offchain-interface/pkg/repository/repositorytest"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildPolicyGlobalVolume(t *testing.T) {
+	policy := types.RetentionPolicy{
+		Filters: []types.Filter{
+			{
+				PolicyAction: types.PolicyAction{
+					Type:      types.PolicyTypeCount,
+					RuleGroup: types.RuleGroupingGlobal,
+					Volume:    1000,
+				},
+			},
+		},
+	}
+
+	statement, args, err := buildRetentionQuery(policy, time.Now())
+	require.NoError(t, err)
+
+	require.Equal(t, "SELECT event_id FROM events WHERE TRUE ORDER BY received_time DESC NULLS LAST, seq DESC OFFSET $1", statement)
+	require.Equal(t, []any{uint64(1000)}, args)
+}
+
+func TestBuildPolicyPrincipalVolume(t *testing.T) {
+	policy := types.RetentionPolicy{
+		Filters: []types.Filter{
+			{
+				PolicyAction: types.PolicyAction{
+					Type:      types.PolicyTypeCount,
+					RuleGroup: types.RuleGroupingPrincipal,
+					Volume:    1000,
+				},
+			},
+		},
+	}
+
+	statement, args, err := buildRetentionQuery(policy, time.Now())
+	require.NoError(t, err)
+
+	require.Equal(t, "SELECT event_id FROM (SELECT event_id, ROW_NUMBER() OVER (PARTITION BY principal ORDER BY received_time DESC NULLS LAST, seq DESC) AS position FROM events WHERE TRUE) AS ranked WHERE position > $1", statement)
+	require.Equal(t, []any{uint64(1000)}, args)
+}
+
+func TestBuildPolicyGlobalTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	channel := "Security"
+	eventId := events.EventId(4624)
+
+	policy := types.RetentionPolicy{
+		Filters: []types.Filter{
+			{
+				PolicyAction: types.PolicyAction{
+					Type:            types.PolicyTypeTimestamp,
+					RetentionPeriod: types2.MarshalledDuration(time.Hour * 24 * 3),
+				},
+			},
+			{
+				Match: types.Match{
+					Channel: &channel,
+					EventId: &eventId,
+				},
+				PolicyAction: types.PolicyAction{
+					Type:            types.PolicyTypeTimestamp,
+					RetentionPeriod: types2.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	statement, args, err := buildRetentionQuery(policy, now)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"SELECT event_id FROM events WHERE ((received_time < $1)) AND "+
+			"(LOWER(channel) IS DISTINCT FROM $3 OR event_type_id IS DISTINCT FROM $4 OR (received_time < $2 AND LOWER(channel) = $3 AND event_type_id = $4))",
+		statement,
+	)
+	require.Equal(t, []any{now.Add(-time.Hour * 24 * 3), now.Add(-time.Hour), "security", 4624}, args)
+}
+
+func TestBuildPolicyChannelMatches(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	for _, tc := range repositorytest.ChannelMatches {
+		t.Run(tc.Name, func(t *testing.T) {
+			policy := types.RetentionPolicy{
+				Filters: []types.Filter{
+					{
+						Match: types.Match{
+							Channel: utils.Ptr(tc.Filter),
+						},
+						PolicyAction: types.PolicyAction{
+							Type:            types.PolicyTypeTimestamp,
+							RetentionPeriod: types2.MarshalledDuration(time.Hour),
+						},
+					},
+				},
+			}
+
+			statement, args, err := buildRetentionQuery(policy, now)
+			require.NoError(t, err)
+
+			require.Equal(t,
+				"SELECT event_id FROM events WHERE (LOWER(channel) IS DISTINCT FROM $2 OR (received_time < $1 AND LOWER(channel) = $2))",
+				statement,
+			)
+
+			// The lowered channel column is compared with the argument, so the filter matches the event exactly when
+			// the argument equals the event's lowered channel
+			require.Len(t, args, 2)
+			require.Equal(t, tc.Matches, args[1] == strings.ToLower(tc.Event))
+		})
+	}
+}
+
+func TestBuildPolicyTimestampAndVolume(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	policy := types.RetentionPolicy{
+		Filters: []types.Filter{
+			{
+				PolicyAction: types.PolicyAction{
+					Type:            types.PolicyTypeTimestamp,
+					RetentionPeriod: types2.MarshalledDuration(time.Hour),
+				},
+			},
+			{
+				PolicyAction: types.PolicyAction{
+					Type:      types.PolicyTypeCount,
+					RuleGroup: types.RuleGroupingGlobal,
+					Volume:    10,
+				},
+			},
+		},
+	}
+
+	statement, args, err := buildRetentionQuery(policy, now)
+	require.NoError(t, err)
+
+	require.Equal(t, "SELECT event_id FROM events WHERE ((received_time < $1)) ORDER BY received_time DESC NULLS LAST, seq DESC OFFSET $2", statement)
+	require.Equal(t, []any{now.Add(-time.Hour), uint64(10)}, args)
+}
+
+func TestBuildPolicyInvalidProviderGuid(t *testing.T) {
+	provider := "not-a-guid"
+	policy := types.RetentionPolicy{
+		Filters: []types.Filter{
+			{
+				Match: types.Match{ProviderGuid: &provider},
+				PolicyAction: types.PolicyAction{
+					Type:            types.PolicyTypeTimestamp,
+					RetentionPeriod: types2.MarshalledDuration(time.Hour),
+				},
+			},
+		},
+	}
+
+	_, _, err := buildRetentionQuery(policy, time.Now())
+	require.Error(t, err)
+}
@@ -21,7 +21,14 @@ type EventRepository interface {
 	SearchEvents(ctx context.Context, filters []Filter, limit, page int, first *events.EventHash) ([]events.StoredEvent, error)
 	EventCount(ctx context.Context) (int, error)
 	Store(ctx context.Context, event events.StoredEvent) error
-	DropExpiredEvents(ctx context.Context, policy types.RetentionPolicy) error
+	// DropExpiredEvents deletes the events outside of the retention policy, except for those protected by any of the
+	// given legal holds.
+	DropExpiredEvents(ctx context.Context, policy types.RetentionPolicy, holds types.LegalHolds) error
+	// CountHeldEvents returns the number of stored events protected by the legal hold.
+	CountHeldEvents(ctx context.Context, hold types.LegalHold) (int, error)
+	// Redact erases the data of the event targeted by the redaction order, and records the order against the event. If
+	// the event has not been stored yet, a placeholder is stored in its place, so that the data is not later backfilled.
+	Redact(ctx context.Context, redaction events.Redaction) error
 }
 
 // ChallengeRepository is used to store challenges for authenticating with the viewer server.
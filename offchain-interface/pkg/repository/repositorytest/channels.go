@@ -0,0 +1,23 @@
+// Package repositorytest contains test cases shared by the repository implementations, so that each backend is held
+// to the same matching rules.
+package repositorytest
+
+// ChannelMatch is a case in which a retention policy filter names the channel Filter, and an event was logged to the
+// channel Event. The filter must match the event if and only if Matches is true.
+type ChannelMatch struct {
+	Name    string
+	Filter  string
+	Event   string
+	Matches bool
+}
+
+// ChannelMatches are the cases that every repository must satisfy. Channels are compared case-insensitively.
+var ChannelMatches = []ChannelMatch{
+	{Name: "exact", Filter: "Security", Event: "Security", Matches: true},
+	{Name: "lower case filter", Filter: "security", Event: "Security", Matches: true},
+	{Name: "upper case event", Filter: "Security", Event: "SECURITY", Matches: true},
+	{Name: "mixed case", Filter: "sEcUrItY", Event: "SeCuRiTy", Matches: true},
+	{Name: "different channel", Filter: "Security", Event: "System", Matches: false},
+	{Name: "prefix", Filter: "Sec", Event: "Security", Matches: false},
+	{Name: "suffix", Filter: "Security", Event: "Security/Operational", Matches: false},
+}
@@ -56,31 +56,46 @@ func (a *Agent) StartLoop(shutdownCh chan chan error) {
 }
 
 func (a *Agent) scanAndDrop() error {
-	// If we do not have the policy, fetch it from the blockchain. It is possible that a policy has not been set yet.
-	// Keep attempting to fetch the policy until it is set. Once one is found, there is no need to keep fetching it,
-	// as the retention policy is immutable.
-	if a.policy == nil {
-		policy, err := a.blockchainClient.GetRetentionPolicy()
-		if err != nil {
-			return err
-		}
+	// The retention policy may be amended by proposal, so fetch the version in effect at the latest block height
+	// before every scan. It is possible that a policy has not been set yet.
+	policy, err := a.blockchainClient.GetRetentionPolicy()
+	if err != nil {
+		return err
+	}
 
-		a.policy = policy
+	if policy == nil {
+		a.logger.Info("Tried to run retention policy scan, but the retention policy has not been set yet")
+		return nil
+	}
 
-		if a.policy == nil {
-			a.logger.Info("Tried to run retention policy scan, but the retention policy has not been set yet")
-			return nil
-		} else {
-			a.logger.Info("Retrieved retention policy from the blockchain")
-			a.logger.Debug("Retrieved retention policy from the blockchain", zap.Any("policy", a.policy.Policy))
-		}
+	if a.policy == nil || a.policy.Version != policy.Version {
+		a.logger.Info(
+			"Retrieved new retention policy version from the blockchain",
+			zap.Uint64("version", policy.Version),
+			zap.Int64("effective_height", policy.EffectiveHeight),
+		)
+		a.logger.Debug("Retrieved retention policy from the blockchain", zap.Any("policy", policy.Policy))
 	}
 
+	a.policy = policy
+
+	// Legal holds override the policy, so a scan must not proceed without them
+	holds, err := a.blockchainClient.GetLegalHolds()
+	if err != nil {
+		return err
+	}
+
+	active := holds.Active(time.Now())
+
 	ctx, cancelFunc := context.WithTimeout(context.Background(), a.config.EventRetention.ScanTimeout.Duration())
 	defer cancelFunc()
 
-	a.logger.Info("Scanning for and dropping events outside of the retention policy")
-	if err := a.repository.Events().DropExpiredEvents(ctx, a.policy.Policy); err != nil {
+	a.logger.Info(
+		"Scanning for and dropping events outside of the retention policy",
+		zap.Uint64("version", a.policy.Version),
+		zap.Int("active_holds", len(active)),
+	)
+	if err := a.repository.Events().DropExpiredEvents(ctx, a.policy.Policy, active); err != nil {
 		return err
 	}
 
@@ -65,7 +65,7 @@ func startBackfillResponseHandlerLoop[T any, U any](
 				logger.Debug("Processing backfilled event", zap.Stringer("event_id", event.EventId))
 
 				// Fetch TX from blockchain
-				tx, err := blockchainClient.GetEventByTx(event.TxHash)
+				tx, err := blockchainClient.GetEventByTx(event.TxHash, event.EventId)
 				if err != nil {
 					if errors.Is(err, blockchain.ErrEventNotFound) {
 						logger.Warn(
@@ -52,13 +52,23 @@ func EventRequestHandler(cfg config.Config, repository repository.Repository, tr
 			return
 		}
 
-		data := make([]payload.EventBackfillResponseData, len(events))
-		for i, event := range events {
-			data[i] = payload.EventBackfillResponseData{
+		data := make([]payload.EventBackfillResponseData, 0, len(events))
+		for _, event := range events {
+			// The data of redacted events has been erased, so cannot be shared
+			if event.Redaction != nil {
+				continue
+			}
+
+			data = append(data, payload.EventBackfillResponseData{
 				EventId:   event.Metadata.EventId,
 				TxHash:    event.TxHash,
 				EventData: event.EventWithData.EventData,
-			}
+			})
+		}
+
+		if len(data) == 0 {
+			logger.Debug("All requested events have been redacted", zap.String("source", sourceName))
+			return
 		}
 
 		marshalled, err := payload.NewPayloadMarshalled(payload.TypeBackfillResponse, payload.EventBackfillResponse{
@@ -75,7 +85,7 @@ func EventRequestHandler(cfg config.Config, repository repository.Repository, tr
 
 		logger.Info(
 			"Successfully responded to event backfill request",
-			zap.Int("count", len(events)),
+			zap.Int("count", len(data)),
 			zap.String("source", sourceName),
 		)
 
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"os"
+)
+
+// runExport writes the committed state of a stopped node as genesis app state, to be placed in the app_state field of
+// a new chain's genesis document. Flags following the export command are parsed separately from the node's flags.
+func runExport(app *multiplexer.MultiplexedApplication, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	outputPath := flags.String("output", "", "Path to write the genesis app state to. Written to stdout if empty")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	exported, err := app.ExportGenesis()
+	if err != nil {
+		return err
+	}
+
+	if *outputPath == "" {
+		_, err := os.Stdout.Write(append(exported, '\n'))
+		return err
+	}
+
+	return os.WriteFile(*outputPath, exported, 0644)
+}
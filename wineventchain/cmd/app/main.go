@@ -12,6 +12,8 @@ import (
 	"github.com/RyanW02/wineventchain/app/pkg/identity"
 	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
 	"github.com/RyanW02/wineventchain/app/pkg/retentionpolicy"
+	"github.com/RyanW02/wineventchain/app/pkg/upgrade"
+	"github.com/RyanW02/wineventchain/app/pkg/validators"
 	dbm "github.com/cometbft/cometbft-db"
 	"go.mongodb.org/mongo-driver/mongo"
 	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
@@ -56,7 +58,53 @@ func main() {
 		panic(err)
 	}
 
+	conf := loadConfig(logger)
+	app := newApplication(logger, conf)
+
+	if flag.Arg(0) == "export" {
+		if err := runExport(app, flag.Args()[1:]); err != nil {
+			logger.Fatal("failed to export genesis app state", zap.Error(err))
+		}
+
+		return
+	}
+
+	if conf.Metrics.ListenAddress != "" {
+		if err := serveMetrics(logger, app, conf.Metrics.ListenAddress); err != nil {
+			logger.Fatal("failed to serve metrics", zap.Error(err))
+		}
+	}
+
+	*tendermintConfigPath = strings.ReplaceAll(*tendermintConfigPath, "$HOME", homeDir)
+	node, err := newNode(app, *tendermintConfigPath, cfg.DefaultDBProvider)
+	if err != nil {
+		logger.Fatal("failed to create new node", zap.Error(err))
+	}
+
+	// Chains initialised before the chain ID was recorded by InitChain must learn it from the genesis document
+	app.SetChainID(node.GenesisDoc().ChainID)
+
+	if err := node.Start(); err != nil {
+		logger.Fatal("failed to start CometBFT node", zap.Error(err))
+	}
+
+	defer func() {
+		if err := node.Stop(); err != nil {
+			logger.Error("failed to stop CometBFT node", zap.Error(err))
+		}
+
+		node.Wait()
+	}()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+	os.Exit(0)
+}
+
+func loadConfig(logger *zap.Logger) config.Config {
 	var conf config.Config
+	var err error
 	if configPath != nil && *configPath != "" {
 		logger.Info("Loading config from file", zap.String("path", *configPath))
 
@@ -88,43 +136,47 @@ func main() {
 		}
 	}
 
+	return conf
+}
+
+// newApplication creates the application from the configured state store, as it was left by the last commit.
+func newApplication(logger *zap.Logger, conf config.Config) *multiplexer.MultiplexedApplication {
 	// Set up state store
 	dbGenerator := newDbGenerator(logger, conf)
 	stateDb := utils.Must(dbGenerator("state"))
 
 	identityApp := utils.Must(identity.NewIdentityApp(logger, utils.Must(dbGenerator("identity"))))
 	eventsApp := utils.Must(events.NewEventsApp(logger, utils.Must(dbGenerator("events")), identityApp.Repository))
+	eventsApp.MaxClockSkew = conf.Events.MaxClockSkew.Duration()
 	policyApp := utils.Must(retentionpolicy.NewRetentionPolicyApp(logger, identityApp.Repository, utils.Must(dbGenerator("retentionpolicy"))))
+	validatorsApp := utils.Must(validators.NewValidatorsApp(logger, identityApp.Repository, utils.Must(dbGenerator("validators"))))
+	upgradeApp := utils.Must(upgrade.NewUpgradeApp(logger, identityApp.Repository, utils.Must(dbGenerator("upgrade"))))
 	app := multiplexer.NewApplication(
 		logger,
 		stateDb,
 		identityApp,
 		eventsApp,
 		policyApp,
+		validatorsApp,
+		upgradeApp,
 	)
-
-	*tendermintConfigPath = strings.ReplaceAll(*tendermintConfigPath, "$HOME", homeDir)
-	node, err := newNode(app, *tendermintConfigPath, cfg.DefaultDBProvider)
-	if err != nil {
-		logger.Fatal("failed to create new node", zap.Error(err))
-	}
-
-	if err := node.Start(); err != nil {
-		logger.Fatal("failed to start CometBFT node", zap.Error(err))
+	app.Sequences = identityApp.Sequences
+	app.SnapshotInterval = conf.Snapshots.Interval
+	app.SnapshotKeepRecent = conf.Snapshots.KeepRecent
+	app.RetainBlocks = conf.Pruning.RetainBlocks
+	app.Pruning = multiplexer.PruningOptions{
+		KeepRecent: conf.Pruning.KeepRecent,
+		KeepEvery:  conf.Pruning.KeepEvery,
+		Interval:   conf.Pruning.Interval,
 	}
 
-	defer func() {
-		if err := node.Stop(); err != nil {
-			logger.Error("failed to stop CometBFT node", zap.Error(err))
+	for _, handler := range upgrades() {
+		if err := app.RegisterUpgrade(handler); err != nil {
+			logger.Fatal("failed to register upgrade handler", zap.Error(err))
 		}
+	}
 
-		node.Wait()
-	}()
-
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
-	os.Exit(0)
+	return app
 }
 
 func newNode(app abci.Application, configFile string, dbProvider cfg.DBProvider) (*nm.Node, error) {
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// serveMetrics serves the metrics of the application at /metrics on the given address, in the background. The metrics
+// are kept in a registry of their own, separate from CometBFT's.
+func serveMetrics(logger *zap.Logger, app *multiplexer.MultiplexedApplication, address string) error {
+	registry := prometheus.NewRegistry()
+	if err := app.RegisterMetrics(registry); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	go func() {
+		logger.Info("Serving metrics", zap.String("address", address))
+
+		if err := http.ListenAndServe(address, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server stopped", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
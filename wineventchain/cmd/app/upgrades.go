@@ -0,0 +1,11 @@
+package main
+
+import "github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+
+// upgrades returns the handlers for every upgrade supported by this binary. When a release changes the format of stored
+// state, a handler is added here with the height and version of the plan scheduled through the upgrade app, and
+// migrations for the affected sub-applications. Handlers are never removed, so that the chain can be replayed from
+// genesis.
+func upgrades() []multiplexer.Upgrade {
+	return []multiplexer.Upgrade{}
+}
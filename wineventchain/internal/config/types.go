@@ -1,5 +1,7 @@
 package config
 
+import "github.com/RyanW02/wineventchain/common/pkg/types"
+
 type Config struct {
 	StateStore struct {
 		Type StoreType `env:"TYPE" json:"type"`
@@ -11,6 +13,34 @@ type Config struct {
 			DatabaseName     string `env:"DB_NAME" json:"db_name"`
 		} `envPrefix:"MONGODB_" json:"mongodb"`
 	} `envPrefix:"STATE_STORE_" json:"state_store"`
+	Events struct {
+		// MaxClockSkew is the maximum difference between the creation time of an event and the node's clock for the
+		// event to be admitted to the mempool. Creation times are not checked against the clock if 0. Blocks are not
+		// checked against it, so it may differ between nodes, but it should be below the consensus limit on the skew
+		// from the block time, events.MaxBlockTimeSkew, which it cannot relax.
+		MaxClockSkew types.MarshalledDuration `env:"MAX_CLOCK_SKEW" json:"max_clock_skew"`
+	} `envPrefix:"EVENTS_" json:"events"`
+	Snapshots struct {
+		// Interval is the number of blocks between state sync snapshots, which allow new nodes to join the network
+		// without replaying every block. Snapshots are not taken if 0.
+		Interval   int64 `env:"INTERVAL" json:"interval"`
+		KeepRecent int   `env:"KEEP_RECENT" json:"keep_recent"`
+	} `envPrefix:"SNAPSHOTS_" json:"snapshots"`
+	Metrics struct {
+		// ListenAddress is the address on which Prometheus metrics are served, at /metrics. Metrics are not served if
+		// empty. CometBFT serves its own metrics separately, as configured by its instrumentation settings.
+		ListenAddress string `env:"LISTEN_ADDRESS" json:"listen_address"`
+	} `envPrefix:"METRICS_" json:"metrics"`
+	Pruning struct {
+		// RetainBlocks is the number of recent blocks that CometBFT retains. All blocks are retained if 0.
+		RetainBlocks int64 `env:"RETAIN_BLOCKS" json:"retain_blocks"`
+		// KeepRecent is the number of recent heights for which app state is retained. All state is retained if 0.
+		KeepRecent int64 `env:"KEEP_RECENT" json:"keep_recent"`
+		// KeepEvery additionally retains the app state at every KeepEvery-th height, if non-zero.
+		KeepEvery int64 `env:"KEEP_EVERY" json:"keep_every"`
+		// Interval is the number of blocks between runs of app state pruning.
+		Interval int64 `env:"INTERVAL" json:"interval"`
+	} `envPrefix:"PRUNING_" json:"pruning"`
 }
 
 type StoreType string
@@ -1,9 +1,53 @@
 package datastore
 
+import "bytes"
+
 type BaseRepository interface {
 	LoadLatest() (int64, error)
 	LoadVersion(version int64) error
+	// LoadVersionForOverwriting deletes every version saved after the given version, which becomes the latest.
+	LoadVersionForOverwriting(version int64) error
 	Rollback()
+	// Hash returns the hash of the latest saved version.
 	Hash() ([]byte, error)
+	// WorkingHash returns the hash of the working state, including writes that have not yet been saved.
+	WorkingHash() ([]byte, error)
 	Save() ([]byte, int64, error)
+	// Version returns the latest saved version.
+	Version() int64
+	// Size returns the number of entries in the working state.
+	Size() int64
+	// AvailableVersions returns the saved versions that have not been pruned, in ascending order.
+	AvailableVersions() []int64
+	// DeleteVersions prunes the given saved versions. The latest version cannot be deleted.
+	DeleteVersions(versions ...int64) error
+	// Export exports the latest saved version of the repository, for inclusion in a state sync snapshot.
+	Export() (TreeSnapshot, error)
+	// Import restores the repository from a state sync snapshot. The repository must be empty.
+	Import(snapshot TreeSnapshot) error
+	// ExportGenesis exports the content of the latest saved version of the repository, for carrying over to a new chain.
+	ExportGenesis() ([]GenesisEntry, error)
+	// ImportGenesis writes exported content to the working state of the repository, which must be empty.
+	ImportGenesis(entries []GenesisEntry) error
+}
+
+// SaveChanges saves a new version of the repository if the working state differs from the latest saved version, so
+// that versions are only saved for blocks that changed the state. Returns the latest saved version.
+func SaveChanges(repository BaseRepository) (int64, error) {
+	working, err := repository.WorkingHash()
+	if err != nil {
+		return 0, err
+	}
+
+	saved, err := repository.Hash()
+	if err != nil {
+		return 0, err
+	}
+
+	if bytes.Equal(working, saved) {
+		return repository.Version(), nil
+	}
+
+	_, version, err := repository.Save()
+	return version, err
 }
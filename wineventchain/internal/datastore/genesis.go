@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"errors"
+	"github.com/cosmos/iavl"
+)
+
+// GenesisEntry is a single key-value pair of an IAVL tree, as carried in the genesis app state of a new chain.
+type GenesisEntry struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+var ErrTreeNotEmpty = errors.New("tree must be empty to import genesis state")
+
+// ExportGenesis exports the key-value pairs of the latest saved version of the tree, in ascending key order. Unlike a
+// TreeSnapshot, the export does not carry the versions of tree nodes, so it can be imported into a new chain whose
+// versions start from the beginning, and is identical for any two trees with the same content.
+func ExportGenesis(tree *iavl.MutableTree) ([]GenesisEntry, error) {
+	entries := make([]GenesisEntry, 0)
+
+	version := tree.Version()
+	if version == 0 {
+		return entries, nil
+	}
+
+	immutable, err := tree.GetImmutable(version)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := immutable.Iterator(nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		entries = append(entries, GenesisEntry{
+			Key:   iterator.Key(),
+			Value: iterator.Value(),
+		})
+	}
+
+	return entries, iterator.Error()
+}
+
+// ImportGenesis writes exported key-value pairs to the working state of the tree, which must not have saved any
+// versions. The entries are written in the order given, so that every node derives the same tree from the same genesis
+// app state.
+func ImportGenesis(tree *iavl.MutableTree, entries []GenesisEntry) error {
+	if tree.Version() != 0 || tree.Size() != 0 {
+		return ErrTreeNotEmpty
+	}
+
+	for _, entry := range entries {
+		if _, err := tree.Set(entry.Key, entry.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package datastore
+
+import (
+	"github.com/cosmos/iavl"
+	"sort"
+)
+
+// AvailableVersions returns the saved versions of the tree that have not been deleted, in ascending order.
+func AvailableVersions(tree *iavl.MutableTree) []int64 {
+	available := tree.AvailableVersions()
+
+	versions := make([]int64, len(available))
+	for i, version := range available {
+		versions[i] = int64(version)
+	}
+
+	return versions
+}
+
+// DeleteVersions deletes the given versions of the tree, deleting contiguous runs of versions in a single batch.
+func DeleteVersions(tree *iavl.MutableTree, versions []int64) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] < versions[j]
+	})
+
+	from := versions[0]
+	for i := 1; i <= len(versions); i++ {
+		if i < len(versions) && versions[i] == versions[i-1]+1 {
+			continue
+		}
+
+		// DeleteVersionsRange excludes the upper bound
+		if err := tree.DeleteVersionsRange(from, versions[i-1]+1); err != nil {
+			return err
+		}
+
+		if i < len(versions) {
+			from = versions[i]
+		}
+	}
+
+	return nil
+}
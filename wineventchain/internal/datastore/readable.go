@@ -0,0 +1,41 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	dbm "github.com/cometbft/cometbft-db"
+	ics23 "github.com/confio/ics23/go"
+	"github.com/cosmos/iavl"
+)
+
+// ReadableTree is the read-only view of an IAVL tree, satisfied by both the working tree and the immutable trees of
+// saved versions.
+type ReadableTree interface {
+	Get(key []byte) ([]byte, error)
+	GetWithIndex(key []byte) (int64, []byte, error)
+	Has(key []byte) (bool, error)
+	Iterator(start, end []byte, ascending bool) (dbm.Iterator, error)
+	Size() int64
+	Hash() ([]byte, error)
+	GetProof(key []byte) (*ics23.CommitmentProof, error)
+}
+
+var ErrVersionUnavailable = errors.New("tree version is not available")
+
+var _ ReadableTree = (*iavl.MutableTree)(nil)
+var _ ReadableTree = (*iavl.ImmutableTree)(nil)
+
+// TreeAt returns the saved version of the tree. Version 0 is the empty tree that precedes the first saved version,
+// which cannot be read from, and so proof.ErrTreeUninitialized is returned.
+func TreeAt(tree *iavl.MutableTree, version int64) (ReadableTree, error) {
+	if version == 0 {
+		return nil, proof.ErrTreeUninitialized
+	}
+
+	if !tree.VersionExists(version) {
+		return nil, fmt.Errorf("%w: %d", ErrVersionUnavailable, version)
+	}
+
+	return tree.GetImmutable(version)
+}
@@ -0,0 +1,103 @@
+package datastore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/cosmos/iavl"
+)
+
+// TreeSnapshot is an export of the latest saved version of an IAVL tree, from which the tree can be reconstructed with
+// an identical hash on another node.
+type TreeSnapshot struct {
+	Version int64              `json:"version"`
+	Hash    []byte             `json:"hash"`
+	Nodes   []*iavl.ExportNode `json:"nodes"`
+}
+
+var ErrSnapshotHashMismatch = errors.New("restored tree hash does not match snapshot")
+
+// ExportTree exports the latest saved version of the tree. Unsaved changes are not included.
+func ExportTree(tree *iavl.MutableTree) (TreeSnapshot, error) {
+	version := tree.Version()
+	if version == 0 {
+		return TreeSnapshot{}, nil
+	}
+
+	immutable, err := tree.GetImmutable(version)
+	if err != nil {
+		return TreeSnapshot{}, err
+	}
+
+	hash, err := immutable.Hash()
+	if err != nil {
+		return TreeSnapshot{}, err
+	}
+
+	snapshot := TreeSnapshot{
+		Version: version,
+		Hash:    hash,
+		Nodes:   make([]*iavl.ExportNode, 0, immutable.Size()),
+	}
+
+	// The exporter cannot traverse a tree without a root
+	if immutable.Size() == 0 {
+		return snapshot, nil
+	}
+
+	exporter, err := immutable.Export()
+	if err != nil {
+		return TreeSnapshot{}, err
+	}
+	defer exporter.Close()
+
+	for {
+		node, err := exporter.Next()
+		if err != nil {
+			if errors.Is(err, iavl.ErrorExportDone) {
+				break
+			}
+
+			return TreeSnapshot{}, err
+		}
+
+		snapshot.Nodes = append(snapshot.Nodes, node)
+	}
+
+	return snapshot, nil
+}
+
+// ImportTree reconstructs the snapshotted version of a tree into the given tree, which must be empty, and verifies
+// that the resulting hash matches the snapshot.
+func ImportTree(tree *iavl.MutableTree, snapshot TreeSnapshot) error {
+	if snapshot.Version == 0 {
+		return nil
+	}
+
+	importer, err := tree.Import(snapshot.Version)
+	if err != nil {
+		return err
+	}
+	defer importer.Close()
+
+	for _, node := range snapshot.Nodes {
+		if err := importer.Add(node); err != nil {
+			return err
+		}
+	}
+
+	if err := importer.Commit(); err != nil {
+		return err
+	}
+
+	hash, err := tree.Hash()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(hash, snapshot.Hash) {
+		return fmt.Errorf("%w: expected %x, got %x", ErrSnapshotHashMismatch, snapshot.Hash, hash)
+	}
+
+	return nil
+}
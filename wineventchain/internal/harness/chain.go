@@ -0,0 +1,173 @@
+// Package harness drives a MultiplexedApplication through the ABCI methods, as CometBFT would, without running a node.
+// The application is backed by in-memory databases, which survive restarts of the application so that recovery can
+// be tested.
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/pkg/events"
+	"github.com/RyanW02/wineventchain/app/pkg/identity"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/app/pkg/retentionpolicy"
+	"github.com/RyanW02/wineventchain/app/pkg/upgrade"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// ChainID is the ID of every chain created by the harness, which signed transactions must be bound to.
+const ChainID = "test-chain"
+
+// GenesisTime is the time of the genesis block. Each block is timestamped one second after the previous block.
+var GenesisTime = time.Unix(1700000000, 0)
+
+// Chain is a single-node chain whose application is driven directly by the test.
+type Chain struct {
+	T   testing.TB
+	App *multiplexer.MultiplexedApplication
+	// DBs are the databases backing the application, keyed by name
+	DBs map[string]dbm.DB
+	// Height is the height of the latest committed block
+	Height int64
+
+	// Upgrades are registered with the application each time it is started
+	Upgrades []multiplexer.Upgrade
+	// MaxClockSkew is applied to the events app each time it is started
+	MaxClockSkew time.Duration
+}
+
+// New creates and initialises a chain without genesis app state.
+func New(t testing.TB) *Chain {
+	return NewFromGenesis(t, nil, 1)
+}
+
+// NewAtVersion creates and initialises a chain whose genesis document sets the given app version.
+func NewAtVersion(t testing.TB, appVersion uint64) *Chain {
+	return initChain(t, &abci.RequestInitChain{
+		Time:            GenesisTime,
+		ChainId:         ChainID,
+		ConsensusParams: &cmtproto.ConsensusParams{Version: &cmtproto.VersionParams{App: appVersion}},
+		InitialHeight:   1,
+	})
+}
+
+// NewFromGenesis creates a chain whose genesis document carries the given app state, and whose first block is at
+// initialHeight.
+func NewFromGenesis(t testing.TB, appState []byte, initialHeight int64) *Chain {
+	return initChain(t, &abci.RequestInitChain{
+		Time:          GenesisTime,
+		ChainId:       ChainID,
+		AppStateBytes: appState,
+		InitialHeight: initialHeight,
+	})
+}
+
+func initChain(t testing.TB, req *abci.RequestInitChain) *Chain {
+	chain := NewUninitialised(t)
+
+	_, err := chain.App.InitChain(context.Background(), req)
+	require.NoError(t, err)
+
+	chain.Height = req.InitialHeight - 1
+	return chain
+}
+
+// NewUninitialised creates a chain that has not run InitChain, as a node joining the network through state sync.
+func NewUninitialised(t testing.TB) *Chain {
+	chain := &Chain{
+		T: t,
+		DBs: map[string]dbm.DB{
+			"state":           dbm.NewMemDB(),
+			"identity":        dbm.NewMemDB(),
+			"events":          dbm.NewMemDB(),
+			"retentionpolicy": dbm.NewMemDB(),
+			"upgrade":         dbm.NewMemDB(),
+		},
+	}
+
+	chain.Start()
+	return chain
+}
+
+// Start creates the application from the chain's databases, as a node does on startup. Calling Start again simulates
+// a restart of the node.
+func (c *Chain) Start() {
+	logger := zap.NewNop()
+
+	identityApp, err := identity.NewIdentityApp(logger, c.DBs["identity"])
+	require.NoError(c.T, err)
+
+	eventsApp, err := events.NewEventsApp(logger, c.DBs["events"], identityApp.Repository)
+	require.NoError(c.T, err)
+	eventsApp.MaxClockSkew = c.MaxClockSkew
+
+	policyApp, err := retentionpolicy.NewRetentionPolicyApp(logger, identityApp.Repository, c.DBs["retentionpolicy"])
+	require.NoError(c.T, err)
+
+	upgradeApp, err := upgrade.NewUpgradeApp(logger, identityApp.Repository, c.DBs["upgrade"])
+	require.NoError(c.T, err)
+
+	c.App = multiplexer.NewApplication(logger, c.DBs["state"], identityApp, eventsApp, policyApp, upgradeApp)
+	c.App.Sequences = identityApp.Sequences
+
+	for _, handler := range c.Upgrades {
+		require.NoError(c.T, c.App.RegisterUpgrade(handler))
+	}
+}
+
+// CheckTx checks a transaction for admission to the mempool.
+func (c *Chain) CheckTx(tx []byte) *abci.ResponseCheckTx {
+	res, err := c.App.CheckTx(context.Background(), &abci.RequestCheckTx{Tx: tx, Type: abci.CheckTxType_New})
+	require.NoError(c.T, err)
+
+	return res
+}
+
+// Block finalizes and commits a block containing the given transactions, returning the response to FinalizeBlock.
+func (c *Chain) Block(txs ...[]byte) *abci.ResponseFinalizeBlock {
+	c.Height++
+
+	res, err := c.App.FinalizeBlock(context.Background(), &abci.RequestFinalizeBlock{
+		Txs:    txs,
+		Height: c.Height,
+		Time:   GenesisTime.Add(time.Duration(c.Height) * time.Second),
+	})
+	require.NoError(c.T, err)
+	require.Len(c.T, res.TxResults, len(txs))
+
+	_, err = c.App.Commit(context.Background(), &abci.RequestCommit{})
+	require.NoError(c.T, err)
+
+	return res
+}
+
+// LastAppHash returns the app hash of the latest committed block, as reported to CometBFT on startup.
+func (c *Chain) LastAppHash() []byte {
+	info, err := c.App.Info(context.Background(), &abci.RequestInfo{})
+	require.NoError(c.T, err)
+	require.Equal(c.T, c.Height, info.LastBlockHeight)
+
+	return info.LastBlockAppHash
+}
+
+// Query queries the latest committed state of an app, with proofs.
+func (c *Chain) Query(app, path string) *abci.ResponseQuery {
+	return c.QueryAt(app, path, 0)
+}
+
+// QueryAt queries the state of an app at the given height, with proofs.
+func (c *Chain) QueryAt(app, path string, height int64) *abci.ResponseQuery {
+	data, err := json.Marshal(rpc.MuxedRequest{App: app})
+	require.NoError(c.T, err)
+
+	res, err := c.App.Query(context.Background(), &abci.RequestQuery{Data: data, Path: path, Height: height, Prove: true})
+	require.NoError(c.T, err)
+
+	return res
+}
@@ -0,0 +1,136 @@
+package harness_test
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ChainSuite struct {
+	suite.Suite
+	chain        *harness.Chain
+	admin, agent harness.Principal
+}
+
+func TestChainSuite(t *testing.T) {
+	suite.Run(t, new(ChainSuite))
+}
+
+// SetupTest starts each test from a fresh chain, on which the admin has been seeded and the agent registered.
+func (suite *ChainSuite) SetupTest() {
+	suite.chain = harness.New(suite.T())
+	suite.admin = harness.NewPrincipal(suite.T(), "admin")
+	suite.agent = harness.NewPrincipal(suite.T(), "agent")
+
+	res := suite.chain.Block(
+		harness.SeedTx(suite.T(), suite.admin),
+		harness.RegisterTx(suite.T(), suite.admin, 0, suite.agent, identitytypes.RoleAgent),
+	)
+	harness.RequireCodes(suite.T(), res, identitytypes.CodeOk, identitytypes.CodeOk)
+}
+
+func (suite *ChainSuite) TestSeedOnce() {
+	other := harness.NewPrincipal(suite.T(), "other")
+
+	checked := suite.chain.CheckTx(harness.SeedTx(suite.T(), other))
+	suite.Require().Equal(identitytypes.CodeAlreadySeeded, checked.Code, checked.Log)
+
+	res := suite.chain.Block(harness.SeedTx(suite.T(), other))
+	harness.RequireCodes(suite.T(), res, identitytypes.CodeAlreadySeeded)
+
+	queried := suite.chain.Query(identitytypes.AppName, "/"+string(other.Name))
+	suite.Require().Equal(identitytypes.CodeNotFound, queried.Code, queried.Log)
+}
+
+func (suite *ChainSuite) TestRegistration() {
+	queried := suite.chain.Query(identitytypes.AppName, "/"+string(suite.agent.Name))
+	suite.Require().Equal(identitytypes.CodeOk, queried.Code, queried.Log)
+
+	other := harness.NewPrincipal(suite.T(), "other")
+	res := suite.chain.Block(
+		// Agents may not register other principals
+		harness.RegisterTx(suite.T(), suite.agent, 0, other, identitytypes.RoleAgent),
+		harness.RegisterTx(suite.T(), suite.admin, 1, suite.agent, identitytypes.RoleAgent),
+		harness.RegisterTx(suite.T(), suite.admin, 1, other, identitytypes.RoleAgent),
+	)
+	harness.RequireCodes(suite.T(), res,
+		identitytypes.CodeUnauthorized,
+		identitytypes.CodePrincipalAlreadyExists,
+		identitytypes.CodeOk,
+	)
+}
+
+func (suite *ChainSuite) TestEventCreation() {
+	tx := harness.CreateEventTx(suite.T(), suite.agent, 0, 1)
+
+	checked := suite.chain.CheckTx(tx)
+	suite.Require().Equal(eventtypes.CodeOk, checked.Code, checked.Log)
+
+	res := suite.chain.Block(tx)
+	harness.RequireCodes(suite.T(), res, eventtypes.CodeOk)
+
+	ids := harness.EventIds(suite.T(), res, 0)
+	suite.Require().Len(ids, 1)
+
+	queried := suite.chain.Query(eventtypes.AppName, "/event-by-id/"+ids[0].String())
+	suite.Require().Equal(eventtypes.CodeOk, queried.Code, queried.Log)
+
+	var event eventtypes.EventWithMetadata
+	suite.Require().NoError(json.Unmarshal(queried.Value, &event))
+	suite.Require().Equal(suite.agent.Name, event.Metadata.Principal)
+	suite.Require().Equal(1, event.Event.System.EventRecordId)
+}
+
+func (suite *ChainSuite) TestDuplicateEvents() {
+	event := harness.TestEvent(1)
+
+	res := suite.chain.Block(
+		harness.EventTx(suite.T(), suite.agent, 0, event),
+		harness.EventTx(suite.T(), suite.agent, 1, event),
+	)
+	harness.RequireCodes(suite.T(), res, eventtypes.CodeOk, eventtypes.CodeDuplicateEvent)
+
+	// Event IDs are derived from the block height, so resubmitting the event in a later block creates a distinct event
+	later := suite.chain.Block(harness.EventTx(suite.T(), suite.agent, 1, event))
+	harness.RequireCodes(suite.T(), later, eventtypes.CodeOk)
+	suite.Require().NotEqual(harness.EventIds(suite.T(), res, 0), harness.EventIds(suite.T(), later, 0))
+}
+
+func (suite *ChainSuite) TestMultiTxBlock() {
+	other := harness.NewPrincipal(suite.T(), "other")
+
+	res := suite.chain.Block(
+		harness.RegisterTx(suite.T(), suite.admin, 1, other, identitytypes.RoleAgent),
+		harness.CreateEventTx(suite.T(), other, 0, 1),
+		harness.CreateEventTx(suite.T(), suite.agent, 0, 1),
+		harness.EventBatchTx(suite.T(), suite.agent, 1, harness.TestEvent(2), harness.TestEvent(3)),
+	)
+	harness.RequireCodes(suite.T(), res, identitytypes.CodeOk, eventtypes.CodeOk, eventtypes.CodeOk, eventtypes.CodeOk)
+	suite.Require().Len(harness.EventIds(suite.T(), res, 1), 4)
+}
+
+func (suite *ChainSuite) TestProofs() {
+	res := suite.chain.Block(harness.CreateEventTx(suite.T(), suite.agent, 0, 1))
+	harness.RequireCodes(suite.T(), res, eventtypes.CodeOk)
+
+	eventId := harness.EventIds(suite.T(), res, 0)[0]
+	queried := suite.chain.Query(eventtypes.AppName, "/event-by-id/"+eventId.String())
+	suite.Require().Equal(eventtypes.CodeOk, queried.Code, queried.Log)
+	suite.Require().Equal(suite.chain.Height, queried.Height)
+
+	value, err := proof.VerifyChain(queried.ProofOps, eventtypes.AppName, eventId, res.AppHash, multiplexer.AppVersion)
+	suite.Require().NoError(err)
+	suite.Require().Equal(queried.Value, value)
+
+	// The proof must be rejected against the app hash of a later block that changed the state
+	next := suite.chain.Block(harness.CreateEventTx(suite.T(), suite.agent, 1, 2))
+	harness.RequireCodes(suite.T(), next, eventtypes.CodeOk)
+
+	_, err = proof.VerifyChain(queried.ProofOps, eventtypes.AppName, eventId, next.AppHash, multiplexer.AppVersion)
+	suite.Require().Error(err)
+}
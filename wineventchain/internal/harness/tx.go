@@ -0,0 +1,193 @@
+package harness
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	upgradetypes "github.com/RyanW02/wineventchain/common/pkg/types/upgrade"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Principal is an identity, and the private key with which it signs transactions.
+type Principal struct {
+	Name identitytypes.Principal
+	Key  ed25519.PrivateKey
+}
+
+// NewPrincipal generates a key for a new principal. The principal must be seeded or registered before it can submit
+// signed transactions.
+func NewPrincipal(t testing.TB, name string) Principal {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	return Principal{Name: identitytypes.Principal(name), Key: key}
+}
+
+// PublicKey returns the public key of the principal.
+func (p Principal) PublicKey() ed25519.PublicKey {
+	return p.Key.Public().(ed25519.PublicKey)
+}
+
+// SignedTx builds a transaction for the given app, signed by the principal with the given sequence number.
+func SignedTx(t testing.TB, signer Principal, sequence uint64, app string, requestType rpc.RequestType, data any) []byte {
+	tx, err := rpc.NewBuilder().
+		App(app).
+		Data(requestType, data).
+		ChainID(ChainID).
+		Sequence(sequence).
+		Signed(signer.Name, signer.Key).
+		Marshal()
+	require.NoError(t, err)
+
+	return tx
+}
+
+// LegacyEventTx builds a transaction that creates a single event, with a legacy signature covering the payload data
+// only. The sequence number is omitted if 0.
+func LegacyEventTx(t testing.TB, agent Principal, sequence uint64, recordId int) []byte {
+	data, err := json.Marshal(eventtypes.CreateRequest{Event: TestEvent(recordId)})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(rpc.SignedPayload{
+		Payload:   rpc.Payload{Type: eventtypes.RequestTypeCreate, Data: data},
+		Principal: agent.Name,
+		Signature: hex.EncodeToString(ed25519.Sign(agent.Key, data)),
+		Sequence:  sequence,
+	})
+	require.NoError(t, err)
+
+	tx, err := json.Marshal(rpc.MuxedRequest{App: eventtypes.AppName, Data: payload})
+	require.NoError(t, err)
+
+	return tx
+}
+
+// SeedTx builds the unsigned transaction that registers the first administrator of the chain.
+func SeedTx(t testing.TB, admin Principal) []byte {
+	tx, err := rpc.NewBuilder().
+		App(identitytypes.AppName).
+		Data(identitytypes.RequestTypeSeed, identitytypes.PayloadSeed{
+			Principal: admin.Name,
+			Key:       admin.PublicKey(),
+		}).
+		Unsigned().
+		Marshal()
+	require.NoError(t, err)
+
+	return tx
+}
+
+// RegisterTx builds a transaction in which the admin registers a new principal with the given role.
+func RegisterTx(t testing.TB, admin Principal, sequence uint64, registering Principal, role identitytypes.Role) []byte {
+	return SignedTx(t, admin, sequence, identitytypes.AppName, identitytypes.RequestTypeRegister, identitytypes.PayloadRegister{
+		Principal: registering.Name,
+		Role:      role,
+		Key:       registering.PublicKey(),
+	})
+}
+
+// RotateKeyTx builds a transaction in which the signer replaces the key of the rotating principal, which is updated
+// to sign with the new key.
+func RotateKeyTx(t testing.TB, signer Principal, sequence uint64, rotating *Principal) []byte {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	tx := SignedTx(t, signer, sequence, identitytypes.AppName, identitytypes.RequestTypeRotateKey, identitytypes.PayloadRotateKey{
+		Principal: rotating.Name,
+		Key:       key.Public().(ed25519.PublicKey),
+	})
+
+	rotating.Key = key
+	return tx
+}
+
+// CreateEventTx creates an event that is distinguished from others created by the same principal at the same height
+// by its record ID.
+func CreateEventTx(t testing.TB, agent Principal, sequence uint64, recordId int) []byte {
+	return EventTx(t, agent, sequence, TestEvent(recordId))
+}
+
+// TestEvent returns a valid event with the given record ID, read from the Security channel. The event is created at
+// GenesisTime, so that it is within the limit on the skew from the time of any block in a test.
+func TestEvent(recordId int) eventtypes.ScrubbedEvent {
+	providerName := "Microsoft-Windows-Security-Auditing"
+	return eventtypes.ScrubbedEvent{
+		OffChainHash: fmt.Sprintf("%064x", recordId),
+		Event: eventtypes.Event{
+			System: eventtypes.System{
+				Provider:      eventtypes.Provider{Name: &providerName},
+				TimeCreated:   eventtypes.TimeCreated{SystemTime: GenesisTime},
+				EventId:       4624,
+				EventRecordId: recordId,
+				Channel:       "Security",
+				Computer:      "host",
+			},
+		},
+	}
+}
+
+// EventTx builds a transaction that creates a single event.
+func EventTx(t testing.TB, agent Principal, sequence uint64, event eventtypes.ScrubbedEvent) []byte {
+	return SignedTx(t, agent, sequence, eventtypes.AppName, eventtypes.RequestTypeCreate, eventtypes.CreateRequest{Event: event})
+}
+
+// EventBatchTx builds a transaction that creates a batch of events.
+func EventBatchTx(t testing.TB, agent Principal, sequence uint64, events ...eventtypes.ScrubbedEvent) []byte {
+	return SignedTx(t, agent, sequence, eventtypes.AppName, eventtypes.RequestTypeCreateBatch, eventtypes.CreateBatchRequest{Events: events})
+}
+
+// RedactTx builds a transaction that records redaction orders for the given events.
+func RedactTx(t testing.TB, author Principal, sequence uint64, eventIds ...eventtypes.EventHash) []byte {
+	return SignedTx(t, author, sequence, eventtypes.AppName, eventtypes.RequestTypeRedact, eventtypes.RedactRequest{
+		EventIds: eventIds,
+		Reason:   "erasure request",
+	})
+}
+
+// ScheduleUpgradeTx builds a transaction in which the admin schedules an upgrade plan.
+func ScheduleUpgradeTx(t testing.TB, admin Principal, sequence uint64, plan upgradetypes.Plan) []byte {
+	return SignedTx(t, admin, sequence, upgradetypes.AppName, upgradetypes.RequestTypeSchedule, upgradetypes.ScheduleRequest{Plan: plan})
+}
+
+// RequireCodes asserts that the leading transactions of the block returned the given codes.
+func RequireCodes(t testing.TB, res *abci.ResponseFinalizeBlock, codes ...uint32) {
+	for i, code := range codes {
+		require.Equal(t, code, res.TxResults[i].Code, "tx %d: %s", i, res.TxResults[i].Log)
+	}
+}
+
+// EventIds returns the IDs of the events created by the transactions of the block, starting from the transaction at
+// index from. Rejected transactions are skipped.
+func EventIds(t testing.TB, res *abci.ResponseFinalizeBlock, from int) []eventtypes.EventHash {
+	var ids []eventtypes.EventHash
+	for _, result := range res.TxResults[from:] {
+		if result.Code != eventtypes.CodeOk {
+			continue
+		}
+
+		var created eventtypes.CreateResponse
+		require.NoError(t, json.Unmarshal(result.Data, &created))
+
+		for _, metadata := range created.All() {
+			ids = append(ids, metadata.EventId)
+		}
+	}
+
+	return ids
+}
+
+// EventTypes returns the type of each ABCI event emitted by the transaction.
+func EventTypes(result *abci.ExecTxResult) []string {
+	types := make([]string, len(result.Events))
+	for i, event := range result.Events {
+		types[i] = event.Type
+	}
+
+	return types
+}
@@ -1,5 +1,10 @@
 package utils
 
+import (
+	"cmp"
+	"slices"
+)
+
 func Keys[T comparable, U any](m map[T]U) []T {
 	keys := make([]T, len(m))
 
@@ -11,3 +16,10 @@ func Keys[T comparable, U any](m map[T]U) []T {
 
 	return keys
 }
+
+// SortedKeys returns the keys of m in ascending order, for iteration in a deterministic order.
+func SortedKeys[T cmp.Ordered, U any](m map[T]U) []T {
+	keys := Keys(m)
+	slices.Sort(keys)
+	return keys
+}
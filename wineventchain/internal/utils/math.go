@@ -9,3 +9,11 @@ func Max[T constraints.Ordered](a, b T) T {
 
 	return b
 }
+
+func Min[T constraints.Ordered](a, b T) T {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -6,29 +6,46 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
 	"github.com/RyanW02/wineventchain/app/internal/utils"
 	"github.com/RyanW02/wineventchain/app/pkg/identity"
 	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
 	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	types "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
 	dbm "github.com/cometbft/cometbft-db"
 	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
 	"github.com/cosmos/iavl"
 	"go.uber.org/zap"
+	"math"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type EventsApp struct {
-	Repository    Repository
+	Repository Repository
+	// MaxClockSkew is the maximum difference between the creation time of an event and the local clock for the event
+	// to be admitted to the mempool. Creation times are not checked against the local clock if 0. Only applied by
+	// CheckTx, as a node-local setting cannot decide whether a block is valid: blocks are held to
+	// types.MaxBlockTimeSkew instead, so this should be set below it, to act as a stricter filter.
+	MaxClockSkew  time.Duration
 	logger        *zap.Logger
 	identities    identity.Repository
 	versionNumber int64
 	txState       txState
+	metrics       metrics
 }
 
 type txState struct {
 	creating []string
+	// missingRecords and regressions are the record anomalies detected in the current block
+	missingRecords int
+	regressions    int
 }
 
 func defaultTxState() txState {
@@ -37,7 +54,9 @@ func defaultTxState() txState {
 	}
 }
 
-var _ multiplexer.MultiplexedApp = (*EventsApp)(nil)
+var _ multiplexer.SnapshottableApp = (*EventsApp)(nil)
+var _ multiplexer.GenesisApp = (*EventsApp)(nil)
+var _ multiplexer.VersionedApp = (*EventsApp)(nil)
 
 const (
 	treeCacheSize = 1000
@@ -61,6 +80,7 @@ func NewEventsApp(logger *zap.Logger, db dbm.DB, identityRepository identity.Rep
 		identities:    identityRepository,
 		versionNumber: versionNumber,
 		txState:       defaultTxState(),
+		metrics:       newMetrics(),
 	}, nil
 }
 
@@ -81,7 +101,18 @@ func (app *EventsApp) Info(ctx context.Context, req *abci.RequestInfo) any {
 	}
 }
 
+// InitChain saves the empty state of a new chain, so that a block whose commit did not complete can always be rolled
+// back to an earlier version.
 func (app *EventsApp) InitChain(ctx context.Context, req *abci.RequestInitChain) []byte {
+	if app.Repository.Version() == 0 {
+		_, versionNumber, err := app.Repository.Save()
+		if err != nil {
+			app.logger.Fatal("Got error saving EventsApp state when running InitChain", zap.Error(err))
+		}
+
+		app.versionNumber = versionNumber
+	}
+
 	appHash, err := app.Repository.Hash()
 	if err != nil {
 		app.logger.Fatal("Got error getting hash of EventsApp when running InitChain", zap.Error(err))
@@ -90,19 +121,140 @@ func (app *EventsApp) InitChain(ctx context.Context, req *abci.RequestInitChain)
 	return appHash
 }
 
+// ExportSnapshot exports the latest committed version of the events tree.
+func (app *EventsApp) ExportSnapshot() (json.RawMessage, error) {
+	snapshot, err := app.Repository.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(snapshot)
+}
+
+// RestoreSnapshot imports the events tree from a snapshot, verifying its hash.
+func (app *EventsApp) RestoreSnapshot(data json.RawMessage) error {
+	var snapshot datastore.TreeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if err := app.Repository.Import(snapshot); err != nil {
+		return err
+	}
+
+	app.versionNumber = snapshot.Version
+	return nil
+}
+
+func (app *EventsApp) WorkingHash() ([]byte, error) {
+	return app.Repository.WorkingHash()
+}
+
+// Commit saves the events stored in the current block, if any.
+func (app *EventsApp) Commit() error {
+	stored := len(app.txState.creating)
+	missingRecords, regressions := app.txState.missingRecords, app.txState.regressions
+	app.txState = defaultTxState()
+
+	versionNumber, err := datastore.SaveChanges(app.Repository)
+	if err != nil {
+		return err
+	}
+
+	if stored > 0 {
+		app.logger.Info("Committed events successfully", zap.Int("count", stored), zap.Int64("version_number", versionNumber))
+	}
+
+	app.metrics.created.Add(float64(stored))
+	app.metrics.missingRecords.Add(float64(missingRecords))
+	app.metrics.regressions.Add(float64(regressions))
+
+	app.versionNumber = versionNumber
+	return nil
+}
+
+func (app *EventsApp) LoadVersionForOverwriting(version int64) error {
+	if err := app.Repository.LoadVersionForOverwriting(version); err != nil {
+		return err
+	}
+
+	app.versionNumber = version
+	return nil
+}
+
+func (app *EventsApp) Version() int64 {
+	return app.Repository.Version()
+}
+
+func (app *EventsApp) AvailableVersions() []int64 {
+	return app.Repository.AvailableVersions()
+}
+
+func (app *EventsApp) DeleteVersions(versions ...int64) error {
+	return app.Repository.DeleteVersions(versions...)
+}
+
+// ExportGenesis exports the content of the latest committed version of the events tree.
+func (app *EventsApp) ExportGenesis() (json.RawMessage, error) {
+	entries, err := app.Repository.ExportGenesis()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entries)
+}
+
+// InitGenesis writes the events and redaction orders carried over from another chain to the working tree. The state is
+// saved by InitChain. Events keep the heights at which they were stored on the original chain.
+func (app *EventsApp) InitGenesis(data json.RawMessage) error {
+	var entries []datastore.GenesisEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	return app.Repository.ImportGenesis(entries)
+}
+
 func (app *EventsApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, data json.RawMessage) (*abci.ResponseCheckTx, error) {
 	// Checks the signature on the request, and ensure that the principal making the request exists
-	decoded, err := app.decode(data)
+	decoded, requester, err := app.decode(ctx, data, 0)
 	if err != nil {
 		return err.IntoCheckTxResponse(), nil
 	}
 
 	switch decoded.Type {
-	case types.RequestTypeCreate:
-		var payload types.CreateRequest
-		if err := json.Unmarshal(decoded.Data, &payload); err != nil {
-			app.logger.Warn("Got error decoding EventsApp create payload", zap.Error(err))
-			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+	case types.RequestTypeCreate, types.RequestTypeCreateBatch:
+		if !requester.Can(identitytypes.PermissionCreateEvents) {
+			app.logger.Warn(
+				"Got unauthorised principal attempting to create an event",
+				zap.String("requester", decoded.Principal.String()),
+				zap.String("role", requester.Role.String()),
+			)
+			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to create events")).IntoCheckTxResponse(), nil
+		}
+
+		scrubbedEvents, errRes := app.decodeEvents(decoded)
+		if errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+
+		// CheckTx does not decide the result of a block, so events are validated at every app version. The block time
+		// is not yet known, so creation times are checked against the local clock.
+		if errRes := app.validateEvents(scrubbedEvents, time.Now(), app.MaxClockSkew); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+	case types.RequestTypeRedact:
+		if !requester.Can(identitytypes.PermissionRedactEvents) {
+			app.logger.Warn(
+				"Got unauthorised principal attempting to redact events",
+				zap.String("requester", decoded.Principal.String()),
+				zap.String("role", requester.Role.String()),
+			)
+			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to redact events")).IntoCheckTxResponse(), nil
+		}
+
+		if _, err := app.decodeRedaction(decoded); err != nil {
+			return err.IntoCheckTxResponse(), nil
 		}
 	default:
 		return multiplexer.NewErrorResponse(
@@ -119,131 +271,259 @@ func (app *EventsApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, dat
 }
 
 func (app *EventsApp) FinalizeBlock(ctx context.Context, req *abci.RequestFinalizeBlock, data json.RawMessage) multiplexer.FinalizeBlockResponse {
-	decoded, err := app.decode(data)
+	decoded, requester, err := app.decode(ctx, data, req.Height)
 	if err != nil {
 		return err.IntoFinalizeBlockResponse()
 	}
-	if err := app.Repository.LoadVersion(app.versionNumber); err != nil {
-		app.logger.Warn("Got error loading EventsApp version", zap.Error(err))
-		return multiplexer.NewErrorResponse(rpc.CodeUnknownRequestType, rpc.Codespace, err).IntoFinalizeBlockResponse()
+	switch decoded.Type {
+	case types.RequestTypeCreate, types.RequestTypeCreateBatch:
+		if !requester.Can(identitytypes.PermissionCreateEvents) {
+			app.logger.Warn(
+				"Got unauthorised principal attempting to create an event",
+				zap.String("requester", decoded.Principal.String()),
+				zap.String("role", requester.Role.String()),
+			)
+			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to create events")).IntoFinalizeBlockResponse()
+		}
+
+		// Events in the block that rotates the principal's key would have off-chain data signed by an ambiguous key
+		if requester.KeyRotatedAt(req.Height) {
+			return multiplexer.NewErrorResponse(
+				types.CodeKeyRotated,
+				types.Codespace,
+				errors.New("principal's key was rotated in this block, so events must be created in a later block"),
+			).IntoFinalizeBlockResponse()
+		}
+
+		scrubbedEvents, errRes := app.decodeEvents(decoded)
+		if errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
+
+		// Blocks created before validation was introduced must be replayed under the rules they were created with
+		if multiplexer.AppVersionFromContext(ctx) >= types.ValidationAppVersion {
+			if errRes := app.validateEvents(scrubbedEvents, req.Time, types.MaxBlockTimeSkew); errRes != nil {
+				return errRes.IntoFinalizeBlockResponse()
+			}
+		}
+
+		return app.createEvents(req, decoded, scrubbedEvents)
+	case types.RequestTypeRedact:
+		if !requester.Can(identitytypes.PermissionRedactEvents) {
+			app.logger.Warn(
+				"Got unauthorised principal attempting to redact events",
+				zap.String("requester", decoded.Principal.String()),
+				zap.String("role", requester.Role.String()),
+			)
+			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to redact events")).IntoFinalizeBlockResponse()
+		}
+
+		request, errRes := app.decodeRedaction(decoded)
+		if errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
+
+		return app.redactEvents(req, decoded, request)
+	default:
+		return multiplexer.NewErrorResponse(
+			rpc.CodeUnknownRequestType,
+			rpc.Codespace,
+			fmt.Errorf("unknown request type: %s", decoded.Type),
+		).IntoFinalizeBlockResponse()
 	}
+}
 
-	switch decoded.Type {
-	case types.RequestTypeCreate:
+// decodeEvents extracts the events carried by a create or create_batch request.
+func (app *EventsApp) decodeEvents(decoded rpc.SignedPayload) ([]types.ScrubbedEvent, *multiplexer.ErrorResponse) {
+	var scrubbedEvents []types.ScrubbedEvent
+	if decoded.Type == types.RequestTypeCreate {
 		var payload types.CreateRequest
 		if err := json.Unmarshal(decoded.Data, &payload); err != nil {
 			app.logger.Warn("Got error decoding EventsApp create payload", zap.Error(err))
-			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+			return nil, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
 		}
 
-		eventId, err := types.NewEventHash(uint64(req.Height), decoded.Principal, payload.Event.Event)
-		if err != nil {
-			app.logger.Error("Failed to generate EventId hash", zap.Error(err), zap.Int64("height", req.Height))
-			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		scrubbedEvents = []types.ScrubbedEvent{payload.Event}
+	} else {
+		var payload types.CreateBatchRequest
+		if err := json.Unmarshal(decoded.Data, &payload); err != nil {
+			app.logger.Warn("Got error decoding EventsApp create batch payload", zap.Error(err))
+			return nil, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
 		}
 
-		// De-dup
-		if utils.Contains(app.txState.creating, eventId.String()) {
-			app.logger.Warn("Duplicate event creation request", zap.Stringer("event_id", eventId))
-			return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, nil).IntoFinalizeBlockResponse()
+		if len(payload.Events) == 0 || len(payload.Events) > types.MaxBatchSize {
+			return nil, multiplexer.NewErrorResponse(
+				types.CodeInvalidBatchSize,
+				types.Codespace,
+				fmt.Errorf("batch must contain between 1 and %d events, got %d", types.MaxBatchSize, len(payload.Events)),
+			)
 		}
 
-		app.txState.creating = append(app.txState.creating, eventId.String())
+		scrubbedEvents = payload.Events
+	}
 
-		metadata := types.Metadata{
-			EventId:      eventId,
-			ReceivedTime: req.Time, // Deterministic
-			Principal:    decoded.Principal,
+	return scrubbedEvents, nil
+}
+
+// validateEvents returns an error response identifying the first malformed event, if any. Creation times are checked
+// against the reference time if maxSkew is non-zero.
+func (app *EventsApp) validateEvents(
+	scrubbedEvents []types.ScrubbedEvent,
+	reference time.Time,
+	maxSkew time.Duration,
+) *multiplexer.ErrorResponse {
+	for i, event := range scrubbedEvents {
+		if err := event.Validate(reference, maxSkew); err != nil {
+			var validationErr *types.ValidationError
+			if !errors.As(err, &validationErr) {
+				return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+			}
+
+			app.logger.Debug("Got invalid event", zap.Int("index", i), zap.Error(err))
+			return multiplexer.NewErrorResponse(validationErr.Code, types.Codespace, fmt.Errorf("event %d: %w", i, err))
 		}
+	}
+
+	return nil
+}
 
-		appHash, err := app.Repository.Hash()
+// createEvents assigns each event its own ID, and stores all the events in a single commit.
+func (app *EventsApp) createEvents(
+	req *abci.RequestFinalizeBlock,
+	decoded rpc.SignedPayload,
+	scrubbedEvents []types.ScrubbedEvent,
+) multiplexer.FinalizeBlockResponse {
+	toStore := make([]types.EventWithMetadata, 0, len(scrubbedEvents))
+	eventIds := make([]string, 0, len(scrubbedEvents))
+	for _, event := range scrubbedEvents {
+		eventId, err := types.NewEventHash(uint64(req.Height), decoded.Principal, event.Event)
 		if err != nil {
-			app.logger.Warn("Got error getting app hash", zap.Error(err))
+			app.logger.Error("Failed to generate EventId hash", zap.Error(err), zap.Int64("height", req.Height))
 			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
 
-		app.logger.Info(
-			"Generated pre-commit AppHash for event create",
-			zap.String("app_hash", hex.EncodeToString(appHash)),
-			zap.Stringer("event_id", eventId),
-		)
+		// De-dup, against both other transactions in this block and other events in this batch
+		if utils.Contains(app.txState.creating, eventId.String()) || utils.Contains(eventIds, eventId.String()) {
+			app.logger.Warn("Duplicate event creation request", zap.Stringer("event_id", eventId))
+			return multiplexer.NewErrorResponse(types.CodeDuplicateEvent, types.Codespace, fmt.Errorf("duplicate event: %s", eventId)).IntoFinalizeBlockResponse()
+		}
 
-		responseMarshalled, err := json.Marshal(types.CreateResponse{Metadata: metadata})
-		if err != nil {
-			app.logger.Warn("Got error marshalling create response", zap.Error(err))
-			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		eventIds = append(eventIds, eventId.String())
+		toStore = append(toStore, types.EventWithMetadata{
+			ScrubbedEvent: event,
+			Metadata: types.Metadata{
+				EventId:      eventId,
+				ReceivedTime: req.Time, // Deterministic
+				Principal:    decoded.Principal,
+			},
+		})
+	}
+
+	tracking, err := app.trackRecords(decoded.Principal, req.Height, toStore)
+	if err != nil {
+		app.logger.Warn("Got error tracking event records", zap.Error(err))
+		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	response := types.CreateResponse{Metadata: toStore[0].Metadata}
+	if decoded.Type == types.RequestTypeCreateBatch {
+		response.Batch = make([]types.Metadata, len(toStore))
+		for i, event := range toStore {
+			response.Batch[i] = event.Metadata
 		}
+	}
 
-		return multiplexer.FinalizeBlockResponse{
-			TxResult: abci.ExecTxResult{
-				Code: types.CodeOk,
-				Data: responseMarshalled,
-				Log:  "event stored",
-				Events: []abci.Event{utils.Event(types.EventCreate,
-					abci.EventAttribute{
-						Key:   types.AttributeType,
-						Value: types.AttributeValueCreate,
-						Index: true,
-					},
-					abci.EventAttribute{
-						Key:   types.AttributeEventId,
-						Value: eventId.String(),
-						Index: true,
-					},
-					abci.EventAttribute{
-						Key:   types.AttributePrincipal,
-						Value: decoded.Principal.String(),
-						Index: true,
-					},
-				)},
-				Codespace: types.Codespace,
+	responseMarshalled, err := json.Marshal(response)
+	if err != nil {
+		app.logger.Warn("Got error marshalling create response", zap.Error(err))
+		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	// Emit one event per stored event, so that subscribers receive every event ID
+	abciEvents := make([]abci.Event, len(toStore))
+	for i, event := range toStore {
+		abciEvents[i] = utils.Event(types.EventCreate,
+			abci.EventAttribute{
+				Key:   types.AttributeType,
+				Value: types.AttributeValueCreate,
+				Index: true,
+			},
+			abci.EventAttribute{
+				Key:   types.AttributeEventId,
+				Value: event.Metadata.EventId.String(),
+				Index: true,
 			},
-			AppHash: appHash,
-			CommitFunc: func() error {
-				app.logger.Info("Committing event", zap.Stringer("event_id", eventId))
-
-				// Reset state to before tx
-				app.txState = defaultTxState()
-
-				// Apply state changes
-				if err := app.Repository.Store(types.EventWithMetadata{
-					ScrubbedEvent: payload.Event,
-					Metadata:      metadata,
-				}); err != nil {
-					return err
-				}
-
-				newHash, versionNumber, err := app.Repository.Save()
-				if err != nil {
-					return err
-				}
-
-				app.logger.Info(
-					"Committed event successfully",
-					zap.Stringer("event_id", eventId),
-					zap.String("app_hash", hex.EncodeToString(newHash)),
-					zap.Int("version_number", int(versionNumber)),
-				)
-
-				app.versionNumber = versionNumber
-				return nil
+			abci.EventAttribute{
+				Key:   types.AttributePrincipal,
+				Value: decoded.Principal.String(),
+				Index: true,
 			},
+		)
+	}
+
+	// Apply state changes, only once every step that can reject the transaction has succeeded. The writes cannot be
+	// rolled back individually, so a failure part-way through would leave the working state inconsistent with the
+	// transaction's result: the node is stopped instead, and the block is replayed from the last commit on restart.
+	for _, event := range toStore {
+		if err := app.Repository.Store(event, req.Height); err != nil {
+			app.logger.Fatal("Got error storing event", zap.Error(err), zap.Stringer("event_id", event.Metadata.EventId))
+		}
+	}
+
+	for _, watermark := range tracking.watermarks {
+		if err := app.Repository.SetWatermark(watermark); err != nil {
+			app.logger.Fatal("Got error storing watermark", zap.Error(err), zap.String("channel", watermark.Channel))
 		}
-	default:
-		return multiplexer.NewErrorResponse(
-			rpc.CodeUnknownRequestType,
-			rpc.Codespace,
-			fmt.Errorf("unknown request type: %s", decoded.Type),
-		).IntoFinalizeBlockResponse()
+	}
+
+	app.txState.creating = append(app.txState.creating, eventIds...)
+	app.txState.missingRecords += tracking.missingRecords
+	app.txState.regressions += tracking.regressions
+
+	return multiplexer.FinalizeBlockResponse{
+		TxResult: abci.ExecTxResult{
+			Code:      types.CodeOk,
+			Data:      responseMarshalled,
+			Log:       fmt.Sprintf("%d event(s) stored", len(toStore)),
+			Events:    append(abciEvents, tracking.events...),
+			Codespace: types.Codespace,
+		},
 	}
 }
 
-// /event-by-id/{event_id} where event_id is a hex encoded sha256 hash
-var pathRegex = regexp.MustCompile(`^/event-by-id/([a-f0-9]{64})$`)
+var (
+	// /event-by-id/{event_id} where event_id is a hex encoded sha256 hash
+	pathRegex = regexp.MustCompile(`^/event-by-id/([a-f0-9]{64})$`)
+	// /events-by-principal/{principal}
+	principalPathRegex = regexp.MustCompile(`^` + types.QueryPathEventsByPrincipal + `(.+)$`)
+	// /events-by-height/{low}/{high}
+	heightPathRegex = regexp.MustCompile(`^` + types.QueryPathEventsByHeight + `(\d+)/(\d+)$`)
+)
 
 func (app *EventsApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
+	reader, errRes := app.reader(ctx, req)
+	if errRes != nil {
+		return errRes.IntoQueryResponse(), nil
+	}
+
+	if strings.HasPrefix(req.Path, types.QueryPathEventsByPrincipal) || strings.HasPrefix(req.Path, types.QueryPathEventsByHeight) {
+		return app.queryList(req, reader)
+	}
+
+	if strings.HasPrefix(req.Path, types.QueryPathRedactionsByHeight) {
+		return app.queryRedactionList(req, reader)
+	}
+
+	if strings.HasPrefix(req.Path, types.QueryPathRedaction) {
+		return app.queryRedaction(req, reader)
+	}
+
+	if strings.HasPrefix(req.Path, types.QueryPathWatermarks) {
+		return app.queryWatermarks(req, reader)
+	}
+
 	if req.Path == "/count" {
-		count, err := app.Repository.EventCount()
+		count, err := reader.EventCount()
 		if err != nil {
 			app.logger.Error("Got error getting event count", zap.Error(err))
 			return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
@@ -276,7 +556,7 @@ func (app *EventsApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.
 
 	eventId := types.EventHash(eventIdRaw)
 
-	ev, err := app.Repository.GetWithProof(eventId)
+	ev, err := reader.GetWithProof(eventId)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return multiplexer.NewErrorResponse(types.CodeEventNotFound, types.Codespace, err).IntoQueryResponse(), nil
@@ -324,11 +604,152 @@ func (app *EventsApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.
 	}, nil
 }
 
-func (app *EventsApp) decode(data json.RawMessage) (rpc.SignedPayload, *multiplexer.ErrorResponse) {
+// reader returns the Reader for the saved version resolved by the multiplexer for the height of the query, or the
+// working tree if no version was resolved.
+func (app *EventsApp) reader(ctx context.Context, req *abci.RequestQuery) (Reader, *multiplexer.ErrorResponse) {
+	version, ok := multiplexer.VersionFromContext(ctx)
+	if !ok {
+		return app.Repository, nil
+	}
+
+	height := req.Height
+	if height == 0 {
+		height = multiplexer.HeightFromContext(ctx)
+	}
+
+	reader, err := app.Repository.ReaderAt(version, height)
+	if err != nil {
+		if errors.Is(err, proof.ErrTreeUninitialized) {
+			return nil, multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err)
+		} else if errors.Is(err, datastore.ErrVersionUnavailable) {
+			return nil, multiplexer.NewErrorResponse(multiplexer.CodeHeightUnavailable, multiplexer.Codespace, err)
+		} else {
+			app.logger.Error("Got error reading EventsApp version", zap.Error(err), zap.Int64("version", version))
+			return nil, multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+		}
+	}
+
+	return reader, nil
+}
+
+// queryList handles the paginated event listing queries, which take the form:
+// /events-by-principal/{principal}?from=&to=&limit=&cursor= and /events-by-height/{low}/{high}?limit=&cursor=
+func (app *EventsApp) queryList(req *abci.RequestQuery, reader Reader) (*abci.ResponseQuery, error) {
+	parsed, err := url.Parse(req.Path)
+	if err != nil {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	params := parsed.Query()
+
+	limit, cursor, errRes := parsePage(params)
+	if errRes != nil {
+		return errRes.IntoQueryResponse(), nil
+	}
+
+	var res types.EventListResponse
+	if match := principalPathRegex.FindStringSubmatch(parsed.Path); len(match) == 2 {
+		from, err := parseIntParam(params, types.QueryParamFrom, 0)
+		if err != nil {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err).IntoQueryResponse(), nil
+		}
+
+		to, err := parseIntParam(params, types.QueryParamTo, math.MaxInt64)
+		if err != nil {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err).IntoQueryResponse(), nil
+		}
+
+		if from < 0 || from > to {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, errors.New("invalid height range")).IntoQueryResponse(), nil
+		}
+
+		res, err = reader.ListByPrincipal(identitytypes.Principal(match[1]), from, to, cursor, limit)
+	} else if match := heightPathRegex.FindStringSubmatch(parsed.Path); len(match) == 3 {
+		low, lowErr := strconv.ParseInt(match[1], 10, 64)
+		high, highErr := strconv.ParseInt(match[2], 10, 64)
+		if lowErr != nil || highErr != nil || low > high {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, errors.New("invalid height range")).IntoQueryResponse(), nil
+		}
+
+		res, err = reader.ListByHeight(low, high, cursor, limit)
+	} else {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err).IntoQueryResponse(), nil
+		} else if errors.Is(err, proof.ErrTreeUninitialized) {
+			return multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err).IntoQueryResponse(), nil
+		} else {
+			app.logger.Error("Got error listing events", zap.Error(err), zap.String("path", req.Path))
+			return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+		}
+	}
+
+	marshalled, err := json.Marshal(res)
+	if err != nil {
+		app.logger.Error("Got error marshalling event list", zap.Error(err))
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	return &abci.ResponseQuery{
+		Code:      types.CodeOk,
+		Log:       fmt.Sprintf("%d event(s) listed", len(res.Events)),
+		Height:    req.Height,
+		Value:     marshalled,
+		ProofOps:  &crypto.ProofOps{},
+		Codespace: types.Codespace,
+	}, nil
+}
+
+// parsePage parses the limit and cursor parameters shared by the paginated listing queries.
+func parsePage(params url.Values) (int, []byte, *multiplexer.ErrorResponse) {
+	limit, err := parseIntParam(params, types.QueryParamLimit, types.DefaultPageSize)
+	if err != nil {
+		return 0, nil, multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err)
+	}
+
+	if limit <= 0 || limit > types.MaxPageSize {
+		return 0, nil, multiplexer.NewErrorResponse(
+			types.CodeInvalidQueryParameter,
+			types.Codespace,
+			fmt.Errorf("limit must be between 1 and %d", types.MaxPageSize),
+		)
+	}
+
+	var cursor []byte
+	if raw := params.Get(types.QueryParamCursor); raw != "" {
+		if cursor, err = hex.DecodeString(raw); err != nil {
+			return 0, nil, multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err)
+		}
+	}
+
+	return int(limit), cursor, nil
+}
+
+// parseIntParam parses an optional integer query parameter, returning def if the parameter is absent.
+func parseIntParam(params url.Values, name string, def int64) (int64, error) {
+	raw := params.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter: %w", name, err)
+	}
+
+	return value, nil
+}
+
+// decode decodes the signed payload, and validates its signature against the key that was assigned to the requester at
+// the given block height. A height of 0 validates against the requester's current key.
+func (app *EventsApp) decode(ctx context.Context, data json.RawMessage, height int64) (rpc.SignedPayload, identitytypes.IdentityData, *multiplexer.ErrorResponse) {
 	var payload rpc.SignedPayload
 	if err := json.Unmarshal(data, &payload); err != nil {
 		app.logger.Warn("Got error decoding EventsApp request rpc", zap.Error(err))
-		return rpc.SignedPayload{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
 	}
 
 	requester, err := app.identities.Get(payload.Principal)
@@ -338,17 +759,17 @@ func (app *EventsApp) decode(data json.RawMessage) (rpc.SignedPayload, *multiple
 			zap.Error(err),
 			zap.String("requester", payload.Principal.String()),
 		)
-		return rpc.SignedPayload{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
 	}
 
-	valid, err := payload.ValidateSignature(requester.PublicKey)
+	valid, err := payload.Verify(requester.KeyAt(height), multiplexer.ChainIDFromContext(ctx), types.AppName)
 	if err != nil {
 		app.logger.Warn(
 			"Got error validating EventsApp request signature",
 			zap.Error(err),
 			zap.String("requester", payload.Principal.String()),
 		)
-		return rpc.SignedPayload{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
 	}
 
 	if !valid {
@@ -356,8 +777,17 @@ func (app *EventsApp) decode(data json.RawMessage) (rpc.SignedPayload, *multiple
 			"Got invalid EventsApp request signature",
 			zap.String("requester", payload.Principal.String()),
 		)
-		return rpc.SignedPayload{}, multiplexer.NewErrorResponse(rpc.CodeInvalidSignature, rpc.Codespace, nil)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(rpc.CodeInvalidSignature, rpc.Codespace, nil)
+	}
+
+	if err := identity.CheckStatus(payload.Principal, requester); err != nil {
+		app.logger.Warn(
+			"Got EventsApp request from inactive principal",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("status", requester.Status.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, err
 	}
 
-	return payload, nil
+	return payload, requester, nil
 }
@@ -0,0 +1,107 @@
+package events_test
+
+import (
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	upgradetypes "github.com/RyanW02/wineventchain/common/pkg/types/upgrade"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// Malformed events must be rejected with a code identifying the violation, and must not consume a sequence number
+func TestEventValidation(t *testing.T) {
+	chain := harness.NewAtVersion(t, eventtypes.ValidationAppVersion)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	invalidHash := harness.TestEvent(1)
+	invalidHash.OffChainHash = "not-a-hash"
+	missingChannel := harness.TestEvent(2)
+	missingChannel.Event.System.Channel = ""
+	missingProvider := harness.TestEvent(3)
+	missingProvider.Event.System.Provider = eventtypes.Provider{}
+	invalidEventId := harness.TestEvent(4)
+	invalidEventId.Event.System.EventId = -1
+
+	res := chain.Block(
+		harness.EventTx(t, agent, 0, invalidHash),
+		harness.EventTx(t, agent, 0, missingChannel),
+		harness.EventTx(t, agent, 0, missingProvider),
+		harness.EventTx(t, agent, 0, invalidEventId),
+		harness.CreateEventTx(t, agent, 0, 5),
+	)
+	harness.RequireCodes(t, res,
+		eventtypes.CodeInvalidOffChainHash,
+		eventtypes.CodeMissingChannel,
+		eventtypes.CodeMissingProvider,
+		eventtypes.CodeInvalidEventId,
+		eventtypes.CodeOk,
+	)
+
+	// Events created too long before or after the block that stores them are rejected
+	blockTime := harness.GenesisTime.Add(time.Duration(chain.Height+1) * time.Second)
+	early, late, withinLimit := harness.TestEvent(6), harness.TestEvent(7), harness.TestEvent(8)
+	early.Event.System.TimeCreated.SystemTime = blockTime.Add(-eventtypes.MaxBlockTimeSkew - time.Second)
+	late.Event.System.TimeCreated.SystemTime = blockTime.Add(eventtypes.MaxBlockTimeSkew + time.Second)
+	withinLimit.Event.System.TimeCreated.SystemTime = blockTime.Add(-eventtypes.MaxBlockTimeSkew)
+
+	res = chain.Block(
+		harness.EventTx(t, agent, 1, early),
+		harness.EventTx(t, agent, 1, late),
+		harness.EventTx(t, agent, 1, withinLimit),
+	)
+	harness.RequireCodes(t, res, eventtypes.CodeTimeSkew, eventtypes.CodeTimeSkew, eventtypes.CodeOk)
+
+	// Restart with the local skew check enabled, which keeps events skewed from the node's clock out of the mempool
+	chain.MaxClockSkew = time.Hour
+	chain.Start()
+
+	skewed, current := harness.TestEvent(9), harness.TestEvent(10)
+	skewed.Event.System.TimeCreated.SystemTime = time.Now().Add(-2 * time.Hour)
+	current.Event.System.TimeCreated.SystemTime = time.Now()
+
+	checked := chain.CheckTx(harness.EventTx(t, agent, 2, skewed))
+	require.Equal(t, eventtypes.CodeTimeSkew, checked.Code, checked.Log)
+	checked = chain.CheckTx(harness.EventTx(t, agent, 2, current))
+	require.Equal(t, eventtypes.CodeOk, checked.Code, checked.Log)
+
+	// The local check does not apply to blocks, which accept events far from the node's clock but within the limit on
+	// the skew from the block time
+	res = chain.Block(harness.CreateEventTx(t, agent, 2, 11))
+	harness.RequireCodes(t, res, eventtypes.CodeOk)
+}
+
+// Chains at app versions before validation was introduced must continue to accept malformed events, so that their
+// blocks replay to the same results
+func TestEventValidationVersion(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	missingChannel := harness.TestEvent(1)
+	missingChannel.Event.System.Channel = ""
+
+	// CheckTx does not decide the result of a block, so keeps malformed events out of the mempool at every version
+	checked := chain.CheckTx(harness.EventTx(t, agent, 0, missingChannel))
+	require.Equal(t, eventtypes.CodeMissingChannel, checked.Code, checked.Log)
+
+	res := chain.Block(harness.EventTx(t, agent, 0, missingChannel))
+	harness.RequireCodes(t, res, eventtypes.CodeOk)
+
+	// Once upgraded, the same event is rejected
+	plan := upgradetypes.Plan{Name: "v2", Height: 4, Version: eventtypes.ValidationAppVersion}
+	chain.Upgrades = []multiplexer.Upgrade{{Height: plan.Height, Version: plan.Version}}
+	chain.Start()
+
+	missingChannel = harness.TestEvent(2)
+	missingChannel.Event.System.Channel = ""
+
+	chain.Block(harness.ScheduleUpgradeTx(t, admin, 1, plan))
+	res = chain.Block(harness.EventTx(t, agent, 1, missingChannel))
+	harness.RequireCodes(t, res, eventtypes.CodeMissingChannel)
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"math"
+	"strconv"
+)
+
+// Events are stored in the tree under their raw 32 byte ID. Alongside them, the tree holds secondary index entries,
+// which map to the ID of the indexed event, a count of the stored events, redaction orders, which are stored under
+// events.RedactionTreeKey and indexed by height, and the record watermarks stored under events.WatermarkTreeKey. None
+// of these keys are 32 bytes long, so they cannot collide with an event ID. The keys are written at every app version,
+// so the blocks of a chain started by a release that predates them cannot be replayed to the same app hashes: such a
+// chain is migrated to a new chain through its exported genesis state instead.
+//
+// Heights are zero-padded so that index entries are iterated in ascending height order. Principals are hex encoded, so
+// that a principal containing the separator cannot match the entries of another principal.
+const (
+	principalIndexPrefix       = "principal/"
+	heightIndexPrefix          = "height/"
+	redactionHeightIndexPrefix = "redaction_height/"
+	heightLength               = 20
+)
+
+var countKey = []byte("count")
+
+func principalPrefix(principal identity.Principal) string {
+	return fmt.Sprintf("%s%s/", principalIndexPrefix, hex.EncodeToString(principal.Bytes()))
+}
+
+func principalIndexKey(principal identity.Principal, height int64, eventId events.EventHash) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", principalPrefix(principal), height, eventId.String()))
+}
+
+func heightIndexKey(height int64, eventId events.EventHash) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", heightIndexPrefix, height, eventId.String()))
+}
+
+func redactionHeightIndexKey(height int64, eventId events.EventHash) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%s", redactionHeightIndexPrefix, height, eventId.String()))
+}
+
+// indexRange returns the keys bounding the index entries under prefix between the given heights (inclusive).
+func indexRange(prefix string, from, to int64) ([]byte, []byte) {
+	start := []byte(fmt.Sprintf("%s%020d/", prefix, from))
+	if to == math.MaxInt64 {
+		return start, prefixEnd([]byte(prefix))
+	}
+
+	return start, []byte(fmt.Sprintf("%s%020d/", prefix, to+1))
+}
+
+// indexHeight extracts the height from an index entry key under the given prefix.
+func indexHeight(prefix string, key []byte) (int64, error) {
+	if len(key) < len(prefix)+heightLength {
+		return 0, fmt.Errorf("malformed index key: %x", key)
+	}
+
+	return strconv.ParseInt(string(key[len(prefix):len(prefix)+heightLength]), 10, 64)
+}
+
+// prefixEnd returns the first key that sorts after every key with the given prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+func encodeCount(count uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, count)
+}
@@ -1,9 +1,16 @@
 package events
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
 	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	"github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/cometbft/cometbft/libs/sync"
 	"github.com/cosmos/iavl"
 	"github.com/pkg/errors"
@@ -17,7 +24,8 @@ type MerkleRepository struct {
 var _ Repository = (*MerkleRepository)(nil)
 
 var (
-	ErrNotFound = errors.New("event not found")
+	ErrNotFound      = errors.New("event not found")
+	ErrInvalidCursor = errors.New("cursor is outside of the queried range")
 )
 
 func NewMerkleRepository(tree *iavl.MutableTree) *MerkleRepository {
@@ -42,6 +50,14 @@ func (r *MerkleRepository) LoadVersion(version int64) error {
 	return err
 }
 
+func (r *MerkleRepository) LoadVersionForOverwriting(version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.tree.LoadVersionForOverwriting(version)
+	return err
+}
+
 func (r *MerkleRepository) Rollback() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -56,6 +72,13 @@ func (r *MerkleRepository) Hash() ([]byte, error) {
 	return r.tree.Hash()
 }
 
+func (r *MerkleRepository) WorkingHash() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.WorkingHash()
+}
+
 func (r *MerkleRepository) Save() ([]byte, int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -63,7 +86,210 @@ func (r *MerkleRepository) Save() ([]byte, int64, error) {
 	return r.tree.SaveVersion()
 }
 
+func (r *MerkleRepository) Version() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Version()
+}
+
+func (r *MerkleRepository) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Size()
+}
+
+func (r *MerkleRepository) AvailableVersions() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.AvailableVersions(r.tree)
+}
+
+func (r *MerkleRepository) DeleteVersions(versions ...int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.DeleteVersions(r.tree, versions)
+}
+
+func (r *MerkleRepository) Export() (datastore.TreeSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ExportTree(r.tree)
+}
+
+func (r *MerkleRepository) Import(snapshot datastore.TreeSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ImportTree(r.tree, snapshot)
+}
+
+func (r *MerkleRepository) ExportGenesis() ([]datastore.GenesisEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ExportGenesis(r.tree)
+}
+
+func (r *MerkleRepository) ImportGenesis(entries []datastore.GenesisEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ImportGenesis(r.tree, entries)
+}
+
 func (r *MerkleRepository) GetByEventId(id events.EventHash) (events.EventWithMetadata, error) {
+	return r.reader().GetByEventId(id)
+}
+
+func (r *MerkleRepository) GetWithProof(id events.EventHash) (proof.ItemWithProof[events.EventWithMetadata], error) {
+	return r.reader().GetWithProof(id)
+}
+
+func (r *MerkleRepository) EventCount() (uint64, error) {
+	return r.reader().EventCount()
+}
+
+func (r *MerkleRepository) ListByPrincipal(
+	principal identity.Principal,
+	from, to int64,
+	cursor []byte,
+	limit int,
+) (events.EventListResponse, error) {
+	return r.reader().ListByPrincipal(principal, from, to, cursor, limit)
+}
+
+func (r *MerkleRepository) ListByHeight(from, to int64, cursor []byte, limit int) (events.EventListResponse, error) {
+	return r.reader().ListByHeight(from, to, cursor, limit)
+}
+
+func (r *MerkleRepository) GetRedactionWithProof(id events.EventHash) (proof.ItemWithProof[events.Redaction], error) {
+	return r.reader().GetRedactionWithProof(id)
+}
+
+func (r *MerkleRepository) ListRedactions(from, to int64, cursor []byte, limit int) (events.RedactionListResponse, error) {
+	return r.reader().ListRedactions(from, to, cursor, limit)
+}
+
+func (r *MerkleRepository) GetWatermark(principal identity.Principal, channel string) (*events.RecordWatermark, error) {
+	return r.reader().GetWatermark(principal, channel)
+}
+
+func (r *MerkleRepository) ListWatermarks(principal identity.Principal, cursor []byte, limit int) (events.WatermarkListResponse, error) {
+	return r.reader().ListWatermarks(principal, cursor, limit)
+}
+
+func (r *MerkleRepository) ReaderAt(version, height int64) (Reader, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tree, err := datastore.TreeAt(r.tree, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return merkleReader{
+		tree: tree,
+		mu:   &r.mu,
+		height: func() int64 {
+			return height
+		},
+	}, nil
+}
+
+// reader returns a Reader for the working tree.
+func (r *MerkleRepository) reader() merkleReader {
+	return merkleReader{
+		tree: r.tree,
+		mu:   &r.mu,
+		height: func() int64 {
+			return proof.GetProofHeight(r.tree)
+		},
+	}
+}
+
+func (r *MerkleRepository) Store(event events.EventWithMetadata, height int64) error {
+	marshalled, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count, err := r.reader().eventCount()
+	if err != nil {
+		return err
+	}
+
+	eventId := event.Metadata.EventId
+
+	updated, err := r.tree.Set(eventId, marshalled)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.tree.Set(principalIndexKey(event.Metadata.Principal, height, eventId), eventId); err != nil {
+		return err
+	}
+
+	if _, err := r.tree.Set(heightIndexKey(height, eventId), eventId); err != nil {
+		return err
+	}
+
+	// Overwriting an existing event does not change the count
+	if !updated {
+		count++
+	}
+
+	_, err = r.tree.Set(countKey, encodeCount(count))
+	return err
+}
+
+func (r *MerkleRepository) Redact(redaction events.Redaction) error {
+	marshalled, err := json.Marshal(redaction)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.tree.Set(events.RedactionTreeKey(redaction.EventId), marshalled); err != nil {
+		return err
+	}
+
+	_, err = r.tree.Set(redactionHeightIndexKey(redaction.Height, redaction.EventId), redaction.EventId)
+	return err
+}
+
+func (r *MerkleRepository) SetWatermark(watermark events.RecordWatermark) error {
+	marshalled, err := json.Marshal(watermark)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.tree.Set(events.WatermarkTreeKey(watermark.Principal, watermark.Channel), marshalled)
+	return err
+}
+
+// merkleReader reads from a single version of the events tree, sharing the repository's lock.
+type merkleReader struct {
+	tree   datastore.ReadableTree
+	mu     *sync.Mutex
+	height func() int64
+}
+
+var _ Reader = merkleReader{}
+
+func (r merkleReader) GetByEventId(id events.EventHash) (events.EventWithMetadata, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -84,7 +310,7 @@ func (r *MerkleRepository) GetByEventId(id events.EventHash) (events.EventWithMe
 	return event, nil
 }
 
-func (r *MerkleRepository) GetWithProof(id events.EventHash) (proof.ItemWithProof[events.EventWithMetadata], error) {
+func (r merkleReader) GetWithProof(id events.EventHash) (proof.ItemWithProof[events.EventWithMetadata], error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -104,7 +330,7 @@ func (r *MerkleRepository) GetWithProof(id events.EventHash) (proof.ItemWithProo
 		return proof.ItemWithProof[events.EventWithMetadata]{
 			Item:    nil,
 			Index:   index,
-			Height:  proof.GetProofHeight(r.tree),
+			Height:  r.height(),
 			ProofOp: proofOp,
 		}, nil
 	}
@@ -117,32 +343,335 @@ func (r *MerkleRepository) GetWithProof(id events.EventHash) (proof.ItemWithProo
 	return proof.ItemWithProof[events.EventWithMetadata]{
 		Item:    &event,
 		Index:   index,
-		Height:  proof.GetProofHeight(r.tree),
+		Height:  r.height(),
 		ProofOp: proofOp,
 	}, nil
 }
 
-func (r *MerkleRepository) EventCount() (uint64, error) {
+func (r merkleReader) EventCount() (uint64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	size := r.tree.Size()
-	if size < 0 {
-		return 0, errors.New("negative size")
-	} else {
-		return uint64(size), nil
+	return r.eventCount()
+}
+
+func (r merkleReader) ListByPrincipal(
+	principal identity.Principal,
+	from, to int64,
+	cursor []byte,
+	limit int,
+) (events.EventListResponse, error) {
+	prefix := principalPrefix(principal)
+	start, end := indexRange(prefix, from, to)
+	return r.list(prefix, start, end, cursor, limit)
+}
+
+func (r merkleReader) ListByHeight(from, to int64, cursor []byte, limit int) (events.EventListResponse, error) {
+	start, end := indexRange(heightIndexPrefix, from, to)
+	return r.list(heightIndexPrefix, start, end, cursor, limit)
+}
+
+// list returns the events referenced by up to limit index entries between start (inclusive) and end (exclusive), with
+// proofs of both the index entries and the events.
+func (r merkleReader) list(prefix string, start, end, cursor []byte, limit int) (events.EventListResponse, error) {
+	if cursor != nil {
+		if bytes.Compare(cursor, start) < 0 || bytes.Compare(cursor, end) >= 0 {
+			return events.EventListResponse{}, ErrInvalidCursor
+		}
+
+		start = cursor
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	iterator, err := r.tree.Iterator(start, end, true)
+	if err != nil {
+		return events.EventListResponse{}, err
+	}
+
+	// Fetch one extra entry, to determine the cursor of the next page
+	var keys, eventIds [][]byte
+	for ; iterator.Valid() && len(keys) <= limit; iterator.Next() {
+		keys = append(keys, iterator.Key())
+		eventIds = append(eventIds, iterator.Value())
+	}
+
+	if err := iterator.Error(); err != nil {
+		iterator.Close()
+		return events.EventListResponse{}, err
+	}
+
+	if err := iterator.Close(); err != nil {
+		return events.EventListResponse{}, err
+	}
+
+	res := events.EventListResponse{
+		Events: make([]events.IndexedEvent, 0, utils.Min(len(keys), limit)),
+	}
+
+	if len(keys) > limit {
+		res.Next = hex.EncodeToString(keys[limit])
+		keys, eventIds = keys[:limit], eventIds[:limit]
+	}
+
+	for i, key := range keys {
+		height, err := indexHeight(prefix, key)
+		if err != nil {
+			return events.EventListResponse{}, err
+		}
+
+		eventBytes, err := r.tree.Get(eventIds[i])
+		if err != nil {
+			return events.EventListResponse{}, err
+		}
+
+		if eventBytes == nil {
+			return events.EventListResponse{}, fmt.Errorf("%w: index entry %s references missing event", ErrNotFound, key)
+		}
+
+		var event events.EventWithMetadata
+		if err := json.Unmarshal(eventBytes, &event); err != nil {
+			return events.EventListResponse{}, err
+		}
+
+		indexProof, err := proof.ProofOpForTree(r.tree, key)
+		if err != nil {
+			return events.EventListResponse{}, err
+		}
+
+		eventProof, err := proof.ProofOpForTree(r.tree, eventIds[i])
+		if err != nil {
+			return events.EventListResponse{}, err
+		}
+
+		res.Events = append(res.Events, events.IndexedEvent{
+			Event:      event,
+			Height:     height,
+			IndexKey:   key,
+			IndexProof: indexProof,
+			EventProof: eventProof,
+		})
 	}
+
+	return res, nil
 }
 
-func (r *MerkleRepository) Store(event events.EventWithMetadata) error {
-	marshalled, err := json.Marshal(event)
+func (r merkleReader) GetRedactionWithProof(id events.EventHash) (proof.ItemWithProof[events.Redaction], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := events.RedactionTreeKey(id)
+
+	index, bytes, err := r.tree.GetWithIndex(key)
 	if err != nil {
-		return err
+		return proof.ItemWithProof[events.Redaction]{}, err
+	}
+
+	proofOp, err := proof.ProofOpForTree(r.tree, key)
+	if err != nil {
+		return proof.ItemWithProof[events.Redaction]{}, err
+	}
+
+	item := proof.ItemWithProof[events.Redaction]{
+		Index:   index,
+		Height:  r.height(),
+		ProofOp: proofOp,
+	}
+
+	if bytes != nil {
+		var redaction events.Redaction
+		if err := json.Unmarshal(bytes, &redaction); err != nil {
+			return proof.ItemWithProof[events.Redaction]{}, err
+		}
+
+		item.Item = &redaction
+	}
+
+	return item, nil
+}
+
+func (r merkleReader) ListRedactions(from, to int64, cursor []byte, limit int) (events.RedactionListResponse, error) {
+	start, end := indexRange(redactionHeightIndexPrefix, from, to)
+	if cursor != nil {
+		if bytes.Compare(cursor, start) < 0 || bytes.Compare(cursor, end) >= 0 {
+			return events.RedactionListResponse{}, ErrInvalidCursor
+		}
+
+		start = cursor
 	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, err = r.tree.Set(event.Metadata.EventId, marshalled)
-	return err
+	iterator, err := r.tree.Iterator(start, end, true)
+	if err != nil {
+		return events.RedactionListResponse{}, err
+	}
+
+	// Fetch one extra entry, to determine the cursor of the next page
+	var keys, eventIds [][]byte
+	for ; iterator.Valid() && len(keys) <= limit; iterator.Next() {
+		keys = append(keys, iterator.Key())
+		eventIds = append(eventIds, iterator.Value())
+	}
+
+	if err := iterator.Error(); err != nil {
+		iterator.Close()
+		return events.RedactionListResponse{}, err
+	}
+
+	if err := iterator.Close(); err != nil {
+		return events.RedactionListResponse{}, err
+	}
+
+	res := events.RedactionListResponse{
+		Redactions: make([]events.ProvenRedaction, 0, utils.Min(len(keys), limit)),
+	}
+
+	if len(keys) > limit {
+		res.Next = hex.EncodeToString(keys[limit])
+		keys, eventIds = keys[:limit], eventIds[:limit]
+	}
+
+	for i, key := range keys {
+		redactionKey := events.RedactionTreeKey(eventIds[i])
+
+		redactionBytes, err := r.tree.Get(redactionKey)
+		if err != nil {
+			return events.RedactionListResponse{}, err
+		}
+
+		if redactionBytes == nil {
+			return events.RedactionListResponse{}, fmt.Errorf("%w: index entry %s references missing redaction", ErrNotFound, key)
+		}
+
+		var redaction events.Redaction
+		if err := json.Unmarshal(redactionBytes, &redaction); err != nil {
+			return events.RedactionListResponse{}, err
+		}
+
+		redactionProof, err := proof.ProofOpForTree(r.tree, redactionKey)
+		if err != nil {
+			return events.RedactionListResponse{}, err
+		}
+
+		res.Redactions = append(res.Redactions, events.ProvenRedaction{
+			Redaction: redaction,
+			Key:       redactionKey,
+			Proof:     redactionProof,
+		})
+	}
+
+	return res, nil
+}
+
+func (r merkleReader) GetWatermark(principal identity.Principal, channel string) (*events.RecordWatermark, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bytes, err := r.tree.Get(events.WatermarkTreeKey(principal, channel))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes == nil {
+		return nil, nil
+	}
+
+	var watermark events.RecordWatermark
+	if err := json.Unmarshal(bytes, &watermark); err != nil {
+		return nil, err
+	}
+
+	return &watermark, nil
+}
+
+func (r merkleReader) ListWatermarks(principal identity.Principal, cursor []byte, limit int) (events.WatermarkListResponse, error) {
+	prefix := []byte(events.WatermarkTreeKeyPrefix)
+	if principal != "" {
+		prefix = []byte(events.WatermarkPrincipalPrefix(principal))
+	}
+
+	start, end := prefix, prefixEnd(prefix)
+	if cursor != nil {
+		if bytes.Compare(cursor, start) < 0 || bytes.Compare(cursor, end) >= 0 {
+			return events.WatermarkListResponse{}, ErrInvalidCursor
+		}
+
+		start = cursor
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	iterator, err := r.tree.Iterator(start, end, true)
+	if err != nil {
+		return events.WatermarkListResponse{}, err
+	}
+
+	// Fetch one extra entry, to determine the cursor of the next page
+	var keys, values [][]byte
+	for ; iterator.Valid() && len(keys) <= limit; iterator.Next() {
+		keys = append(keys, iterator.Key())
+		values = append(values, iterator.Value())
+	}
+
+	if err := iterator.Error(); err != nil {
+		iterator.Close()
+		return events.WatermarkListResponse{}, err
+	}
+
+	if err := iterator.Close(); err != nil {
+		return events.WatermarkListResponse{}, err
+	}
+
+	res := events.WatermarkListResponse{
+		Watermarks: make([]events.ProvenWatermark, 0, utils.Min(len(keys), limit)),
+	}
+
+	if len(keys) > limit {
+		res.Next = hex.EncodeToString(keys[limit])
+		keys, values = keys[:limit], values[:limit]
+	}
+
+	for i, key := range keys {
+		var watermark events.RecordWatermark
+		if err := json.Unmarshal(values[i], &watermark); err != nil {
+			return events.WatermarkListResponse{}, err
+		}
+
+		watermarkProof, err := proof.ProofOpForTree(r.tree, key)
+		if err != nil {
+			return events.WatermarkListResponse{}, err
+		}
+
+		res.Watermarks = append(res.Watermarks, events.ProvenWatermark{
+			Watermark: watermark,
+			Key:       key,
+			Proof:     watermarkProof,
+		})
+	}
+
+	return res, nil
+}
+
+// eventCount returns the number of stored events. Trees written before the count was recorded contain only events,
+// so their size is the count. Must be called with the lock held.
+func (r merkleReader) eventCount() (uint64, error) {
+	encoded, err := r.tree.Get(countKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if encoded != nil {
+		return binary.BigEndian.Uint64(encoded), nil
+	}
+
+	size := r.tree.Size()
+	if size < 0 {
+		return 0, errors.New("negative size")
+	} else {
+		return uint64(size), nil
+	}
 }
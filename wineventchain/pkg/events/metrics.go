@@ -0,0 +1,47 @@
+package events
+
+import (
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ multiplexer.MetricsApp = (*EventsApp)(nil)
+var _ multiplexer.SizedApp = (*EventsApp)(nil)
+
+// metrics are updated when a block is committed, so that each event is only counted once.
+type metrics struct {
+	created        prometheus.Counter
+	missingRecords prometheus.Counter
+	regressions    prometheus.Counter
+}
+
+func newMetrics() metrics {
+	return metrics{
+		created: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: multiplexer.MetricsNamespace,
+			Subsystem: "events",
+			Name:      "created_total",
+			Help:      "Number of events stored on-chain.",
+		}),
+		missingRecords: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: multiplexer.MetricsNamespace,
+			Subsystem: "events",
+			Name:      "missing_records_total",
+			Help:      "Number of event record IDs skipped by the events stored from each principal's channels.",
+		}),
+		regressions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: multiplexer.MetricsNamespace,
+			Subsystem: "events",
+			Name:      "record_regressions_total",
+			Help:      "Number of stored events whose record ID did not follow on from the previous event of the channel.",
+		}),
+	}
+}
+
+func (app *EventsApp) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{app.metrics.created, app.metrics.missingRecords, app.metrics.regressions}
+}
+
+func (app *EventsApp) Size() int64 {
+	return app.Repository.Size()
+}
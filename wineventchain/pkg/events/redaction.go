@@ -0,0 +1,245 @@
+package events
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"go.uber.org/zap"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// /redaction/{event_id} where event_id is a hex encoded sha256 hash
+	redactionPathRegex = regexp.MustCompile(`^` + types.QueryPathRedaction + `([a-f0-9]{64})$`)
+	// /redactions-by-height/{low}/{high}
+	redactionHeightPathRegex = regexp.MustCompile(`^` + types.QueryPathRedactionsByHeight + `(\d+)/(\d+)$`)
+)
+
+// decodeRedaction decodes a redact request, and checks that every event it targets exists and has not already been
+// redacted, including by requests earlier in the current block.
+func (app *EventsApp) decodeRedaction(decoded rpc.SignedPayload) (types.RedactRequest, *multiplexer.ErrorResponse) {
+	var request types.RedactRequest
+	if err := json.Unmarshal(decoded.Data, &request); err != nil {
+		app.logger.Warn("Got error decoding EventsApp redact payload", zap.Error(err))
+		return types.RedactRequest{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+	}
+
+	if len(request.EventIds) == 0 || len(request.EventIds) > types.MaxBatchSize {
+		return types.RedactRequest{}, multiplexer.NewErrorResponse(
+			types.CodeInvalidBatchSize,
+			types.Codespace,
+			fmt.Errorf("redaction must target between 1 and %d events, got %d", types.MaxBatchSize, len(request.EventIds)),
+		)
+	}
+
+	if len(request.Reason) == 0 || len(request.Reason) > types.MaxRedactionReasonLength {
+		return types.RedactRequest{}, multiplexer.NewErrorResponse(
+			types.CodeInvalidRedaction,
+			types.Codespace,
+			fmt.Errorf("reason must be between 1 and %d bytes long", types.MaxRedactionReasonLength),
+		)
+	}
+
+	seen := make(map[string]struct{}, len(request.EventIds))
+	for _, eventId := range request.EventIds {
+		if _, ok := seen[eventId.String()]; ok {
+			return types.RedactRequest{}, multiplexer.NewErrorResponse(
+				types.CodeInvalidRedaction,
+				types.Codespace,
+				fmt.Errorf("event %s is targeted more than once", eventId),
+			)
+		}
+
+		seen[eventId.String()] = struct{}{}
+
+		if _, err := app.Repository.GetByEventId(eventId); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return types.RedactRequest{}, multiplexer.NewErrorResponse(types.CodeEventNotFound, types.Codespace, fmt.Errorf("event %s not found", eventId))
+			}
+
+			app.logger.Error("Got error getting event to redact", zap.Error(err), zap.Stringer("event_id", eventId))
+			return types.RedactRequest{}, multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+		}
+
+		existing, err := app.Repository.GetRedactionWithProof(eventId)
+		if err != nil {
+			app.logger.Error("Got error getting existing redaction", zap.Error(err), zap.Stringer("event_id", eventId))
+			return types.RedactRequest{}, multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+		}
+
+		if existing.Item != nil {
+			return types.RedactRequest{}, multiplexer.NewErrorResponse(
+				types.CodeAlreadyRedacted,
+				types.Codespace,
+				fmt.Errorf("event %s was already %s", eventId, existing.Item),
+			)
+		}
+	}
+
+	return request, nil
+}
+
+// redactEvents records a redaction order for each of the events targeted by the request. Off-chain nodes act upon the
+// recorded orders by erasing the data of the events.
+func (app *EventsApp) redactEvents(
+	req *abci.RequestFinalizeBlock,
+	decoded rpc.SignedPayload,
+	request types.RedactRequest,
+) multiplexer.FinalizeBlockResponse {
+	redactions := make([]types.Redaction, len(request.EventIds))
+	abciEvents := make([]abci.Event, len(request.EventIds))
+	for i, eventId := range request.EventIds {
+		redactions[i] = types.Redaction{
+			EventId: eventId,
+			Reason:  request.Reason,
+			Author:  decoded.Principal,
+			Height:  req.Height,
+		}
+
+		if err := app.Repository.Redact(redactions[i]); err != nil {
+			app.logger.Warn("Got error storing redaction", zap.Error(err), zap.Stringer("event_id", eventId))
+			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		abciEvents[i] = abci.Event{
+			Type: types.EventRedact,
+			Attributes: []abci.EventAttribute{
+				{Key: types.AttributeType, Value: types.AttributeValueRedact, Index: true},
+				{Key: types.AttributeEventId, Value: eventId.String(), Index: true},
+				{Key: types.AttributePrincipal, Value: decoded.Principal.String(), Index: true},
+			},
+		}
+	}
+
+	app.logger.Info(
+		"Recorded redaction order",
+		zap.Int("count", len(redactions)),
+		zap.String("author", decoded.Principal.String()),
+		zap.String("reason", request.Reason),
+	)
+
+	res, err := json.Marshal(types.RedactResponse{Redactions: redactions})
+	if err != nil {
+		return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	return multiplexer.FinalizeBlockResponse{
+		TxResult: abci.ExecTxResult{
+			Code:      types.CodeOk,
+			Data:      res,
+			Log:       fmt.Sprintf("%d event(s) redacted", len(redactions)),
+			Events:    abciEvents,
+			Codespace: types.Codespace,
+		},
+	}
+}
+
+// queryRedaction returns the redaction order recorded for an event, with a proof of its presence or absence:
+// /redaction/{event_id}
+func (app *EventsApp) queryRedaction(req *abci.RequestQuery, reader Reader) (*abci.ResponseQuery, error) {
+	match := redactionPathRegex.FindStringSubmatch(req.Path)
+	if len(match) != 2 {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+
+	eventIdRaw, err := hex.DecodeString(match[1])
+	if err != nil { // *Should* be infallible
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+
+	eventId := types.EventHash(eventIdRaw)
+
+	redaction, err := reader.GetRedactionWithProof(eventId)
+	if err != nil {
+		if errors.Is(err, proof.ErrTreeUninitialized) {
+			return multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err).IntoQueryResponse(), nil
+		}
+
+		app.logger.Error("Got error getting redaction with proof", zap.Error(err), zap.Stringer("event_id", eventId))
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	res := &abci.ResponseQuery{
+		Code:      types.CodeOk,
+		Log:       "Redaction found",
+		Index:     redaction.Index,
+		Key:       types.RedactionTreeKey(eventId),
+		ProofOps:  redaction.ProofOps(),
+		Height:    req.Height,
+		Codespace: types.Codespace,
+	}
+
+	if redaction.Item == nil {
+		res.Code = types.CodeRedactionNotFound
+		res.Log = "Redaction not found"
+		return res, nil
+	}
+
+	if res.Value, err = json.Marshal(redaction.Item); err != nil {
+		app.logger.Error("Got error marshalling redaction", zap.Error(err), zap.Stringer("event_id", eventId))
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	return res, nil
+}
+
+// queryRedactionList lists the redaction orders recorded between two heights:
+// /redactions-by-height/{low}/{high}?limit=&cursor=
+func (app *EventsApp) queryRedactionList(req *abci.RequestQuery, reader Reader) (*abci.ResponseQuery, error) {
+	parsed, err := url.Parse(req.Path)
+	if err != nil {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	limit, cursor, errRes := parsePage(parsed.Query())
+	if errRes != nil {
+		return errRes.IntoQueryResponse(), nil
+	}
+
+	match := redactionHeightPathRegex.FindStringSubmatch(parsed.Path)
+	if len(match) != 3 {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+
+	low, lowErr := strconv.ParseInt(match[1], 10, 64)
+	high, highErr := strconv.ParseInt(match[2], 10, 64)
+	if lowErr != nil || highErr != nil || low > high {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, errors.New("invalid height range")).IntoQueryResponse(), nil
+	}
+
+	res, err := reader.ListRedactions(low, high, cursor, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err).IntoQueryResponse(), nil
+		} else if errors.Is(err, proof.ErrTreeUninitialized) {
+			return multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err).IntoQueryResponse(), nil
+		} else {
+			app.logger.Error("Got error listing redactions", zap.Error(err), zap.String("path", req.Path))
+			return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+		}
+	}
+
+	marshalled, err := json.Marshal(res)
+	if err != nil {
+		app.logger.Error("Got error marshalling redaction list", zap.Error(err))
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	return &abci.ResponseQuery{
+		Code:      types.CodeOk,
+		Log:       fmt.Sprintf("%d redaction(s) listed", len(res.Redactions)),
+		Height:    req.Height,
+		Value:     marshalled,
+		ProofOps:  &crypto.ProofOps{},
+		Codespace: types.Codespace,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package events_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Redaction orders may only be recorded once per event by an admin, and must be provable against the app hash
+func TestRedaction(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	res := chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent),
+		harness.CreateEventTx(t, agent, 0, 1),
+		harness.CreateEventTx(t, agent, 1, 2),
+	)
+	harness.RequireCodes(t, res, 0, 0, 0, 0)
+	ids := harness.EventIds(t, res, 2)
+
+	missing := chain.Query(eventtypes.AppName, eventtypes.QueryPathRedaction+ids[0].String())
+	require.Equal(t, eventtypes.CodeRedactionNotFound, missing.Code, missing.Log)
+
+	value, err := proof.VerifyChain(missing.ProofOps, eventtypes.AppName, eventtypes.RedactionTreeKey(ids[0]), res.AppHash, multiplexer.AppVersion)
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	res = chain.Block(
+		harness.RedactTx(t, agent, 2, ids[0]),
+		harness.RedactTx(t, admin, 1, ids[0]),
+		harness.RedactTx(t, admin, 2, ids[0], ids[1]),
+	)
+	require.Equal(t, eventtypes.CodeUnauthorized, res.TxResults[0].Code)
+	require.Equal(t, uint32(0), res.TxResults[1].Code, res.TxResults[1].Log)
+	require.Equal(t, eventtypes.CodeAlreadyRedacted, res.TxResults[2].Code)
+
+	queried := chain.Query(eventtypes.AppName, eventtypes.QueryPathRedaction+ids[0].String())
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	value, err = proof.VerifyChain(queried.ProofOps, eventtypes.AppName, eventtypes.RedactionTreeKey(ids[0]), res.AppHash, multiplexer.AppVersion)
+	require.NoError(t, err)
+	require.Equal(t, queried.Value, value)
+
+	var redaction eventtypes.Redaction
+	require.NoError(t, json.Unmarshal(value, &redaction))
+	require.Equal(t, admin.Name, redaction.Author)
+	require.Equal(t, chain.Height, redaction.Height)
+
+	listed := chain.Query(eventtypes.AppName, fmt.Sprintf("%s%d/%d", eventtypes.QueryPathRedactionsByHeight, 0, chain.Height))
+	require.Equal(t, uint32(0), listed.Code, listed.Log)
+
+	var list eventtypes.RedactionListResponse
+	require.NoError(t, json.Unmarshal(listed.Value, &list))
+	require.Len(t, list.Redactions, 1)
+	require.Equal(t, redaction, list.Redactions[0].Redaction)
+
+	proofOps := proof.ListedProofOps(list.Redactions[0].Proof, listed.ProofOps)
+	value, err = proof.VerifyChain(proofOps, eventtypes.AppName, eventtypes.RedactionTreeKey(ids[0]), res.AppHash, multiplexer.AppVersion)
+	require.NoError(t, err)
+	require.Equal(t, queried.Value, value)
+}
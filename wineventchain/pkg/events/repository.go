@@ -4,12 +4,44 @@ import (
 	"github.com/RyanW02/wineventchain/app/internal/datastore"
 	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	types "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 )
 
-type Repository interface {
-	datastore.BaseRepository
+// Reader reads events from a single version of the events tree.
+type Reader interface {
 	GetByEventId(id types.EventHash) (types.EventWithMetadata, error)
 	GetWithProof(id types.EventHash) (proof.ItemWithProof[types.EventWithMetadata], error)
 	EventCount() (uint64, error)
-	Store(event types.EventWithMetadata) error
+	// ListByPrincipal lists up to limit events submitted by the principal between the given heights (inclusive),
+	// resuming from cursor if non-nil.
+	ListByPrincipal(principal identity.Principal, from, to int64, cursor []byte, limit int) (types.EventListResponse, error)
+	// ListByHeight lists up to limit events stored between the given heights (inclusive), resuming from cursor if
+	// non-nil.
+	ListByHeight(from, to int64, cursor []byte, limit int) (types.EventListResponse, error)
+	// GetRedactionWithProof returns the redaction order recorded for the event, with a proof of its presence or
+	// absence.
+	GetRedactionWithProof(id types.EventHash) (proof.ItemWithProof[types.Redaction], error)
+	// ListRedactions lists up to limit redaction orders recorded between the given heights (inclusive), with a proof
+	// of each, resuming from cursor if non-nil.
+	ListRedactions(from, to int64, cursor []byte, limit int) (types.RedactionListResponse, error)
+	// GetWatermark returns the record watermark of the principal's channel, or nil if the principal has not submitted
+	// any events from the channel.
+	GetWatermark(principal identity.Principal, channel string) (*types.RecordWatermark, error)
+	// ListWatermarks lists up to limit record watermarks of the principal, or of every principal if empty, resuming
+	// from cursor if non-nil.
+	ListWatermarks(principal identity.Principal, cursor []byte, limit int) (types.WatermarkListResponse, error)
+}
+
+type Repository interface {
+	datastore.BaseRepository
+	Reader
+	// ReaderAt returns a Reader for the given saved version of the repository, which reflects the state at the given
+	// block height.
+	ReaderAt(version, height int64) (Reader, error)
+	// Store stores the event, and indexes it by principal and by the height at which it was stored.
+	Store(event types.EventWithMetadata, height int64) error
+	// Redact records the redaction order, and indexes it by the height at which it was ordered.
+	Redact(redaction types.Redaction) error
+	// SetWatermark stores the record watermark of a principal's channel, replacing any existing watermark.
+	SetWatermark(watermark types.RecordWatermark) error
 }
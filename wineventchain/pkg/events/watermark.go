@@ -0,0 +1,187 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"go.uber.org/zap"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// /watermarks/{principal}, where the principal may be omitted to list the watermarks of every principal
+var watermarkPathRegex = regexp.MustCompile(`^` + types.QueryPathWatermarks + `(.*)$`)
+
+// recordTracking is the outcome of tracking the record IDs of the events created by a single transaction.
+type recordTracking struct {
+	// watermarks are the updated watermarks, to be stored alongside the events
+	watermarks []types.RecordWatermark
+	// events contains an abci.Event for each gap or regression in the record IDs
+	events         []abci.Event
+	missingRecords int
+	regressions    int
+}
+
+// trackRecords advances the record watermark of each channel that the events were read from. Events are expected in
+// the order they were read from the channel.
+func (app *EventsApp) trackRecords(
+	principal identity.Principal,
+	height int64,
+	toStore []types.EventWithMetadata,
+) (recordTracking, error) {
+	var tracking recordTracking
+	var channels []string
+	watermarks := make(map[string]*types.RecordWatermark)
+
+	for _, event := range toStore {
+		channel := event.Event.System.Channel
+		recordId := event.Event.System.EventRecordId
+
+		watermark, ok := watermarks[channel]
+		if !ok {
+			existing, err := app.Repository.GetWatermark(principal, channel)
+			if err != nil {
+				return recordTracking{}, err
+			}
+
+			channels = append(channels, channel)
+			watermarks[channel] = existing
+			watermark = existing
+		}
+
+		// The first event from a channel sets its watermark, as there is nothing to compare it against
+		if watermark == nil {
+			watermarks[channel] = &types.RecordWatermark{
+				Principal:    principal,
+				Channel:      channel,
+				LastRecordId: recordId,
+				LastEventId:  event.Metadata.EventId,
+				Height:       height,
+			}
+
+			continue
+		}
+
+		previousRecordId := watermark.LastRecordId
+		missing, regression := watermark.Observe(recordId)
+		watermark.LastEventId = event.Metadata.EventId
+		watermark.Height = height
+
+		if missing > 0 {
+			tracking.missingRecords += missing
+			app.logger.Warn(
+				"Detected gap in event records",
+				zap.String("principal", principal.String()),
+				zap.String("channel", channel),
+				zap.Int("previous_record_id", previousRecordId),
+				zap.Int("record_id", recordId),
+			)
+
+			tracking.events = append(tracking.events, recordEvent(
+				types.EventRecordGap,
+				types.AttributeValueRecordGap,
+				principal,
+				event,
+				previousRecordId,
+				abci.EventAttribute{Key: types.AttributeMissingRecords, Value: strconv.Itoa(missing)},
+			))
+		} else if regression {
+			tracking.regressions++
+			app.logger.Warn(
+				"Detected regression in event records",
+				zap.String("principal", principal.String()),
+				zap.String("channel", channel),
+				zap.Int("previous_record_id", previousRecordId),
+				zap.Int("record_id", recordId),
+			)
+
+			tracking.events = append(tracking.events, recordEvent(
+				types.EventRecordRegression,
+				types.AttributeValueRecordRegression,
+				principal,
+				event,
+				previousRecordId,
+			))
+		}
+	}
+
+	tracking.watermarks = make([]types.RecordWatermark, len(channels))
+	for i, channel := range channels {
+		tracking.watermarks[i] = *watermarks[channel]
+	}
+
+	return tracking, nil
+}
+
+func recordEvent(
+	eventType, attributeValue string,
+	principal identity.Principal,
+	event types.EventWithMetadata,
+	previousRecordId int,
+	extra ...abci.EventAttribute,
+) abci.Event {
+	attributes := []abci.EventAttribute{
+		{Key: types.AttributeType, Value: attributeValue, Index: true},
+		{Key: types.AttributeEventId, Value: event.Metadata.EventId.String(), Index: true},
+		{Key: types.AttributePrincipal, Value: principal.String(), Index: true},
+		{Key: types.AttributeChannel, Value: event.Event.System.Channel, Index: true},
+		{Key: types.AttributeRecordId, Value: strconv.Itoa(event.Event.System.EventRecordId)},
+		{Key: types.AttributePreviousRecordId, Value: strconv.Itoa(previousRecordId)},
+	}
+
+	return utils.Event(eventType, append(attributes, extra...)...)
+}
+
+// queryWatermarks lists the record watermarks of a principal, or of every principal, with a proof of each:
+// /watermarks/{principal}?limit=&cursor=
+func (app *EventsApp) queryWatermarks(req *abci.RequestQuery, reader Reader) (*abci.ResponseQuery, error) {
+	parsed, err := url.Parse(req.Path)
+	if err != nil {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	limit, cursor, errRes := parsePage(parsed.Query())
+	if errRes != nil {
+		return errRes.IntoQueryResponse(), nil
+	}
+
+	match := watermarkPathRegex.FindStringSubmatch(parsed.Path)
+	if len(match) != 2 {
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+
+	res, err := reader.ListWatermarks(identity.Principal(match[1]), cursor, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return multiplexer.NewErrorResponse(types.CodeInvalidQueryParameter, types.Codespace, err).IntoQueryResponse(), nil
+		} else if errors.Is(err, proof.ErrTreeUninitialized) {
+			return multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err).IntoQueryResponse(), nil
+		} else {
+			app.logger.Error("Got error listing watermarks", zap.Error(err), zap.String("path", req.Path))
+			return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+		}
+	}
+
+	marshalled, err := json.Marshal(res)
+	if err != nil {
+		app.logger.Error("Got error marshalling watermark list", zap.Error(err))
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	return &abci.ResponseQuery{
+		Code:      types.CodeOk,
+		Log:       fmt.Sprintf("%d watermark(s) listed", len(res.Watermarks)),
+		Height:    req.Height,
+		Value:     marshalled,
+		ProofOps:  &crypto.ProofOps{},
+		Codespace: types.Codespace,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package events_test
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Gaps and regressions in the record IDs of a principal's channel must be reported, and the watermarks queryable
+func TestRecordGaps(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	sysmon := harness.TestEvent(1)
+	sysmon.Event.System.Channel = "Microsoft-Windows-Sysmon/Operational"
+
+	res := chain.Block(harness.CreateEventTx(t, agent, 0, 10), harness.CreateEventTx(t, agent, 1, 11), harness.EventTx(t, agent, 2, sysmon))
+	harness.RequireCodes(t, res, eventtypes.CodeOk, eventtypes.CodeOk, eventtypes.CodeOk)
+	for _, result := range res.TxResults {
+		require.Len(t, result.Events, 1)
+	}
+
+	// Records 12 to 14 were never submitted, and record 13 precedes record 15
+	res = chain.Block(harness.CreateEventTx(t, agent, 3, 15), harness.CreateEventTx(t, agent, 4, 13))
+	harness.RequireCodes(t, res, eventtypes.CodeOk, eventtypes.CodeOk)
+
+	require.Len(t, res.TxResults[0].Events, 2)
+	gap := res.TxResults[0].Events[1]
+	require.Equal(t, eventtypes.EventRecordGap, gap.Type)
+	require.Contains(t, gap.Attributes, abci.EventAttribute{Key: eventtypes.AttributeChannel, Value: "Security", Index: true})
+	require.Contains(t, gap.Attributes, abci.EventAttribute{Key: eventtypes.AttributeMissingRecords, Value: "3"})
+
+	require.Len(t, res.TxResults[1].Events, 2)
+	require.Equal(t, eventtypes.EventRecordRegression, res.TxResults[1].Events[1].Type)
+
+	listed := chain.Query(eventtypes.AppName, eventtypes.QueryPathWatermarks+agent.Name.String())
+	require.Equal(t, uint32(0), listed.Code, listed.Log)
+
+	var list eventtypes.WatermarkListResponse
+	require.NoError(t, json.Unmarshal(listed.Value, &list))
+	require.Len(t, list.Watermarks, 2)
+
+	watermarks := make(map[string]eventtypes.RecordWatermark)
+	for _, watermark := range list.Watermarks {
+		proofOps := proof.ListedProofOps(watermark.Proof, listed.ProofOps)
+		_, err := proof.VerifyChain(proofOps, eventtypes.AppName, watermark.Key, res.AppHash, multiplexer.AppVersion)
+		require.NoError(t, err)
+
+		watermarks[watermark.Watermark.Channel] = watermark.Watermark
+	}
+
+	require.Equal(t, 13, watermarks["Security"].LastRecordId)
+	require.Equal(t, uint64(3), watermarks["Security"].MissingRecords)
+	require.Equal(t, uint64(1), watermarks["Security"].Regressions)
+	require.Equal(t, 1, watermarks[sysmon.Event.System.Channel].LastRecordId)
+
+	all := chain.Query(eventtypes.AppName, eventtypes.QueryPathWatermarks+"?limit=1")
+	require.Equal(t, uint32(0), all.Code, all.Log)
+	require.NoError(t, json.Unmarshal(all.Value, &list))
+	require.Len(t, list.Watermarks, 1)
+	require.NotEmpty(t, list.Next)
+}
@@ -2,9 +2,12 @@ package identity
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
 	"github.com/RyanW02/wineventchain/app/internal/utils"
 	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
 	"github.com/RyanW02/wineventchain/common/pkg/proof"
@@ -19,25 +22,34 @@ import (
 
 type IdentityApp struct {
 	Repository
+	// Sequences tracks the sequence numbers of principals across all apps, and is shared with the multiplexer.
+	Sequences     *SequenceTracker
 	logger        *zap.Logger
 	db            dbm.DB
 	versionNumber int64
 	txState       txState
+	metrics       metrics
 }
 
 type txState struct {
-	seeded      bool
-	registering []types.Principal
+	seeded         bool
+	registering    []types.Principal
+	rotating       []types.Principal
+	changingStatus []types.Principal
 }
 
 func defaultTxState() txState {
 	return txState{
-		seeded:      false,
-		registering: make([]types.Principal, 0),
+		seeded:         false,
+		registering:    make([]types.Principal, 0),
+		rotating:       make([]types.Principal, 0),
+		changingStatus: make([]types.Principal, 0),
 	}
 }
 
-var _ multiplexer.MultiplexedApp = (*IdentityApp)(nil)
+var _ multiplexer.SnapshottableApp = (*IdentityApp)(nil)
+var _ multiplexer.GenesisApp = (*IdentityApp)(nil)
+var _ multiplexer.VersionedApp = (*IdentityApp)(nil)
 
 const treeCacheSize = 1000
 
@@ -53,13 +65,19 @@ func NewIdentityApp(logger *zap.Logger, db dbm.DB) (*IdentityApp, error) {
 		return nil, err
 	}
 
-	return &IdentityApp{
+	app := &IdentityApp{
 		Repository:    NewMerkleRepository(tree),
 		logger:        logger,
 		db:            db,
 		versionNumber: versionNumber,
 		txState:       defaultTxState(),
-	}, nil
+		metrics:       newMetrics(),
+	}
+
+	app.Sequences = NewSequenceTracker(app.Repository)
+	app.updateMetrics()
+
+	return app, nil
 }
 
 func (app *IdentityApp) Name() string {
@@ -79,15 +97,109 @@ func (app *IdentityApp) Info(ctx context.Context, req *abci.RequestInfo) any {
 	}
 }
 
+// InitChain saves the empty state of a new chain, so that a block whose commit did not complete can always be rolled
+// back to an earlier version.
 func (app *IdentityApp) InitChain(ctx context.Context, req *abci.RequestInitChain) []byte {
+	if app.Repository.Version() == 0 {
+		_, versionNumber, err := app.Repository.Save()
+		if err != nil {
+			app.logger.Fatal("Got error saving IdentityApp state when running InitChain", zap.Error(err))
+		}
+
+		app.versionNumber = versionNumber
+	}
+
 	appHash, err := app.Repository.Hash()
 	if err != nil {
 		app.logger.Fatal("Got error getting hash of IdentityApp when running InitChain", zap.Error(err))
 	}
 
+	// Identities may have been imported from the genesis app state
+	app.updateMetrics()
+
 	return appHash
 }
 
+func (app *IdentityApp) WorkingHash() ([]byte, error) {
+	return app.Repository.WorkingHash()
+}
+
+// Commit saves the identities and sequence numbers written in the current block, if any.
+func (app *IdentityApp) Commit() error {
+	identitiesChanged := len(app.txState.registering) > 0 || len(app.txState.changingStatus) > 0
+	app.txState = defaultTxState()
+
+	versionNumber, err := datastore.SaveChanges(app.Repository)
+	if err != nil {
+		return err
+	}
+
+	app.versionNumber = versionNumber
+
+	if identitiesChanged {
+		app.updateMetrics()
+	}
+
+	return nil
+}
+
+func (app *IdentityApp) LoadVersionForOverwriting(version int64) error {
+	if err := app.Repository.LoadVersionForOverwriting(version); err != nil {
+		return err
+	}
+
+	app.versionNumber = version
+	app.updateMetrics()
+	return nil
+}
+
+// ExportSnapshot exports the latest committed version of the identity tree.
+func (app *IdentityApp) ExportSnapshot() (json.RawMessage, error) {
+	snapshot, err := app.Repository.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(snapshot)
+}
+
+// RestoreSnapshot imports the identity tree from a snapshot, verifying its hash.
+func (app *IdentityApp) RestoreSnapshot(data json.RawMessage) error {
+	var snapshot datastore.TreeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if err := app.Repository.Import(snapshot); err != nil {
+		return err
+	}
+
+	app.versionNumber = snapshot.Version
+	app.updateMetrics()
+	return nil
+}
+
+// ExportGenesis exports the content of the latest committed version of the identity tree.
+func (app *IdentityApp) ExportGenesis() (json.RawMessage, error) {
+	entries, err := app.Repository.ExportGenesis()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entries)
+}
+
+// InitGenesis writes the identities and sequence numbers carried over from another chain to the working tree. The
+// state is saved by InitChain.
+func (app *IdentityApp) InitGenesis(data json.RawMessage) error {
+	var entries []datastore.GenesisEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	return app.Repository.ImportGenesis(entries)
+}
+
 func (app *IdentityApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, data json.RawMessage) (*abci.ResponseCheckTx, error) {
 	var payload rpc.SignedPayload
 	if err := json.Unmarshal(data, &payload); err != nil {
@@ -98,7 +210,7 @@ func (app *IdentityApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, d
 	// The only non-signed rpc is the seed request
 	var requester types.IdentityData
 	if payload.Type != types.RequestTypeSeed {
-		identityData, err := app.extractIdentityData(payload)
+		identityData, err := app.extractIdentityData(ctx, payload, 0)
 		if err != nil {
 			return err.IntoCheckTxResponse(), nil
 		}
@@ -126,12 +238,13 @@ func (app *IdentityApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, d
 
 		return &abci.ResponseCheckTx{Code: types.CodeOk, Codespace: types.Codespace}, nil
 	case types.RequestTypeRegister:
-		if requester.Role != types.RoleAdmin {
+		if !requester.Can(types.PermissionManageIdentities) {
 			app.logger.Warn(
-				"Got non-admin attempting to register",
+				"Got unauthorised principal attempting to register",
 				zap.String("requester", payload.Principal.String()),
+				zap.String("role", requester.Role.String()),
 			)
-			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("only principals with the administrator role can register new principals")).IntoCheckTxResponse(), nil
+			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to register new principals")).IntoCheckTxResponse(), nil
 		}
 
 		var registerData types.PayloadRegister
@@ -140,6 +253,10 @@ func (app *IdentityApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, d
 			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
 		}
 
+		if errRes := app.validateRoleAssignment(payload.Principal, requester, registerData.Role); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+
 		exists, err := app.Repository.Has(registerData.Principal)
 		if err != nil {
 			app.logger.Warn("Got error checking if principal exists", zap.Error(err))
@@ -150,6 +267,30 @@ func (app *IdentityApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, d
 			return multiplexer.NewErrorResponse(types.CodePrincipalAlreadyExists, types.Codespace, errors.New("principal already exists")).IntoCheckTxResponse(), nil
 		}
 
+		return &abci.ResponseCheckTx{Code: types.CodeOk, Codespace: types.Codespace}, nil
+	case types.RequestTypeRotateKey:
+		var rotateData types.PayloadRotateKey
+		if err := json.Unmarshal(payload.Data, &rotateData); err != nil {
+			app.logger.Warn("Got error decoding request rpc", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+		}
+
+		if _, err := app.validateKeyRotation(payload.Principal, requester, rotateData); err != nil {
+			return err.IntoCheckTxResponse(), nil
+		}
+
+		return &abci.ResponseCheckTx{Code: types.CodeOk, Codespace: types.Codespace}, nil
+	case types.RequestTypeSuspend, types.RequestTypeReinstate, types.RequestTypeRevoke:
+		var statusData types.PayloadStatusChange
+		if err := json.Unmarshal(payload.Data, &statusData); err != nil {
+			app.logger.Warn("Got error decoding request rpc", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+		}
+
+		if _, _, err := app.validateStatusChange(payload.Principal, requester, string(payload.Type), statusData); err != nil {
+			return err.IntoCheckTxResponse(), nil
+		}
+
 		return &abci.ResponseCheckTx{Code: types.CodeOk, Codespace: types.Codespace}, nil
 	default:
 		app.logger.Warn(
@@ -170,7 +311,7 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 	// The only non-signed rpc is the seed request
 	var requester types.IdentityData
 	if payload.Type != types.RequestTypeSeed {
-		identityData, err := app.extractIdentityData(payload)
+		identityData, err := app.extractIdentityData(ctx, payload, req.Height)
 		if err != nil {
 			return err.IntoFinalizeBlockResponse()
 		}
@@ -204,9 +345,13 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 		app.txState.seeded = true
 		app.txState.registering = append(app.txState.registering, seedData.Principal)
 
-		appHash, err := app.Repository.Hash()
+		err = app.Repository.Store(seedData.Principal, identityData)
+		if err == nil {
+			err = app.Repository.SetSeeded()
+		}
+
 		if err != nil {
-			app.logger.Warn("Got error getting app hash", zap.Error(err))
+			app.logger.Warn("Got error applying identity changes", zap.Error(err))
 			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
 
@@ -214,37 +359,15 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 			TxResult: abci.ExecTxResult{
 				Code: types.CodeOk,
 			},
-			AppHash: appHash,
-			CommitFunc: func() error {
-				// Reset state to before tx
-				app.txState = defaultTxState()
-
-				// Re-apply state
-				if err := app.Repository.Store(seedData.Principal, identityData); err != nil {
-					return err
-				}
-
-				// Re-apply state
-				if err := app.Repository.SetSeeded(); err != nil {
-					return err
-				}
-
-				_, versionNumber, err := app.Repository.Save()
-				if err != nil {
-					return err
-				}
-
-				app.versionNumber = versionNumber
-				return err
-			},
 		}
 	case types.RequestTypeRegister:
-		if requester.Role != types.RoleAdmin {
+		if !requester.Can(types.PermissionManageIdentities) {
 			app.logger.Warn(
-				"Got non-admin attempting to register",
+				"Got unauthorised principal attempting to register",
 				zap.String("requester", payload.Principal.String()),
+				zap.String("role", requester.Role.String()),
 			)
-			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("only principals with the administrator role can register new principals")).IntoFinalizeBlockResponse()
+			return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to register new principals")).IntoFinalizeBlockResponse()
 		}
 
 		var registerData types.PayloadRegister
@@ -253,6 +376,10 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
 
+		if errRes := app.validateRoleAssignment(payload.Principal, requester, registerData.Role); errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
+
 		identityData := types.IdentityData{
 			PublicKey: registerData.Key,
 			Role:      registerData.Role,
@@ -270,9 +397,54 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 
 		app.txState.registering = append(app.txState.registering, registerData.Principal)
 
-		appHash, err := app.Repository.Hash()
+		if err := app.Repository.Store(registerData.Principal, identityData); err != nil {
+			app.logger.Warn("Got error applying identity changes", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		return multiplexer.FinalizeBlockResponse{
+			TxResult: abci.ExecTxResult{
+				Code: types.CodeOk,
+			},
+		}
+	case types.RequestTypeRotateKey:
+		var rotateData types.PayloadRotateKey
+		if err := json.Unmarshal(payload.Data, &rotateData); err != nil {
+			app.logger.Warn("Got error decoding request rpc", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		if _, err := app.validateKeyRotation(payload.Principal, requester, rotateData); err != nil {
+			return err.IntoFinalizeBlockResponse()
+		}
+
+		// Off-chain event data is verified against the key assigned at the height of the event, which would be
+		// ambiguous if the principal's requests preceded the rotation within the block. Requests that follow the
+		// rotation are rejected by the events app.
+		if multiplexer.SignedInBlock(ctx, rotateData.Principal) {
+			return multiplexer.NewErrorResponse(
+				types.CodeRotationConflict,
+				types.Codespace,
+				errors.New("principal has signed requests earlier in this block, so the key must be rotated in a later block"),
+			).IntoFinalizeBlockResponse()
+		}
+
+		app.txState.rotating = append(app.txState.rotating, rotateData.Principal)
+
+		identityData, err := app.Repository.Get(rotateData.Principal)
+		if err == nil {
+			// Retain the old key, so that signatures made before this height can still be verified
+			identityData.PreviousKeys = append(identityData.PreviousKeys, types.RetiredKey{
+				PublicKey: identityData.PublicKey,
+				RetiredAt: req.Height,
+			})
+			identityData.PublicKey = rotateData.Key
+
+			err = app.Repository.Store(rotateData.Principal, identityData)
+		}
+
 		if err != nil {
-			app.logger.Warn("Got error getting app hash", zap.Error(err))
+			app.logger.Warn("Got error applying identity changes", zap.Error(err))
 			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
 
@@ -280,23 +452,45 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 			TxResult: abci.ExecTxResult{
 				Code: types.CodeOk,
 			},
-			AppHash: appHash,
-			CommitFunc: func() error {
-				// Reset state to before tx
-				app.txState = defaultTxState()
-
-				// Re-apply state
-				if err := app.Repository.Store(registerData.Principal, identityData); err != nil {
-					return err
-				}
-
-				_, versionNumber, err := app.Repository.Save()
-				if err != nil {
-					return err
-				}
-
-				app.versionNumber = versionNumber
-				return err
+		}
+	case types.RequestTypeSuspend, types.RequestTypeReinstate, types.RequestTypeRevoke:
+		var statusData types.PayloadStatusChange
+		if err := json.Unmarshal(payload.Data, &statusData); err != nil {
+			app.logger.Warn("Got error decoding request rpc", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		_, status, errRes := app.validateStatusChange(payload.Principal, requester, string(payload.Type), statusData)
+		if errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
+
+		app.txState.changingStatus = append(app.txState.changingStatus, statusData.Principal)
+
+		app.logger.Info(
+			"Changing principal status",
+			zap.String("principal", statusData.Principal.String()),
+			zap.String("status", status.String()),
+			zap.String("author", payload.Principal.String()),
+			zap.String("reason", statusData.Reason),
+		)
+
+		identityData, err := app.Repository.Get(statusData.Principal)
+		if err == nil {
+			identityData.Status = status
+			identityData.StatusChangedAt = req.Height
+
+			err = app.Repository.Store(statusData.Principal, identityData)
+		}
+
+		if err != nil {
+			app.logger.Warn("Got error applying identity changes", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		return multiplexer.FinalizeBlockResponse{
+			TxResult: abci.ExecTxResult{
+				Code: types.CodeOk,
 			},
 		}
 	default:
@@ -309,9 +503,18 @@ func (app *IdentityApp) FinalizeBlock(ctx context.Context, req *abci.RequestFina
 }
 
 func (app *IdentityApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
+	reader, errRes := app.reader(ctx, req)
+	if errRes != nil {
+		return errRes.IntoQueryResponse(), nil
+	}
+
+	if strings.HasPrefix(req.Path, types.QueryPathSequence) {
+		return app.querySequence(reader, req.Height > 0, types.Principal(strings.TrimPrefix(req.Path, types.QueryPathSequence)))
+	}
+
 	principal := types.Principal(strings.TrimPrefix(req.Path, "/"))
 
-	item, err := app.GetWithProof(principal)
+	item, err := reader.GetWithProof(principal)
 	if err != nil {
 		if errors.Is(err, proof.ErrTreeUninitialized) {
 			return multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err).IntoQueryResponse(), nil
@@ -355,7 +558,202 @@ func (app *IdentityApp) Query(ctx context.Context, req *abci.RequestQuery) (*abc
 	}
 }
 
-func (app *IdentityApp) extractIdentityData(payload rpc.SignedPayload) (types.IdentityData, *multiplexer.ErrorResponse) {
+// reader returns the Reader for the saved version resolved by the multiplexer for the height of the query, or the
+// working tree if no version was resolved.
+func (app *IdentityApp) reader(ctx context.Context, req *abci.RequestQuery) (Reader, *multiplexer.ErrorResponse) {
+	version, ok := multiplexer.VersionFromContext(ctx)
+	if !ok {
+		return app.Repository, nil
+	}
+
+	height := req.Height
+	if height == 0 {
+		height = multiplexer.HeightFromContext(ctx)
+	}
+
+	reader, err := app.Repository.ReaderAt(version, height)
+	if err != nil {
+		if errors.Is(err, proof.ErrTreeUninitialized) {
+			return nil, multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err)
+		} else if errors.Is(err, datastore.ErrVersionUnavailable) {
+			return nil, multiplexer.NewErrorResponse(multiplexer.CodeHeightUnavailable, multiplexer.Codespace, err)
+		} else {
+			app.logger.Error("Got error reading IdentityApp version", zap.Error(err), zap.Int64("version", version))
+			return nil, multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+		}
+	}
+
+	return reader, nil
+}
+
+// querySequence returns the next sequence number expected of the principal, which clients must include in their next
+// signed request. For historical queries, this is the sequence number committed at the requested height.
+func (app *IdentityApp) querySequence(reader Reader, historical bool, principal types.Principal) (*abci.ResponseQuery, error) {
+	var sequence uint64
+	var err error
+	if historical {
+		sequence, err = reader.GetSequence(principal)
+	} else {
+		sequence, err = app.Sequences.Next(principal)
+	}
+
+	if err != nil {
+		app.logger.Error(
+			"Got error getting principal sequence number",
+			zap.Error(err),
+			zap.String("principal", principal.String()),
+		)
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err).IntoQueryResponse(), nil
+	}
+
+	marshalled, err := json.Marshal(types.SequenceResponse{
+		Principal: principal,
+		Sequence:  sequence,
+	})
+	if err != nil {
+		app.logger.Warn("Got error marshalling sequence response", zap.Error(err))
+		return nil, err
+	}
+
+	return &abci.ResponseQuery{
+		Code:      types.CodeOk,
+		Key:       []byte(principal),
+		Value:     marshalled,
+		Codespace: types.Codespace,
+	}, nil
+}
+
+// validateRoleAssignment checks that the role being assigned to a new principal is known to the permission matrix, and
+// that the requester is permitted to assign it. Only administrators may create further administrators, preventing
+// principals from escalating their own privileges through a principal they control.
+func (app *IdentityApp) validateRoleAssignment(
+	requesterPrincipal types.Principal,
+	requester types.IdentityData,
+	role types.Role,
+) *multiplexer.ErrorResponse {
+	if !role.IsValid() {
+		return multiplexer.NewErrorResponse(types.CodeInvalidRole, types.Codespace, fmt.Errorf("unknown role: %s", role))
+	}
+
+	if role == types.RoleAdmin && !requester.IsAdmin() {
+		app.logger.Warn(
+			"Got non-admin attempting to register an administrator",
+			zap.String("requester", requesterPrincipal.String()),
+		)
+		return multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("only principals with the administrator role can register administrators"))
+	}
+
+	return nil
+}
+
+// validateKeyRotation checks that the requester is permitted to replace the key of the target principal, and that the
+// new key is valid. Principals may rotate their own key, and principals permitted to manage identities may rotate the
+// keys of others, although only administrators may rotate the keys of administrators.
+func (app *IdentityApp) validateKeyRotation(
+	requesterPrincipal types.Principal,
+	requester types.IdentityData,
+	rotateData types.PayloadRotateKey,
+) (types.IdentityData, *multiplexer.ErrorResponse) {
+	isSelf := requesterPrincipal == rotateData.Principal
+	if !isSelf && !requester.Can(types.PermissionManageIdentities) {
+		app.logger.Warn(
+			"Got unauthorised principal attempting to rotate the key of another principal",
+			zap.String("requester", requesterPrincipal.String()),
+			zap.String("principal", rotateData.Principal.String()),
+		)
+		return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to rotate the keys of other principals"))
+	}
+
+	if len(rotateData.Key) != ed25519.PublicKeySize {
+		return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeInvalidKey, types.Codespace, errors.New("public key is not a valid Ed25519 key"))
+	}
+
+	target, err := app.Repository.Get(rotateData.Principal)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeNotFound, types.Codespace, err)
+		}
+
+		app.logger.Warn("Got error getting principal identity data", zap.Error(err))
+		return types.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	if !isSelf && target.IsAdmin() && !requester.IsAdmin() {
+		return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("only principals with the administrator role can rotate the keys of administrators"))
+	}
+
+	if target.IsRevoked() {
+		return types.IdentityData{}, CheckStatus(rotateData.Principal, target)
+	}
+
+	if target.HasUsedKey(rotateData.Key) {
+		return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeInvalidKey, types.Codespace, errors.New("public key has already been used by this principal"))
+	}
+
+	if utils.Contains(app.txState.rotating, rotateData.Principal) {
+		return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeKeyRotationPending, types.Codespace, errors.New("principal already has a key rotation pending in this block"))
+	}
+
+	return target, nil
+}
+
+// validateStatusChange checks that the requester is permitted to change the status of the target principal, and that
+// the target principal may transition to the new status. Returns the target's current identity data, and the status
+// that it will transition to.
+func (app *IdentityApp) validateStatusChange(
+	requesterPrincipal types.Principal,
+	requester types.IdentityData,
+	requestType string,
+	statusData types.PayloadStatusChange,
+) (types.IdentityData, types.Status, *multiplexer.ErrorResponse) {
+	if !requester.Can(types.PermissionManageIdentities) {
+		app.logger.Warn(
+			"Got unauthorised principal attempting to change the status of a principal",
+			zap.String("requester", requesterPrincipal.String()),
+			zap.String("principal", statusData.Principal.String()),
+		)
+		return types.IdentityData{}, "", multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("principal is not permitted to change the status of principals"))
+	}
+
+	// Prevent administrators from locking themselves out
+	if requesterPrincipal == statusData.Principal {
+		return types.IdentityData{}, "", multiplexer.NewErrorResponse(types.CodeInvalidStatusTransition, types.Codespace, errors.New("principals cannot change their own status"))
+	}
+
+	target, err := app.Repository.Get(statusData.Principal)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return types.IdentityData{}, "", multiplexer.NewErrorResponse(types.CodeNotFound, types.Codespace, err)
+		}
+
+		app.logger.Warn("Got error getting principal identity data", zap.Error(err))
+		return types.IdentityData{}, "", multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	if target.IsAdmin() && !requester.IsAdmin() {
+		return types.IdentityData{}, "", multiplexer.NewErrorResponse(types.CodeUnauthorized, types.Codespace, errors.New("only principals with the administrator role can change the status of administrators"))
+	}
+
+	status, ok := nextStatus(target.Status, requestType)
+	if !ok {
+		return types.IdentityData{}, "", multiplexer.NewErrorResponse(
+			types.CodeInvalidStatusTransition,
+			types.Codespace,
+			fmt.Errorf("cannot %s a principal with status %s", requestType, target.Status),
+		)
+	}
+
+	if utils.Contains(app.txState.changingStatus, statusData.Principal) {
+		return types.IdentityData{}, "", multiplexer.NewErrorResponse(types.CodeStatusChangePending, types.Codespace, errors.New("principal already has a status change pending in this block"))
+	}
+
+	return target, status, nil
+}
+
+// extractIdentityData fetches the identity of the principal that made the request, and validates the signature on the
+// payload against the key that was assigned to the principal at the given block height. A height of 0 validates
+// against the principal's current key.
+func (app *IdentityApp) extractIdentityData(ctx context.Context, payload rpc.SignedPayload, height int64) (types.IdentityData, *multiplexer.ErrorResponse) {
 	requester, err := app.Repository.Get(payload.Principal)
 	if err != nil {
 		app.logger.Warn(
@@ -366,7 +764,7 @@ func (app *IdentityApp) extractIdentityData(payload rpc.SignedPayload) (types.Id
 		return types.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
 	}
 
-	valid, err := payload.ValidateSignature(requester.PublicKey)
+	valid, err := payload.Verify(requester.KeyAt(height), multiplexer.ChainIDFromContext(ctx), types.AppName)
 	if err != nil {
 		app.logger.Warn(
 			"Got error validating IdentityApp request signature",
@@ -384,5 +782,14 @@ func (app *IdentityApp) extractIdentityData(payload rpc.SignedPayload) (types.Id
 		return types.IdentityData{}, multiplexer.NewErrorResponse(types.CodeInvalidSignature, types.Codespace, nil)
 	}
 
+	if err := CheckStatus(payload.Principal, requester); err != nil {
+		app.logger.Warn(
+			"Got IdentityApp request from inactive principal",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("status", requester.Status.String()),
+		)
+		return types.IdentityData{}, err
+	}
+
 	return requester, nil
 }
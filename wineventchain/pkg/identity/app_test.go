@@ -0,0 +1,48 @@
+package identity_test
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// A key may not be rotated in a block that contains events created by the principal, so that the key that signed the
+// off-chain data of each event is unambiguous
+func TestKeyRotation(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	// Events before the rotation
+	previous := agent
+	res := chain.Block(harness.CreateEventTx(t, agent, 0, 1), harness.RotateKeyTx(t, admin, 1, &agent))
+	harness.RequireCodes(t, res, eventtypes.CodeOk, identitytypes.CodeRotationConflict)
+
+	// Events after the rotation, signed by either key
+	agent = previous
+	rotated := agent
+	res = chain.Block(
+		harness.RotateKeyTx(t, admin, 1, &rotated),
+		harness.CreateEventTx(t, agent, 1, 2),
+		harness.CreateEventTx(t, rotated, 1, 3),
+	)
+	harness.RequireCodes(t, res, identitytypes.CodeOk, eventtypes.CodeKeyRotated, rpc.CodeInvalidSignature)
+
+	res = chain.Block(harness.CreateEventTx(t, rotated, 1, 4))
+	harness.RequireCodes(t, res, eventtypes.CodeOk)
+	eventHeight := chain.Height
+
+	// Off-chain data is verified against the key assigned at the height of the event
+	queried := chain.Query(identitytypes.AppName, "/"+agent.Name.String())
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	var identity identitytypes.IdentityData
+	require.NoError(t, json.Unmarshal(queried.Value, &identity))
+	require.Equal(t, agent.PublicKey(), identity.KeyAt(eventHeight-1))
+	require.Equal(t, rotated.PublicKey(), identity.KeyAt(eventHeight))
+}
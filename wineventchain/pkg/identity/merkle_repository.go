@@ -1,7 +1,9 @@
 package identity
 
 import (
+	"encoding/binary"
 	"errors"
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
 	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	types "github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/cometbft/cometbft/libs/json"
@@ -16,7 +18,8 @@ type MerkleRepository struct {
 
 const (
 	metaPrefix      = "meta/"
-	principalPrefix = "principal/"
+	principalPrefix = types.TreeKeyPrefix
+	sequencePrefix  = "sequence/"
 )
 
 var _ Repository = (*MerkleRepository)(nil)
@@ -45,6 +48,14 @@ func (r *MerkleRepository) LoadVersion(version int64) error {
 	return err
 }
 
+func (r *MerkleRepository) LoadVersionForOverwriting(version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.tree.LoadVersionForOverwriting(version)
+	return err
+}
+
 func (r *MerkleRepository) Rollback() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -59,6 +70,13 @@ func (r *MerkleRepository) Hash() ([]byte, error) {
 	return r.tree.Hash()
 }
 
+func (r *MerkleRepository) WorkingHash() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.WorkingHash()
+}
+
 func (r *MerkleRepository) Save() ([]byte, int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -66,11 +84,161 @@ func (r *MerkleRepository) Save() ([]byte, int64, error) {
 	return r.tree.SaveVersion()
 }
 
+func (r *MerkleRepository) Version() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Version()
+}
+
+func (r *MerkleRepository) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Size()
+}
+
+func (r *MerkleRepository) AvailableVersions() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.AvailableVersions(r.tree)
+}
+
+func (r *MerkleRepository) DeleteVersions(versions ...int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.DeleteVersions(r.tree, versions)
+}
+
+func (r *MerkleRepository) Export() (datastore.TreeSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ExportTree(r.tree)
+}
+
+func (r *MerkleRepository) Import(snapshot datastore.TreeSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ImportTree(r.tree, snapshot)
+}
+
+func (r *MerkleRepository) ExportGenesis() ([]datastore.GenesisEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ExportGenesis(r.tree)
+}
+
+func (r *MerkleRepository) ImportGenesis(entries []datastore.GenesisEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ImportGenesis(r.tree, entries)
+}
+
 func (r *MerkleRepository) Get(principal types.Principal) (types.IdentityData, error) {
+	return r.reader().Get(principal)
+}
+
+func (r *MerkleRepository) GetWithProof(principal types.Principal) (proof.ItemWithProof[types.IdentityData], error) {
+	return r.reader().GetWithProof(principal)
+}
+
+func (r *MerkleRepository) Has(principal types.Principal) (bool, error) {
+	return r.reader().Has(principal)
+}
+
+func (r *MerkleRepository) ForEach(fn func(principal types.Principal, data types.IdentityData) bool) error {
+	return r.reader().ForEach(fn)
+}
+
+func (r *MerkleRepository) GetSequence(principal types.Principal) (uint64, error) {
+	return r.reader().GetSequence(principal)
+}
+
+func (r *MerkleRepository) ReaderAt(version, height int64) (Reader, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tree, err := datastore.TreeAt(r.tree, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return merkleReader{
+		tree: tree,
+		mu:   &r.mu,
+		height: func() int64 {
+			return height
+		},
+	}, nil
+}
+
+// reader returns a Reader for the working tree.
+func (r *MerkleRepository) reader() merkleReader {
+	return merkleReader{
+		tree: r.tree,
+		mu:   &r.mu,
+		height: func() int64 {
+			return proof.GetProofHeight(r.tree)
+		},
+	}
+}
+
+func (r *MerkleRepository) Store(principal types.Principal, data types.IdentityData) error {
+	marshalled, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.tree.Set(principal.TreeKey(), marshalled)
+	return err
+}
+
+func (r *MerkleRepository) SetSequence(principal types.Principal, sequence uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.tree.Set([]byte(sequencePrefix+principal), binary.BigEndian.AppendUint64(nil, sequence))
+	return err
+}
+
+func (r *MerkleRepository) IsSeeded() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Has([]byte(metaPrefix + "seeded"))
+}
+
+func (r *MerkleRepository) SetSeeded() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	bytes, err := r.tree.Get([]byte(principalPrefix + principal))
+	_, err := r.tree.Set([]byte(metaPrefix+"seeded"), []byte("true"))
+	return err
+}
+
+// merkleReader reads from a single version of the identity tree, sharing the repository's lock.
+type merkleReader struct {
+	tree   datastore.ReadableTree
+	mu     *sync.Mutex
+	height func() int64
+}
+
+var _ Reader = merkleReader{}
+
+func (r merkleReader) Get(principal types.Principal) (types.IdentityData, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bytes, err := r.tree.Get(principal.TreeKey())
 	if err != nil {
 		return types.IdentityData{}, err
 	}
@@ -87,11 +255,11 @@ func (r *MerkleRepository) Get(principal types.Principal) (types.IdentityData, e
 	return data, nil
 }
 
-func (r *MerkleRepository) GetWithProof(principal types.Principal) (proof.ItemWithProof[types.IdentityData], error) {
+func (r merkleReader) GetWithProof(principal types.Principal) (proof.ItemWithProof[types.IdentityData], error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	key := []byte(principalPrefix + principal)
+	key := principal.TreeKey()
 	index, bytes, err := r.tree.GetWithIndex(key)
 	if err != nil {
 		return proof.ItemWithProof[types.IdentityData]{}, err
@@ -108,7 +276,7 @@ func (r *MerkleRepository) GetWithProof(principal types.Principal) (proof.ItemWi
 		return proof.ItemWithProof[types.IdentityData]{
 			Item:    nil,
 			Index:   index,
-			Height:  proof.GetProofHeight(r.tree),
+			Height:  r.height(),
 			ProofOp: proofOp,
 		}, nil
 	}
@@ -121,42 +289,58 @@ func (r *MerkleRepository) GetWithProof(principal types.Principal) (proof.ItemWi
 	return proof.ItemWithProof[types.IdentityData]{
 		Item:    &data,
 		Index:   index,
-		Height:  proof.GetProofHeight(r.tree),
+		Height:  r.height(),
 		ProofOp: proofOp,
 	}, nil
 }
 
-func (r *MerkleRepository) Has(principal types.Principal) (bool, error) {
+func (r merkleReader) Has(principal types.Principal) (bool, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	return r.tree.Has([]byte(principalPrefix + principal))
+	return r.tree.Has(principal.TreeKey())
 }
 
-func (r *MerkleRepository) Store(principal types.Principal, data types.IdentityData) error {
-	marshalled, err := json.Marshal(data)
+func (r merkleReader) ForEach(fn func(principal types.Principal, data types.IdentityData) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// '0' is the byte following '/', so the range covers exactly the keys with the principal prefix
+	start, end := []byte(principalPrefix), []byte(principalPrefix[:len(principalPrefix)-1]+"0")
+
+	iterator, err := r.tree.Iterator(start, end, true)
 	if err != nil {
 		return err
 	}
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var data types.IdentityData
+		if err := json.Unmarshal(iterator.Value(), &data); err != nil {
+			return err
+		}
+
+		principal := types.Principal(iterator.Key()[len(principalPrefix):])
+		if !fn(principal, data) {
+			break
+		}
+	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	_, err = r.tree.Set([]byte(principalPrefix+principal), marshalled)
-	return err
+	return iterator.Error()
 }
 
-func (r *MerkleRepository) IsSeeded() (bool, error) {
+func (r merkleReader) GetSequence(principal types.Principal) (uint64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	return r.tree.Has([]byte(metaPrefix + "seeded"))
-}
+	value, err := r.tree.Get([]byte(sequencePrefix + principal))
+	if err != nil {
+		return 0, err
+	}
 
-func (r *MerkleRepository) SetSeeded() error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	if value == nil {
+		return 0, nil
+	}
 
-	_, err := r.tree.Set([]byte(metaPrefix+"seeded"), []byte("true"))
-	return err
+	return binary.BigEndian.Uint64(value), nil
 }
@@ -0,0 +1,63 @@
+package identity
+
+import (
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var _ multiplexer.MetricsApp = (*IdentityApp)(nil)
+var _ multiplexer.SizedApp = (*IdentityApp)(nil)
+
+// metrics are updated when a block that registers identities or changes their status is committed, and when state is
+// loaded, so that scrapes never read the identity tree.
+type metrics struct {
+	identities *prometheus.GaugeVec
+}
+
+func newMetrics() metrics {
+	return metrics{
+		identities: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: multiplexer.MetricsNamespace,
+			Subsystem: "identity",
+			Name:      "identities",
+			Help:      "Number of registered identities, by status.",
+		}, []string{"status"}),
+	}
+}
+
+func (app *IdentityApp) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{app.metrics.identities}
+}
+
+// updateMetrics counts the identities in the working tree by status. Must be called from the goroutine that drives
+// the application, while no block is being finalized, so that the count reflects committed state.
+func (app *IdentityApp) updateMetrics() {
+	counts := map[types.Status]int{
+		types.StatusActive:    0,
+		types.StatusSuspended: 0,
+		types.StatusRevoked:   0,
+	}
+
+	err := app.Repository.ForEach(func(principal types.Principal, data types.IdentityData) bool {
+		switch {
+		case data.IsRevoked():
+			counts[types.StatusRevoked]++
+		case data.IsSuspended():
+			counts[types.StatusSuspended]++
+		default:
+			counts[types.StatusActive]++
+		}
+
+		return true
+	})
+	if err != nil {
+		app.logger.Warn("Got error counting identities", zap.Error(err))
+		return
+	}
+
+	for status, count := range counts {
+		app.metrics.identities.WithLabelValues(string(status)).Set(float64(count))
+	}
+}
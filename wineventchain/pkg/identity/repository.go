@@ -6,12 +6,26 @@ import (
 	types "github.com/RyanW02/wineventchain/common/pkg/types/identity"
 )
 
-type Repository interface {
-	datastore.BaseRepository
+// Reader reads identities from a single version of the identity tree.
+type Reader interface {
 	Get(principal types.Principal) (types.IdentityData, error)
 	GetWithProof(principal types.Principal) (proof.ItemWithProof[types.IdentityData], error)
 	Has(principal types.Principal) (bool, error)
+	// ForEach calls fn for each registered principal, in lexicographical order, until fn returns false.
+	ForEach(fn func(principal types.Principal, data types.IdentityData) bool) error
+	// GetSequence returns the next sequence number expected of the principal, which is 0 for principals that have not
+	// yet submitted a signed request.
+	GetSequence(principal types.Principal) (uint64, error)
+}
+
+type Repository interface {
+	datastore.BaseRepository
+	Reader
+	// ReaderAt returns a Reader for the given saved version of the repository, which reflects the state at the given
+	// block height.
+	ReaderAt(version, height int64) (Reader, error)
 	Store(principal types.Principal, data types.IdentityData) error
+	SetSequence(principal types.Principal, sequence uint64) error
 	IsSeeded() (bool, error)
 	SetSeeded() error
 }
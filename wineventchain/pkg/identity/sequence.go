@@ -0,0 +1,107 @@
+package identity
+
+import (
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"sync"
+)
+
+// SequenceTracker enforces per-principal sequence numbers across all apps. Sequence numbers are stored in the identity
+// tree, so that they are covered by the app hash, and are saved when the identity app is committed.
+type SequenceTracker struct {
+	repository Repository
+
+	mu sync.Mutex
+	// admitted holds the next sequence number that CheckTx will admit for each principal, accounting for requests in
+	// the mempool that have not yet been committed.
+	admitted map[types.Principal]uint64
+}
+
+var _ multiplexer.SequenceTracker = (*SequenceTracker)(nil)
+
+func NewSequenceTracker(repository Repository) *SequenceTracker {
+	return &SequenceTracker{
+		repository: repository,
+		admitted:   make(map[types.Principal]uint64),
+	}
+}
+
+// Next returns the next sequence number expected of the principal, including requests in a block that is being
+// finalized.
+func (t *SequenceTracker) Next(principal types.Principal) (uint64, error) {
+	return t.repository.GetSequence(principal)
+}
+
+func (t *SequenceTracker) ValidateCheckTx(principal types.Principal, sequence uint64) *multiplexer.ErrorResponse {
+	committed, err := t.repository.GetSequence(principal)
+	if err != nil {
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if sequence < committed {
+		return multiplexer.NewErrorResponse(
+			types.CodeInvalidSequence,
+			types.Codespace,
+			fmt.Errorf("sequence number %d has already been used by principal %s, expected %d", sequence, principal, committed),
+		)
+	}
+
+	// Requests may be resubmitted with any sequence number that has not yet been committed, to replace requests that
+	// were evicted from the mempool, but may not skip ahead of the requests that have been admitted
+	if admitted := utils.Max(committed, t.admitted[principal]); sequence > admitted {
+		return multiplexer.NewErrorResponse(
+			types.CodeInvalidSequence,
+			types.Codespace,
+			fmt.Errorf("sequence number %d is ahead of the next sequence number %d for principal %s", sequence, admitted, principal),
+		)
+	}
+
+	return nil
+}
+
+func (t *SequenceTracker) Admit(principal types.Principal, sequence uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.admitted[principal] = utils.Max(t.admitted[principal], sequence+1)
+}
+
+// ValidateFinalize checks the sequence number against the working state, which includes the sequence numbers reserved
+// by requests earlier in the block.
+func (t *SequenceTracker) ValidateFinalize(principal types.Principal, sequence uint64) *multiplexer.ErrorResponse {
+	expected, err := t.repository.GetSequence(principal)
+	if err != nil {
+		return multiplexer.NewErrorResponse(types.CodeUnknownError, types.Codespace, err)
+	}
+
+	if sequence != expected {
+		return multiplexer.NewErrorResponse(
+			types.CodeInvalidSequence,
+			types.Codespace,
+			fmt.Errorf("invalid sequence number %d for principal %s, expected %d", sequence, principal, expected),
+		)
+	}
+
+	return nil
+}
+
+func (t *SequenceTracker) Reserve(principal types.Principal, sequence uint64) error {
+	if err := t.repository.SetSequence(principal, sequence+1); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Later requests from the principal that have been admitted keep their admission
+	if t.admitted[principal] <= sequence+1 {
+		delete(t.admitted, principal)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package identity
+
+import (
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+)
+
+// CheckStatus returns an error response if the principal has been suspended or revoked, and is therefore not permitted
+// to submit transactions to any app.
+func CheckStatus(principal types.Principal, data types.IdentityData) *multiplexer.ErrorResponse {
+	if data.IsRevoked() {
+		return multiplexer.NewErrorResponse(
+			types.CodePrincipalRevoked,
+			types.Codespace,
+			fmt.Errorf("principal %s was revoked at height %d", principal, data.StatusChangedAt),
+		)
+	}
+
+	if data.IsSuspended() {
+		return multiplexer.NewErrorResponse(
+			types.CodePrincipalSuspended,
+			types.Codespace,
+			fmt.Errorf("principal %s was suspended at height %d", principal, data.StatusChangedAt),
+		)
+	}
+
+	return nil
+}
+
+// nextStatus returns the status that a principal with the given current status transitions to for the given request
+// type, or false if the transition is not permitted.
+func nextStatus(current types.Status, requestType string) (types.Status, bool) {
+	if current == "" {
+		current = types.StatusActive
+	}
+
+	switch requestType {
+	case types.RequestTypeSuspend:
+		return types.StatusSuspended, current == types.StatusActive
+	case types.RequestTypeReinstate:
+		return types.StatusActive, current == types.StatusSuspended
+	case types.RequestTypeRevoke:
+		return types.StatusRevoked, current != types.StatusRevoked
+	default:
+		return "", false
+	}
+}
@@ -2,25 +2,31 @@ package multiplexer
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	common "github.com/RyanW02/wineventchain/common/pkg/types/rpc"
 	dbm "github.com/cometbft/cometbft-db"
 	"github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
 	"github.com/cometbft/cometbft/version"
 	"go.uber.org/zap"
-	"strconv"
-	"strings"
+	"time"
 )
 
 const (
-	AppVersion      = 1
-	stateKey        = "muxer_state"
-	validatorPrefix = "val:"
+	// AppVersion is the app version of the state of a new chain, unless the genesis document sets another. Later
+	// versions are introduced by upgrades.
+	AppVersion = 1
+	// LatestAppVersion is the latest app version whose rules are implemented by this binary. Chains adopt it through an
+	// upgrade plan, or by setting the app version in the consensus parameters of the genesis document. Version 2 binds
+	// the name and length of each sub-application root into the app hash, and validates created events. Version 3
+	// rejects legacy signatures.
+	LatestAppVersion = 3
+	stateKey         = "muxer_state"
 )
 
 var _ types.Application = (*MultiplexedApplication)(nil)
@@ -32,27 +38,72 @@ type MultiplexedApplication struct {
 	db           dbm.DB
 	apps         map[string]MultiplexedApp
 	state        State
-	commitFuncs  []func() error
+	committed    committedState
 	RetainBlocks int64 // blocks to retain after commit (via ResponseCommit.RetainHeight)
-
-	validators *ValidatorMap
+	// Pruning controls which historical versions of sub-application state are retained.
+	Pruning PruningOptions
+	// Sequences enforces per-principal sequence numbers on signed requests, preventing replays. Sequence numbers are
+	// not checked if nil. Legacy signatures do not bind the sequence number, so requests are only protected against
+	// replays once the chain reaches common.LegacyRejectionAppVersion.
+	Sequences SequenceTracker
+	// SnapshotInterval is the number of blocks between state sync snapshots. Snapshots are not taken if 0.
+	SnapshotInterval int64
+	// SnapshotKeepRecent is the number of snapshots to retain. All snapshots are retained if 0.
+	SnapshotKeepRecent int
+
+	restoring *restoration
+
+	// validatorSet is the sub-application managing the validator set, if one is registered
+	validatorSet ValidatorSetApp
+	// upgradeSchedule is the sub-application recording upgrade plans, if one is registered
+	upgradeSchedule UpgradeScheduleApp
+	// upgrades are the handlers for the upgrade plans supported by this binary, keyed by app version
+	upgrades map[uint64]Upgrade
+
+	metrics *Metrics
 }
 
 func NewApplication(logger *zap.Logger, db dbm.DB, apps ...MultiplexedApp) *MultiplexedApplication {
 	state := loadState(db)
 
 	appMap := make(map[string]MultiplexedApp)
+	var validatorSet ValidatorSetApp
+	var upgradeSchedule UpgradeScheduleApp
 	for _, app := range apps {
 		appMap[app.Name()] = app
+
+		if app, ok := app.(ValidatorSetApp); ok {
+			validatorSet = app
+		}
+
+		if app, ok := app.(UpgradeScheduleApp); ok {
+			upgradeSchedule = app
+		}
+	}
+
+	app := &MultiplexedApplication{
+		logger:          logger,
+		db:              db,
+		apps:            appMap,
+		state:           state,
+		committed:       newCommittedState(state),
+		validatorSet:    validatorSet,
+		upgradeSchedule: upgradeSchedule,
+		upgrades:        make(map[uint64]Upgrade),
+		metrics:         newMetrics(),
 	}
 
-	return &MultiplexedApplication{
-		logger:     logger,
-		db:         db,
-		apps:       appMap,
-		state:      state,
-		validators: NewValidatorMap(),
+	if err := app.rollbackUncommittedVersions(); err != nil {
+		logger.Fatal("Failed to roll back versions saved by an incomplete commit", zap.Error(err))
 	}
+
+	return app
+}
+
+// SetChainID sets the ID of the chain, for chains that were initialised before the chain ID was recorded in the
+// multiplexer state.
+func (app *MultiplexedApplication) SetChainID(chainId string) {
+	app.state.ChainId = chainId
 }
 
 func (app *MultiplexedApplication) Info(ctx context.Context, req *types.RequestInfo) (*types.ResponseInfo, error) {
@@ -66,21 +117,60 @@ func (app *MultiplexedApplication) Info(ctx context.Context, req *types.RequestI
 	return &types.ResponseInfo{
 		Data:             string(marshalled),
 		Version:          version.ABCIVersion,
-		AppVersion:       AppVersion,
+		AppVersion:       app.state.AppVersion,
 		LastBlockHeight:  app.state.Height,
 		LastBlockAppHash: app.state.GenerateAppHash(),
 	}, nil
 }
 
 func (app *MultiplexedApplication) InitChain(ctx context.Context, req *types.RequestInitChain) (*types.ResponseInitChain, error) {
+	app.state.ChainId = req.ChainId
+
+	genesis, err := app.initGenesis(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// State carried over from another chain keeps the format it was exported in. Otherwise, the app version is taken
+	// from the genesis document, if set.
+	app.state.AppVersion = AppVersion
+	if genesis.AppVersion > 0 {
+		app.state.AppVersion = genesis.AppVersion
+	} else if req.ConsensusParams != nil && req.ConsensusParams.Version != nil && req.ConsensusParams.Version.App > 0 {
+		app.state.AppVersion = req.ConsensusParams.Version.App
+	}
+
+	if app.state.AppVersion > app.supportedVersion() {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, app.state.AppVersion)
+	}
+
 	for _, subApp := range app.apps {
 		app.state.AppHashes[subApp.Name()] = subApp.InitChain(ctx, req)
 	}
 
+	// A validator set carried over from another chain replaces the validators of the genesis document, which are
+	// added to it by InitChain
+	var validators []types.ValidatorUpdate
+	if app.validatorSet != nil {
+		if _, ok := genesis.Apps[app.validatorSet.Name()]; ok {
+			validators = app.validatorSet.Validators()
+		}
+	}
+
+	if len(genesis.Apps) > 0 {
+		app.logger.Info(
+			"Imported genesis app state",
+			zap.String("source_chain_id", genesis.ChainId),
+			zap.Int64("source_height", genesis.Height),
+		)
+	}
+
 	return &types.ResponseInitChain{
-		ConsensusParams: nil,
-		Validators:      nil,
-		AppHash:         app.state.GenerateAppHash(),
+		ConsensusParams: &cmtproto.ConsensusParams{
+			Version: &cmtproto.VersionParams{App: app.state.AppVersion},
+		},
+		Validators: validators,
+		AppHash:    app.state.GenerateAppHash(),
 	}, nil
 }
 
@@ -100,24 +190,46 @@ func (app *MultiplexedApplication) Query(ctx context.Context, req *types.Request
 		return NewErrorResponse(CodeUnknownApp, Codespace, errors.New("unknown app name")).IntoQueryResponse(), nil
 	}
 
-	return subApp.Query(ctx, req)
-}
+	if req.Height > app.state.Height {
+		return NewErrorResponse(
+			CodeHeightUnavailable,
+			Codespace,
+			fmt.Errorf("height %d has not been committed, the latest height is %d", req.Height, app.state.Height),
+		).IntoQueryResponse(), nil
+	}
 
-func (app *MultiplexedApplication) CheckTx(ctx context.Context, req *types.RequestCheckTx) (*types.ResponseCheckTx, error) {
-	isValidatorTx := strings.HasPrefix(string(req.Tx), validatorPrefix)
-	if isValidatorTx {
-		if _, err := parseValidatorTx(string(req.Tx)); err != nil {
-			app.logger.Warn("Error parsing validator tx", zap.Error(err))
-			return NewErrorResponse(CodeInvalidValidatorTx, Codespace, errors.New("error parsing validator tx")).IntoCheckTxResponse(), nil
+	ctx = ContextWithChainID(ContextWithHeight(ctx, app.state.Height), app.state.ChainId)
+
+	// Queries against versioned sub-applications are served from the version saved at the requested height, or the
+	// latest saved version, so that the writes of a block that has been finalized but not committed are not observed.
+	// Sub-applications that do not version their state retain their full history.
+	if versioned, ok := subApp.(VersionedApp); ok {
+		version := versioned.Version()
+		if req.Height > 0 {
+			var errRes *ErrorResponse
+			if version, errRes = app.queryVersion(decoded.App, req.Height); errRes != nil {
+				return errRes.IntoQueryResponse(), nil
+			}
 		}
 
-		return &types.ResponseCheckTx{Code: CodeOk}, nil
+		ctx = ContextWithVersion(ctx, version)
 	}
 
+	res, err := subApp.Query(ctx, req)
+	if err != nil || res == nil || res.ProofOps == nil {
+		return res, err
+	}
+
+	return app.attachMultiplexProof(decoded.App, req, res), nil
+}
+
+func (app *MultiplexedApplication) CheckTx(ctx context.Context, req *types.RequestCheckTx) (*types.ResponseCheckTx, error) {
 	var decoded common.MuxedRequest
 	if err := json.Unmarshal(req.Tx, &decoded); err != nil {
 		app.logger.Warn("Error decoding CheckTx request", zap.Error(err))
-		return NewErrorResponse(CodeEncodingError, Codespace, errors.New("error decoding request")).IntoCheckTxResponse(), nil
+		res := NewErrorResponse(CodeEncodingError, Codespace, errors.New("error decoding request")).IntoCheckTxResponse()
+		app.metrics.observeCheckTx("", res)
+		return res, nil
 	}
 
 	subApp, ok := app.apps[decoded.App]
@@ -126,41 +238,91 @@ func (app *MultiplexedApplication) CheckTx(ctx context.Context, req *types.Reque
 			"Got CheckTx request with unknown app name",
 			zap.String("supplied_name", decoded.App),
 		)
-		return NewErrorResponse(CodeUnknownApp, Codespace, errors.New("unknown app name")).IntoCheckTxResponse(), nil
+		res := NewErrorResponse(CodeUnknownApp, Codespace, errors.New("unknown app name")).IntoCheckTxResponse()
+		app.metrics.observeCheckTx("", res)
+		return res, nil
+	}
+
+	res, err := app.checkTx(ctx, req, subApp, decoded)
+	if err == nil {
+		app.metrics.observeCheckTx(decoded.App, res)
 	}
 
-	return subApp.CheckTx(ctx, req, decoded.Data)
+	return res, err
+}
+
+// checkTx checks a request that has been routed to a sub-application.
+func (app *MultiplexedApplication) checkTx(
+	ctx context.Context,
+	req *types.RequestCheckTx,
+	subApp MultiplexedApp,
+	decoded common.MuxedRequest,
+) (*types.ResponseCheckTx, error) {
+	if _, errRes := app.checkSignatureVersion(decoded); errRes != nil {
+		return errRes.IntoCheckTxResponse(), nil
+	}
+
+	principal, sequence, sequenced := sequencedRequest(decoded, app.state.AppVersion)
+	sequenced = sequenced && app.Sequences != nil
+	if sequenced {
+		if errRes := app.Sequences.ValidateCheckTx(principal, sequence); errRes != nil {
+			app.logger.Warn(
+				"Rejected CheckTx request with invalid sequence number",
+				zap.String("app", decoded.App),
+				zap.String("principal", principal.String()),
+				zap.Uint64("sequence", sequence),
+			)
+			return errRes.IntoCheckTxResponse(), nil
+		}
+	}
+
+	ctx = ContextWithAppVersion(ContextWithHeight(ContextWithChainID(ctx, app.state.ChainId), app.state.Height), app.state.AppVersion)
+	res, err := subApp.CheckTx(ctx, req, decoded.Data)
+	if err == nil && sequenced && res.Code == CodeOk {
+		app.Sequences.Admit(principal, sequence)
+	}
+
+	return res, err
 }
 
 func (app *MultiplexedApplication) FinalizeBlock(ctx context.Context, req *types.RequestFinalizeBlock) (*types.ResponseFinalizeBlock, error) {
-	app.commitFuncs = make([]func() error, 0, len(req.Txs))
 	results := make([]*types.ExecTxResult, len(req.Txs))
 
+	// Upgrades take effect before the transactions of the block at the upgrade height are processed
+	consensusParamUpdates, err := app.applyUpgrade(ctx, req.Height)
+	if err != nil {
+		return nil, err
+	}
+
 	// Punish malicious validators
 	validatorUpdates := make([]types.ValidatorUpdate, 0, len(req.Misbehavior))
 	for _, evidence := range req.Misbehavior {
 		app.logger.Warn("Misbehaviour detected", zap.Any("evidence", evidence))
 
 		if evidence.Type == types.MisbehaviorType_DUPLICATE_VOTE {
-			address := Address(evidence.Validator.Address)
+			address := NewAddress(evidence.Validator.Address)
+
+			if app.validatorSet == nil {
+				app.logger.Error("Got duplicate vote evidence, but no app manages the validator set")
+				continue
+			}
 
-			validator, ok := app.validators.Get(address)
+			update, ok := app.validatorSet.Punish(address, utils.Max(evidence.Validator.Power-1, 0))
 			if !ok {
 				app.logger.Error(
-					"Got duplicate vote evidence for unknown validator",
+					"Could not punish validator for duplicate vote",
 					zap.String("address", address.String()),
 				)
 				continue
 			}
 
-			validatorUpdates = append(validatorUpdates, types.ValidatorUpdate{
-				PubKey: validator.PubKey,
-				Power:  utils.Max(evidence.Validator.Power-1, 0),
-			})
+			validatorUpdates = append(validatorUpdates, update)
 		}
 	}
 
 	var events []types.Event
+	appNames := make([]string, len(req.Txs))
+	signers := make(map[identity.Principal]struct{})
 	for i, tx := range req.Txs {
 		var decoded common.MuxedRequest
 		if err := json.Unmarshal(tx, &decoded); err != nil {
@@ -179,29 +341,96 @@ func (app *MultiplexedApplication) FinalizeBlock(ctx context.Context, req *types
 			continue
 		}
 
-		res := subApp.FinalizeBlock(ctx, req, decoded.Data)
-		if res.CommitFunc != nil {
-			app.commitFuncs = append(app.commitFuncs, res.CommitFunc)
+		appNames[i] = decoded.App
+
+		legacy, errRes := app.checkSignatureVersion(decoded)
+		if errRes != nil {
+			errTxResult := errRes.IntoFinalizeBlockResponse().TxResult
+			results[i] = &errTxResult
+			continue
+		}
+
+		principal, sequence, sequenced := sequencedRequest(decoded, app.state.AppVersion)
+		unsequenced := legacy && !sequenced
+		sequenced = sequenced && app.Sequences != nil
+		if sequenced {
+			if errRes := app.Sequences.ValidateFinalize(principal, sequence); errRes != nil {
+				app.logger.Warn(
+					"Rejected DeliverTx request with invalid sequence number",
+					zap.String("app", decoded.App),
+					zap.String("principal", principal.String()),
+					zap.Uint64("sequence", sequence),
+				)
+
+				errTxResult := errRes.IntoFinalizeBlockResponse().TxResult
+				results[i] = &errTxResult
+				continue
+			}
+		}
+
+		txCtx := ContextWithSigners(ContextWithAppVersion(ContextWithChainID(ctx, app.state.ChainId), app.state.AppVersion), signers)
+		res := subApp.FinalizeBlock(txCtx, req, decoded.Data)
+		if legacy && res.TxResult.Code == CodeOk {
+			res.TxResult.Events = append(res.TxResult.Events, legacySignatureEvent(decoded.App))
+		}
+
+		// Legacy requests without a sequence number could have been replayed, which the chain cannot detect
+		if unsequenced && res.TxResult.Code == CodeOk {
+			app.logger.Warn(
+				"Accepted legacy request without a sequence number, which is not protected against replays",
+				zap.String("app", decoded.App),
+				zap.String("principal", principal.String()),
+			)
+			app.metrics.unsequenced.WithLabelValues(decoded.App).Inc()
+		}
+
+		// Only successful requests consume a sequence number, so that a client may resubmit a rejected request
+		if sequenced && res.TxResult.Code == CodeOk {
+			if err := app.Sequences.Reserve(principal, sequence); err != nil {
+				return nil, err
+			}
+		}
+
+		if res.TxResult.Code == CodeOk {
+			validatorUpdates = append(validatorUpdates, res.ValidatorUpdates...)
+
+			if signer, ok := requestSigner(decoded); ok {
+				signers[signer] = struct{}{}
+			}
 		}
 
 		events = append(events, res.TxResult.Events...)
-		app.state.AppHashes[subApp.Name()] = res.AppHash
 		results[i] = &res.TxResult
 
 		app.logger.Info(
 			"Ran FinalizeBlock for app",
 			zap.String("app", subApp.Name()),
-			zap.String("app_hash", hex.EncodeToString(res.AppHash)),
+			zap.Uint32("code", res.TxResult.Code),
 		)
 	}
 
+	for i, result := range results {
+		app.metrics.observeFinalizeBlock(appNames[i], result)
+	}
+
+	// The app hash reflects the writes of every transaction in the block, which may touch apps other than the one
+	// that each transaction was routed to
+	for name, subApp := range app.apps {
+		appHash, err := subApp.WorkingHash()
+		if err != nil {
+			return nil, fmt.Errorf("error getting working hash of %s app: %w", name, err)
+		}
+
+		app.state.AppHashes[name] = appHash
+	}
+
 	app.state.Height = req.Height
 
 	res := &types.ResponseFinalizeBlock{
 		Events:                nil,
 		TxResults:             results,
 		ValidatorUpdates:      validatorUpdates,
-		ConsensusParamUpdates: nil,
+		ConsensusParamUpdates: consensusParamUpdates,
 		AppHash:               app.state.GenerateAppHash(),
 	}
 
@@ -211,13 +440,47 @@ func (app *MultiplexedApplication) FinalizeBlock(ctx context.Context, req *types
 }
 
 func (app *MultiplexedApplication) Commit(ctx context.Context, commit *types.RequestCommit) (*types.ResponseCommit, error) {
-	for _, commitFunc := range app.commitFuncs {
-		if err := commitFunc(); err != nil {
-			return nil, err
+	// Sub-applications persist their state to separate databases, so cannot be committed in a single write. The
+	// multiplexer state is saved last: versions saved by sub-applications for a block whose commit did not complete
+	// are rolled back on startup, and the block replayed.
+	for _, name := range utils.SortedKeys(app.apps) {
+		start := time.Now()
+		if err := app.apps[name].Commit(); err != nil {
+			return nil, fmt.Errorf("error committing %s app: %w", name, err)
+		}
+
+		app.metrics.observeCommit(name, start)
+
+		if sized, ok := app.apps[name].(SizedApp); ok {
+			app.metrics.treeSize.WithLabelValues(name).Set(float64(sized.Size()))
+		}
+	}
+
+	if err := app.recordVersions(); err != nil {
+		return nil, err
+	}
+
+	if err := app.recordAppHashes(); err != nil {
+		return nil, err
+	}
+
+	// A failure to prune does not affect consensus, so is not fatal
+	if app.Pruning.KeepRecent > 0 && app.Pruning.Interval > 0 && app.state.Height%app.Pruning.Interval == 0 {
+		if err := app.prune(); err != nil {
+			app.logger.Error("Failed to prune app versions", zap.Error(err), zap.Int64("height", app.state.Height))
 		}
 	}
 
 	saveState(app.state)
+	app.committed = newCommittedState(app.state)
+	app.metrics.height.Set(float64(app.state.Height))
+
+	// A failure to take a snapshot does not affect consensus, so is not fatal
+	if app.SnapshotInterval > 0 && app.state.Height%app.SnapshotInterval == 0 {
+		if err := app.createSnapshot(); err != nil {
+			app.logger.Error("Failed to create state sync snapshot", zap.Error(err), zap.Int64("height", app.state.Height))
+		}
+	}
 
 	resp := &types.ResponseCommit{}
 	if app.RetainBlocks > 0 && app.state.Height >= app.RetainBlocks {
@@ -227,31 +490,53 @@ func (app *MultiplexedApplication) Commit(ctx context.Context, commit *types.Req
 	return resp, nil
 }
 
-// Syntax: val:keytype!base64pubkey!power
-func parseValidatorTx(tx string) (types.ValidatorUpdate, error) {
-	// Trim val: prefix
-	tx = strings.TrimPrefix(tx, validatorPrefix)
-
-	parts := strings.Split(tx, "!")
-	if len(parts) != 3 {
-		return types.ValidatorUpdate{}, fmt.Errorf("invalid validator tx: %s", tx)
+// checkSignatureVersion inspects the signature version of a signed request before it is routed to a sub-application.
+// Legacy signatures, covering the payload data alone, are flagged during the transition period and rejected once the
+// chain reaches common.LegacyRejectionAppVersion. Unsigned requests are not affected.
+func (app *MultiplexedApplication) checkSignatureVersion(req common.MuxedRequest) (bool, *ErrorResponse) {
+	var peek struct {
+		Principal string                  `json:"principal"`
+		Signature string                  `json:"signature"`
+		Version   common.SignatureVersion `json:"version"`
 	}
 
-	keyType, pubKeyString, powerString := parts[0], parts[1], parts[2]
-
-	var decodedKey []byte
-	if _, err := base64.StdEncoding.Decode(decodedKey, []byte(pubKeyString)); err != nil {
-		return types.ValidatorUpdate{}, fmt.Errorf("error decoding base64 pubkey: %w", err)
+	if err := json.Unmarshal(req.Data, &peek); err != nil || peek.Signature == "" {
+		// Malformed or unsigned requests are handled by the sub-application
+		return false, nil
 	}
 
-	power, err := strconv.ParseInt(powerString, 10, 64)
-	if err != nil {
-		return types.ValidatorUpdate{}, fmt.Errorf("error parsing power: %w", err)
-	}
+	switch peek.Version {
+	case common.SignatureVersionLegacy:
+		if app.state.AppVersion >= common.LegacyRejectionAppVersion {
+			return false, NewErrorResponse(
+				common.CodeLegacySignatureRejected,
+				common.Codespace,
+				errors.New("legacy signatures are no longer accepted, the request must be signed with domain-separated sign bytes"),
+			)
+		}
+
+		app.logger.Warn(
+			"Accepted request with legacy signature",
+			zap.String("app", req.App),
+			zap.String("principal", peek.Principal),
+		)
 
-	if power < 0 {
-		return types.ValidatorUpdate{}, fmt.Errorf("power must be non-negative")
+		return true, nil
+	case common.SignatureVersionV1:
+		return false, nil
+	default:
+		return false, NewErrorResponse(
+			common.CodeUnsupportedSignatureVersion,
+			common.Codespace,
+			fmt.Errorf("%w: %d", common.ErrUnsupportedSignatureVersion, peek.Version),
+		)
 	}
+}
 
-	return types.UpdateValidator(decodedKey, power, keyType), nil
+func legacySignatureEvent(appName string) types.Event {
+	return utils.Event("legacy_signature", types.EventAttribute{
+		Key:   "app",
+		Value: appName,
+		Index: true,
+	})
 }
@@ -14,12 +14,21 @@ type MultiplexedApp interface {
 	CheckTx(ctx context.Context, req *types.RequestCheckTx, data json.RawMessage) (*types.ResponseCheckTx, error)
 	FinalizeBlock(ctx context.Context, req *types.RequestFinalizeBlock, data json.RawMessage) FinalizeBlockResponse
 	Query(ctx context.Context, req *types.RequestQuery) (*types.ResponseQuery, error)
+	// WorkingHash returns the hash of the app's working state, including the writes applied by FinalizeBlock in the
+	// current block.
+	WorkingHash() ([]byte, error)
+	// Commit persists the writes applied by FinalizeBlock in the current block, and resets any state tracking changes
+	// made in the block. Called once per block, for every app.
+	Commit() error
 }
 
+// FinalizeBlockResponse is the result of a single transaction. Sub-applications apply the writes of successful
+// transactions to their working state during FinalizeBlock, so that later transactions in the block observe them and
+// the app hash of the block reflects them. The working state is persisted when the block is committed.
 type FinalizeBlockResponse struct {
-	TxResult   types.ExecTxResult
-	AppHash    []byte
-	CommitFunc func() error
+	TxResult types.ExecTxResult
+	// ValidatorUpdates are applied to the validator set if the transaction succeeds.
+	ValidatorUpdates []types.ValidatorUpdate
 }
 
 type ErrorResponse struct {
@@ -56,16 +65,15 @@ func (e ErrorResponse) IntoFinalizeBlockResponse() FinalizeBlockResponse {
 	}
 
 	return FinalizeBlockResponse{
-		TxResult:   txResult,
-		AppHash:    nil,
-		CommitFunc: nil,
+		TxResult: txResult,
 	}
 }
 
 func (e ErrorResponse) IntoCheckTxResponse() *types.ResponseCheckTx {
 	return &types.ResponseCheckTx{
-		Code: e.Code,
-		Log:  e.Log,
+		Code:      e.Code,
+		Log:       e.Log,
+		Codespace: e.Codespace,
 	}
 }
 
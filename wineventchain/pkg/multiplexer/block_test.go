@@ -0,0 +1,172 @@
+package multiplexer_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// Transactions later in a block must observe the writes of earlier transactions, including writes to other apps
+func TestBlockDependentTransactions(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	res := chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent),
+		harness.CreateEventTx(t, agent, 0, 1),
+		harness.CreateEventTx(t, agent, 1, 2),
+		harness.CreateEventTx(t, agent, 2, 3),
+	)
+	harness.RequireCodes(t, res, 0, 0, 0, 0, 0)
+
+	for _, eventId := range harness.EventIds(t, res, 2) {
+		queried := chain.Query(eventtypes.AppName, "/event-by-id/"+eventId.String())
+		require.Equal(t, uint32(0), queried.Code, queried.Log)
+	}
+
+	sequence := chain.Query(identitytypes.AppName, identitytypes.QueryPathSequence+string(agent.Name))
+	require.Equal(t, uint32(0), sequence.Code, sequence.Log)
+
+	var decoded identitytypes.SequenceResponse
+	require.NoError(t, json.Unmarshal(sequence.Value, &decoded))
+	require.Equal(t, uint64(3), decoded.Sequence)
+}
+
+// The app hash reported by FinalizeBlock must commit to every write in the block, and match the app hash reported
+// after the block is committed
+func TestBlockAppHash(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	genesisHash := chain.LastAppHash()
+
+	res := chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+	require.NotEqual(t, genesisHash, res.AppHash)
+	require.Equal(t, res.AppHash, chain.LastAppHash())
+
+	res = chain.Block(harness.CreateEventTx(t, agent, 0, 1), harness.CreateEventTx(t, agent, 1, 2))
+	harness.RequireCodes(t, res, 0, 0)
+	require.Equal(t, res.AppHash, chain.LastAppHash())
+
+	// A block without transactions leaves the app hash unchanged
+	empty := chain.Block()
+	require.Equal(t, res.AppHash, empty.AppHash)
+}
+
+// Queries for data written by a block must be provable against the app hash reported by FinalizeBlock for the block
+func TestBlockProofs(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	res := chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent),
+		harness.CreateEventTx(t, agent, 0, 1),
+		harness.CreateEventTx(t, agent, 1, 2),
+	)
+	harness.RequireCodes(t, res, 0, 0, 0, 0)
+
+	for _, eventId := range harness.EventIds(t, res, 2) {
+		queried := chain.Query(eventtypes.AppName, "/event-by-id/"+eventId.String())
+		require.Equal(t, uint32(0), queried.Code, queried.Log)
+		require.Equal(t, chain.Height, queried.Height)
+
+		value, err := proof.VerifyChain(queried.ProofOps, eventtypes.AppName, eventId, res.AppHash, multiplexer.AppVersion)
+		require.NoError(t, err)
+		require.Equal(t, queried.Value, value)
+	}
+
+	queried := chain.Query(identitytypes.AppName, "/"+string(agent.Name))
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	value, err := proof.VerifyChain(queried.ProofOps, identitytypes.AppName, agent.Name.TreeKey(), res.AppHash, multiplexer.AppVersion)
+	require.NoError(t, err)
+	require.NotNil(t, value)
+}
+
+// The writes of a block that has been finalized but not committed must not be observed by queries
+func TestBlockUncommittedWrites(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent),
+		harness.CreateEventTx(t, agent, 0, 1),
+	)
+	committedHash := chain.LastAppHash()
+
+	res, err := chain.App.FinalizeBlock(context.Background(), &abci.RequestFinalizeBlock{
+		Txs:    [][]byte{harness.CreateEventTx(t, agent, 1, 2)},
+		Height: chain.Height + 1,
+		Time:   time.Now(),
+	})
+	require.NoError(t, err)
+	harness.RequireCodes(t, res, 0)
+
+	var created eventtypes.CreateResponse
+	require.NoError(t, json.Unmarshal(res.TxResults[0].Data, &created))
+
+	queried := chain.Query(eventtypes.AppName, "/event-by-id/"+created.Metadata.EventId.String())
+	require.Equal(t, eventtypes.CodeEventNotFound, queried.Code)
+
+	value, err := proof.VerifyChain(queried.ProofOps, eventtypes.AppName, created.Metadata.EventId, committedHash, multiplexer.AppVersion)
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+// Restarting from the committed databases must restore the same app hash, and the same working state
+func TestBlockRestart(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+	res := chain.Block(harness.CreateEventTx(t, agent, 0, 1), harness.CreateEventTx(t, agent, 1, 2))
+	harness.RequireCodes(t, res, 0, 0)
+
+	chain.Start()
+	require.Equal(t, res.AppHash, chain.LastAppHash())
+
+	res = chain.Block(harness.CreateEventTx(t, agent, 2, 3))
+	harness.RequireCodes(t, res, 0)
+	require.Equal(t, res.AppHash, chain.LastAppHash())
+}
+
+// A node that stops part-way through Commit must roll back the versions saved for the block, so that the block can be
+// replayed to the same app hash
+func TestBlockIncompleteCommit(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+	committedHash := chain.LastAppHash()
+
+	// Save the state of the multiplexer before the block, then commit the block as normal
+	state, err := chain.DBs["state"].Get([]byte("muxer_state"))
+	require.NoError(t, err)
+	require.NotNil(t, state)
+
+	txs := [][]byte{harness.CreateEventTx(t, agent, 0, 1), harness.CreateEventTx(t, agent, 1, 2)}
+	res := chain.Block(txs...)
+	harness.RequireCodes(t, res, 0, 0)
+
+	// Restore the multiplexer state, as if the node had stopped after the sub-applications were committed
+	require.NoError(t, chain.DBs["state"].Set([]byte("muxer_state"), state))
+	chain.Height--
+
+	chain.Start()
+	require.Equal(t, committedHash, chain.LastAppHash())
+
+	replayed := chain.Block(txs...)
+	harness.RequireCodes(t, replayed, 0, 0)
+	require.Equal(t, res.AppHash, replayed.AppHash)
+}
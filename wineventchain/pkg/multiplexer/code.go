@@ -7,5 +7,7 @@ const (
 	CodeUnknownError
 	CodeEncodingError
 	CodeUnknownApp
-	CodeInvalidValidatorTx
+	CodeInvalidValidatorTx // No longer returned, as validator transactions are handled by the validators app
+	CodeHeightPruned
+	CodeHeightUnavailable
 )
@@ -0,0 +1,96 @@
+package multiplexer
+
+import (
+	"context"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+)
+
+type contextKey int
+
+const (
+	heightContextKey contextKey = iota
+	chainIdContextKey
+	versionContextKey
+	appVersionContextKey
+	signersContextKey
+)
+
+// ContextWithHeight returns a copy of ctx carrying the height of the latest block that has been finalized, for
+// sub-applications whose state depends on the current height.
+func ContextWithHeight(ctx context.Context, height int64) context.Context {
+	return context.WithValue(ctx, heightContextKey, height)
+}
+
+// HeightFromContext returns the latest finalized block height carried by ctx, or 0 if none is present.
+func HeightFromContext(ctx context.Context) int64 {
+	height, ok := ctx.Value(heightContextKey).(int64)
+	if !ok {
+		return 0
+	}
+
+	return height
+}
+
+// ContextWithChainID returns a copy of ctx carrying the ID of the chain, which sub-applications require to validate
+// the signatures on requests.
+func ContextWithChainID(ctx context.Context, chainId string) context.Context {
+	return context.WithValue(ctx, chainIdContextKey, chainId)
+}
+
+// ChainIDFromContext returns the chain ID carried by ctx, or an empty string if none is present.
+func ChainIDFromContext(ctx context.Context) string {
+	chainId, ok := ctx.Value(chainIdContextKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return chainId
+}
+
+// ContextWithVersion returns a copy of ctx carrying the saved version of a sub-application's state that reflects the
+// height requested by a query: the latest saved version for queries for the latest state, which excludes the writes
+// of a block that is being finalized.
+func ContextWithVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, versionContextKey, version)
+}
+
+// VersionFromContext returns the saved version carried by ctx, which is 0 if no version has been saved. Returns false
+// if ctx does not carry a version, in which case the query is served from the working state.
+func VersionFromContext(ctx context.Context) (int64, bool) {
+	version, ok := ctx.Value(versionContextKey).(int64)
+	return version, ok
+}
+
+// ContextWithAppVersion returns a copy of ctx carrying the app version of the state against which a request is
+// processed.
+func ContextWithAppVersion(ctx context.Context, appVersion uint64) context.Context {
+	return context.WithValue(ctx, appVersionContextKey, appVersion)
+}
+
+// AppVersionFromContext returns the app version carried by ctx, or the initial app version if none is present.
+func AppVersionFromContext(ctx context.Context) uint64 {
+	appVersion, ok := ctx.Value(appVersionContextKey).(uint64)
+	if !ok {
+		return AppVersion
+	}
+
+	return appVersion
+}
+
+// ContextWithSigners returns a copy of ctx carrying the principals that signed the successful requests earlier in the
+// block being finalized.
+func ContextWithSigners(ctx context.Context, signers map[identity.Principal]struct{}) context.Context {
+	return context.WithValue(ctx, signersContextKey, signers)
+}
+
+// SignedInBlock returns true if the principal signed a successful request earlier in the block being finalized, as
+// carried by ctx.
+func SignedInBlock(ctx context.Context, principal identity.Principal) bool {
+	signers, ok := ctx.Value(signersContextKey).(map[identity.Principal]struct{})
+	if !ok {
+		return false
+	}
+
+	_, ok = signers[principal]
+	return ok
+}
@@ -0,0 +1,97 @@
+package multiplexer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/cometbft/cometbft/abci/types"
+)
+
+var ErrInvalidInitialHeight = errors.New("invalid initial height")
+
+// GenesisApp is implemented by sub-applications whose state can be carried over to a new chain through the genesis
+// app state, allowing the chain to be hard-forked without losing its history.
+type GenesisApp interface {
+	MultiplexedApp
+	// ExportGenesis exports the committed state of the app. The export must be deterministic, so that nodes with the
+	// same state produce identical genesis files.
+	ExportGenesis() (json.RawMessage, error)
+	// InitGenesis writes exported state to the working state of the app, which must not have processed any blocks.
+	// Called before InitChain, which saves the imported state.
+	InitGenesis(data json.RawMessage) error
+}
+
+// GenesisState is the app state carried by the genesis document of a chain, as written by the export command.
+type GenesisState struct {
+	// ChainId and Height identify the chain and block height from which the state was exported.
+	ChainId string `json:"chain_id"`
+	Height  int64  `json:"height"`
+	// AppVersion is the format of the exported state, which the new chain continues from.
+	AppVersion uint64                     `json:"app_version"`
+	Apps       map[string]json.RawMessage `json:"apps"`
+}
+
+// ExportGenesis exports the committed state of every genesis sub-application, for use as the app state of a new
+// chain's genesis document. The application must not be running, so that the state does not change during the export.
+func (app *MultiplexedApplication) ExportGenesis() ([]byte, error) {
+	state := GenesisState{
+		ChainId:    app.state.ChainId,
+		Height:     app.state.Height,
+		AppVersion: app.state.AppVersion,
+		Apps:       make(map[string]json.RawMessage),
+	}
+
+	for _, name := range utils.SortedKeys(app.apps) {
+		genesisApp, ok := app.apps[name].(GenesisApp)
+		if !ok {
+			continue
+		}
+
+		exported, err := genesisApp.ExportGenesis()
+		if err != nil {
+			return nil, fmt.Errorf("error exporting %s app: %w", name, err)
+		}
+
+		state.Apps[name] = exported
+	}
+
+	// Maps are marshalled in key order, so the output is deterministic
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// initGenesis imports the app state of the genesis document into each genesis sub-application. Chains started without
+// app state begin empty.
+func (app *MultiplexedApplication) initGenesis(req *types.RequestInitChain) (GenesisState, error) {
+	var state GenesisState
+	if len(req.AppStateBytes) == 0 {
+		return state, nil
+	}
+
+	if err := json.Unmarshal(req.AppStateBytes, &state); err != nil {
+		return state, fmt.Errorf("error decoding genesis app state: %w", err)
+	}
+
+	// Events carried over were created at heights up to the exported height, so the heights of the new chain must
+	// follow it, for the heights of events to be unambiguous
+	if state.Height > 0 && req.InitialHeight <= state.Height {
+		return state, fmt.Errorf(
+			"%w: initial height %d must be after the exported height %d",
+			ErrInvalidInitialHeight, req.InitialHeight, state.Height,
+		)
+	}
+
+	for name := range state.Apps {
+		if _, ok := app.apps[name].(GenesisApp); !ok {
+			return state, fmt.Errorf("genesis app state contains unknown app %s", name)
+		}
+	}
+
+	for _, name := range utils.SortedKeys(state.Apps) {
+		if err := app.apps[name].(GenesisApp).InitGenesis(state.Apps[name]); err != nil {
+			return state, fmt.Errorf("error importing genesis state of %s app: %w", name, err)
+		}
+	}
+
+	return state, nil
+}
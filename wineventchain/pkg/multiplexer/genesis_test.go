@@ -0,0 +1,75 @@
+package multiplexer_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	retentiontypes "github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// State exported from one chain must be imported by the genesis of another, carrying over identities, sequence numbers,
+// events and legal holds
+func TestGenesisExport(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	res := chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent),
+		harness.CreateEventTx(t, agent, 0, 1),
+		harness.SignedTx(t, admin, 1, retentiontypes.AppName, retentiontypes.RequestTypePlaceHold, retentiontypes.PlaceHoldRequest{
+			Criteria: offchain.HoldCriteria{Channels: []string{"Security"}},
+			Reason:   "incident 42",
+		}),
+	)
+	harness.RequireCodes(t, res, 0, 0, 0, 0)
+	eventId := harness.EventIds(t, res, 2)[0]
+
+	exported, err := chain.App.ExportGenesis()
+	require.NoError(t, err)
+
+	var state multiplexer.GenesisState
+	require.NoError(t, json.Unmarshal(exported, &state))
+	require.Equal(t, harness.ChainID, state.ChainId)
+	require.Equal(t, chain.Height, state.Height)
+
+	// The new chain must start after the exported height
+	_, err = harness.NewUninitialised(t).App.InitChain(context.Background(), &abci.RequestInitChain{
+		ChainId:       harness.ChainID,
+		AppStateBytes: exported,
+		InitialHeight: state.Height,
+	})
+	require.ErrorIs(t, err, multiplexer.ErrInvalidInitialHeight)
+
+	forked := harness.NewFromGenesis(t, exported, state.Height+1)
+
+	// Re-exporting the imported state must produce the same content
+	reExported, err := forked.App.ExportGenesis()
+	require.NoError(t, err)
+
+	var forkedState multiplexer.GenesisState
+	require.NoError(t, json.Unmarshal(reExported, &forkedState))
+	require.Equal(t, state.Apps, forkedState.Apps)
+
+	queried := forked.Query(eventtypes.AppName, "/event-by-id/"+eventId.String())
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	queried = forked.Query(retentiontypes.AppName, retentiontypes.QueryPathHolds)
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	var holds offchain.LegalHolds
+	require.NoError(t, json.Unmarshal(queried.Value, &holds))
+	require.Len(t, holds, 1)
+
+	// Sequence numbers are carried over, so requests from the original chain cannot be replayed
+	res = forked.Block(harness.CreateEventTx(t, agent, 0, 2), harness.CreateEventTx(t, agent, 1, 3))
+	require.Equal(t, identitytypes.CodeInvalidSequence, res.TxResults[0].Code)
+	require.Equal(t, uint32(0), res.TxResults[1].Code, res.TxResults[1].Log)
+}
@@ -0,0 +1,113 @@
+package multiplexer
+
+import (
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/cometbft/cometbft/abci/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+// MetricsNamespace prefixes the names of every metric exported by the application, distinguishing them from the
+// metrics exported by CometBFT.
+const MetricsNamespace = "wineventchain"
+
+// MetricsApp is implemented by sub-applications that export metrics of their own.
+type MetricsApp interface {
+	MultiplexedApp
+	// Collectors returns the collectors of the app's metrics, which are registered alongside those of the multiplexer.
+	Collectors() []prometheus.Collector
+}
+
+// SizedApp is implemented by sub-applications backed by a merkle tree, whose size is exported after each commit.
+type SizedApp interface {
+	MultiplexedApp
+	// Size returns the number of entries in the app's tree.
+	Size() int64
+}
+
+// Metrics are recorded by the multiplexer for every sub-application. Requests that could not be routed to a
+// sub-application are recorded with an empty app label.
+type Metrics struct {
+	checkTx       *prometheus.CounterVec
+	finalizeBlock *prometheus.CounterVec
+	commitTime    *prometheus.HistogramVec
+	treeSize      *prometheus.GaugeVec
+	height        prometheus.Gauge
+	unsequenced   *prometheus.CounterVec
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{
+		checkTx: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "check_tx_total",
+			Help:      "Number of transactions checked by CheckTx, by app and response code.",
+		}, []string{"app", "codespace", "code"}),
+		finalizeBlock: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "finalize_block_tx_total",
+			Help:      "Number of transactions executed by FinalizeBlock, by app and result code.",
+		}, []string{"app", "codespace", "code"}),
+		commitTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Name:      "commit_duration_seconds",
+			Help:      "Time taken by each app to save its state when a block is committed.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"app"}),
+		treeSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "tree_size",
+			Help:      "Number of entries in the merkle tree of each app, as of the latest commit.",
+		}, []string{"app"}),
+		height: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "height",
+			Help:      "Height of the latest committed block.",
+		}),
+		unsequenced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "unsequenced_tx_total",
+			Help:      "Number of legacy transactions executed without a sequence number, and so without replay protection, by app.",
+		}, []string{"app"}),
+	}
+}
+
+// RegisterMetrics registers the metrics of the multiplexer and of each sub-application with the registerer. Metrics
+// are recorded whether or not they are registered.
+func (app *MultiplexedApplication) RegisterMetrics(registerer prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		app.metrics.checkTx,
+		app.metrics.finalizeBlock,
+		app.metrics.commitTime,
+		app.metrics.treeSize,
+		app.metrics.height,
+		app.metrics.unsequenced,
+	}
+
+	for _, name := range utils.SortedKeys(app.apps) {
+		if metricsApp, ok := app.apps[name].(MetricsApp); ok {
+			collectors = append(collectors, metricsApp.Collectors()...)
+		}
+	}
+
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Metrics) observeCheckTx(app string, res *types.ResponseCheckTx) {
+	m.checkTx.WithLabelValues(app, res.Codespace, strconv.FormatUint(uint64(res.Code), 10)).Inc()
+}
+
+func (m *Metrics) observeFinalizeBlock(app string, res *types.ExecTxResult) {
+	m.finalizeBlock.WithLabelValues(app, res.Codespace, strconv.FormatUint(uint64(res.Code), 10)).Inc()
+}
+
+func (m *Metrics) observeCommit(app string, start time.Time) {
+	m.commitTime.WithLabelValues(app).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,74 @@
+package multiplexer_test
+
+import (
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Metrics must count transactions by app and code, and report the state of each app after commit
+func TestMetrics(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, chain.App.RegisterMetrics(registry))
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+	chain.Block(harness.CreateEventTx(t, agent, 0, 1), harness.CreateEventTx(t, agent, 0, 2), harness.CreateEventTx(t, agent, 1, 5))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	metrics := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+
+	labels := func(metric *dto.Metric) map[string]string {
+		values := make(map[string]string)
+		for _, label := range metric.GetLabel() {
+			values[label.GetName()] = label.GetValue()
+		}
+
+		return values
+	}
+
+	txCounts := make(map[string]float64)
+	for _, metric := range metrics["wineventchain_finalize_block_tx_total"] {
+		values := labels(metric)
+		txCounts[values["app"]+"/"+values["code"]] += metric.GetCounter().GetValue()
+	}
+
+	require.Equal(t, float64(2), txCounts["identity/0"])
+	require.Equal(t, float64(2), txCounts[eventtypes.AppName+"/0"])
+	require.Equal(t, float64(1), txCounts[eventtypes.AppName+"/"+fmt.Sprint(identitytypes.CodeInvalidSequence)])
+
+	require.Equal(t, float64(2), metrics["wineventchain_events_created_total"][0].GetCounter().GetValue())
+	require.Equal(t, float64(3), metrics["wineventchain_events_missing_records_total"][0].GetCounter().GetValue())
+	require.Equal(t, float64(2), metrics["wineventchain_height"][0].GetGauge().GetValue())
+
+	identities := make(map[string]float64)
+	for _, metric := range metrics["wineventchain_identity_identities"] {
+		identities[labels(metric)["status"]] = metric.GetGauge().GetValue()
+	}
+
+	require.Equal(t, map[string]float64{
+		string(identitytypes.StatusActive):    2,
+		string(identitytypes.StatusSuspended): 0,
+		string(identitytypes.StatusRevoked):   0,
+	}, identities)
+
+	sizes := make(map[string]float64)
+	for _, metric := range metrics["wineventchain_tree_size"] {
+		sizes[labels(metric)["app"]] = metric.GetGauge().GetValue()
+	}
+
+	require.Positive(t, sizes[eventtypes.AppName])
+	require.Positive(t, sizes["identity"])
+}
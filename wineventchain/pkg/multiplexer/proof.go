@@ -0,0 +1,112 @@
+package multiplexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	"github.com/cometbft/cometbft/abci/types"
+	"go.uber.org/zap"
+)
+
+const appHashesPrefix = "app_hashes:"
+
+// committedState is the state as of the latest Commit, against which queries for the latest state are proven. The
+// working state is updated during FinalizeBlock, before sub-application state has been committed.
+type committedState struct {
+	height    int64
+	appHashes map[string][]byte
+}
+
+func newCommittedState(state State) committedState {
+	appHashes := make(map[string][]byte, len(state.AppHashes))
+	for name, hash := range state.AppHashes {
+		appHashes[name] = hash
+	}
+
+	return committedState{
+		height:    state.Height,
+		appHashes: appHashes,
+	}
+}
+
+// recordAppHashes records the sub-application roots at the current height, so that historical queries can be proven
+// against the app hash of the height. Only changes are recorded.
+func (app *MultiplexedApplication) recordAppHashes() error {
+	recorded, err := app.appHashesAt(app.state.Height)
+	if err != nil {
+		return err
+	}
+
+	if recorded != nil && bytes.Equal(proof.MultiplexAppHash(app.state.AppVersion, recorded), app.state.GenerateAppHash()) {
+		return nil
+	}
+
+	marshalled, err := json.Marshal(app.state.AppHashes)
+	if err != nil {
+		return err
+	}
+
+	return app.db.Set(appHashesKey(app.state.Height), marshalled)
+}
+
+// appHashesAt returns the sub-application roots at the given height, or nil if none were recorded at or before it.
+func (app *MultiplexedApplication) appHashesAt(height int64) (map[string][]byte, error) {
+	iterator, err := app.db.ReverseIterator(utils.Bytes(appHashesPrefix), appHashesKey(height+1))
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	if !iterator.Valid() {
+		return nil, iterator.Error()
+	}
+
+	var appHashes map[string][]byte
+	if err := json.Unmarshal(iterator.Value(), &appHashes); err != nil {
+		return nil, err
+	}
+
+	return appHashes, nil
+}
+
+// attachMultiplexProof appends a proof of the sub-application's root within the app hash to a query response carrying
+// a proof from the sub-application's tree, and sets the height of the state that the response reflects. The app hash
+// of the state at height H is committed to by the header of block H+1. Listing queries, which prove each entry within
+// their value, return empty proof operations, to which only the multiplex proof is attached.
+func (app *MultiplexedApplication) attachMultiplexProof(name string, req *types.RequestQuery, res *types.ResponseQuery) *types.ResponseQuery {
+	height, appHashes := app.committed.height, app.committed.appHashes
+	if req.Height > 0 {
+		var err error
+		height = req.Height
+
+		appHashes, err = app.appHashesAt(req.Height)
+		if err != nil {
+			app.logger.Error("Got error reading recorded app hashes", zap.Error(err), zap.Int64("height", req.Height))
+			return NewErrorResponse(CodeUnknownError, Codespace, err).IntoQueryResponse()
+		}
+
+		if appHashes == nil {
+			return NewErrorResponse(
+				CodeHeightUnavailable,
+				Codespace,
+				fmt.Errorf("app hashes at height %d were not recorded", req.Height),
+			).IntoQueryResponse()
+		}
+	}
+
+	op, err := proof.ProofOpForMultiplex(name, appHashes)
+	if err != nil {
+		return NewErrorResponse(CodeUnknownError, Codespace, err).IntoQueryResponse()
+	}
+
+	res.ProofOps.Ops = append(res.ProofOps.Ops, op)
+	res.Height = height
+	return res
+}
+
+// Heights are zero-padded so that records are iterated in ascending height order
+func appHashesKey(height int64) []byte {
+	return utils.Bytes(fmt.Sprintf("%s%020d", appHashesPrefix, height))
+}
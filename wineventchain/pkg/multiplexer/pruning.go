@@ -0,0 +1,234 @@
+package multiplexer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"go.uber.org/zap"
+)
+
+// VersionedApp is implemented by sub-applications that store their state in versioned trees, allowing versions that
+// are no longer required to be pruned.
+type VersionedApp interface {
+	MultiplexedApp
+	// Version returns the latest saved version of the app's state.
+	Version() int64
+	// AvailableVersions returns the saved versions that have not been pruned, in ascending order.
+	AvailableVersions() []int64
+	// DeleteVersions prunes the given saved versions.
+	DeleteVersions(versions ...int64) error
+	// LoadVersionForOverwriting deletes every version saved after the given version, which becomes the latest.
+	LoadVersionForOverwriting(version int64) error
+}
+
+// PruningOptions controls which historical versions of sub-application state are retained. Versions are retained by
+// block height: as sub-applications do not save a version at every height, the version retained for a height is the
+// latest version saved at or before it.
+type PruningOptions struct {
+	// KeepRecent is the number of most recent heights for which state is retained. State is never pruned if 0.
+	KeepRecent int64
+	// KeepEvery additionally retains the state at every KeepEvery-th height, if non-zero.
+	KeepEvery int64
+	// Interval is the number of blocks between pruning runs.
+	Interval int64
+}
+
+const versionPrefix = "version:"
+
+// IsPruned returns true if the state at the given height has been pruned, and can no longer be queried.
+func (app *MultiplexedApplication) IsPruned(height int64) bool {
+	if height > app.state.PrunedHeight {
+		return false
+	}
+
+	return app.Pruning.KeepEvery <= 0 || height%app.Pruning.KeepEvery != 0
+}
+
+// queryVersion returns the version of the sub-application's state at the given height, for historical queries.
+func (app *MultiplexedApplication) queryVersion(name string, height int64) (int64, *ErrorResponse) {
+	if app.IsPruned(height) {
+		return 0, NewErrorResponse(
+			CodeHeightPruned,
+			Codespace,
+			fmt.Errorf("state at height %d has been pruned, the earliest queryable height is %d", height, app.state.PrunedHeight+1),
+		)
+	}
+
+	if app.state.VersionsFrom == 0 || height < app.state.VersionsFrom {
+		return 0, NewErrorResponse(
+			CodeHeightUnavailable,
+			Codespace,
+			fmt.Errorf("state at height %d was not recorded, the earliest queryable height is %d", height, app.state.VersionsFrom),
+		)
+	}
+
+	version, err := app.versionAt(name, height)
+	if err != nil {
+		app.logger.Error("Got error resolving app version", zap.Error(err), zap.String("app", name), zap.Int64("height", height))
+		return 0, NewErrorResponse(CodeUnknownError, Codespace, err)
+	}
+
+	return version, nil
+}
+
+// recordVersions records the latest version of each versioned sub-application at the current height, so that the
+// versions required by retained heights can be determined when pruning. Only changes of version are recorded.
+func (app *MultiplexedApplication) recordVersions() error {
+	if app.state.VersionsFrom == 0 {
+		app.state.VersionsFrom = app.state.Height
+	}
+
+	batch := app.db.NewBatch()
+	defer batch.Close()
+
+	for name, subApp := range app.apps {
+		versioned, ok := subApp.(VersionedApp)
+		if !ok {
+			continue
+		}
+
+		version := versioned.Version()
+
+		recorded, err := app.versionAt(name, app.state.Height)
+		if err != nil {
+			return err
+		}
+
+		if version != recorded {
+			if err := batch.Set(versionKey(name, app.state.Height), binary.BigEndian.AppendUint64(nil, uint64(version))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return batch.Write()
+}
+
+// versionAt returns the version of the sub-application's state at the given height, or 0 if no version was recorded
+// at or before the height.
+func (app *MultiplexedApplication) versionAt(name string, height int64) (int64, error) {
+	start, end := utils.Bytes(versionPrefix+name+":"), versionKey(name, height+1)
+
+	iterator, err := app.db.ReverseIterator(start, end)
+	if err != nil {
+		return 0, err
+	}
+	defer iterator.Close()
+
+	if !iterator.Valid() {
+		return 0, iterator.Error()
+	}
+
+	return int64(binary.BigEndian.Uint64(iterator.Value())), nil
+}
+
+// rollbackUncommittedVersions deletes the versions saved by sub-applications after the latest committed height, which
+// are left behind if the node stops part-way through Commit. The block is then replayed by CometBFT.
+func (app *MultiplexedApplication) rollbackUncommittedVersions() error {
+	if app.state.VersionsFrom == 0 {
+		return nil
+	}
+
+	for _, name := range utils.SortedKeys(app.apps) {
+		versioned, ok := app.apps[name].(VersionedApp)
+		if !ok {
+			continue
+		}
+
+		committed, err := app.versionAt(name, app.state.Height)
+		if err != nil {
+			return err
+		}
+
+		if latest := versioned.Version(); latest > committed {
+			// Trees cannot be rolled back to before their first version
+			if committed == 0 {
+				return fmt.Errorf("%s app saved version %d for an uncommitted block, but no earlier version was committed", name, latest)
+			}
+
+			if err := versioned.LoadVersionForOverwriting(committed); err != nil {
+				return fmt.Errorf("error rolling back %s app from version %d to %d: %w", name, latest, committed, err)
+			}
+
+			app.logger.Warn(
+				"Rolled back app versions saved by an incomplete commit",
+				zap.String("app", name),
+				zap.Int64("latest_version", latest),
+				zap.Int64("committed_version", committed),
+			)
+		}
+	}
+
+	return nil
+}
+
+// prune deletes the versions of sub-application state that are not required by any retained height.
+func (app *MultiplexedApplication) prune() error {
+	earliest := app.state.Height - app.Pruning.KeepRecent + 1
+	if earliest <= 1 {
+		return nil
+	}
+
+	for name, subApp := range app.apps {
+		versioned, ok := subApp.(VersionedApp)
+		if !ok {
+			continue
+		}
+
+		// All versions from the one current at the earliest retained height onwards are retained
+		earliestVersion, err := app.versionAt(name, earliest)
+		if err != nil {
+			return err
+		}
+
+		// Versions saved before heights were recorded cannot be mapped to heights, so are only pruned once the
+		// earliest retained height has a recorded version
+		if earliestVersion == 0 {
+			continue
+		}
+
+		retained := make(map[int64]bool)
+		if app.Pruning.KeepEvery > 0 {
+			for height := app.Pruning.KeepEvery; height < earliest; height += app.Pruning.KeepEvery {
+				version, err := app.versionAt(name, height)
+				if err != nil {
+					return err
+				}
+
+				retained[version] = true
+			}
+		}
+
+		var toDelete []int64
+		for _, version := range versioned.AvailableVersions() {
+			if version >= earliestVersion {
+				break
+			}
+
+			if !retained[version] {
+				toDelete = append(toDelete, version)
+			}
+		}
+
+		if err := versioned.DeleteVersions(toDelete...); err != nil {
+			return fmt.Errorf("error pruning %s app: %w", name, err)
+		}
+
+		if len(toDelete) > 0 {
+			app.logger.Info(
+				"Pruned app versions",
+				zap.String("app", name),
+				zap.Int("count", len(toDelete)),
+				zap.Int64("earliest_retained_version", earliestVersion),
+			)
+		}
+	}
+
+	app.state.PrunedHeight = earliest - 1
+	return nil
+}
+
+// Heights are zero-padded so that versions are iterated in ascending height order
+func versionKey(name string, height int64) []byte {
+	return utils.Bytes(fmt.Sprintf("%s%s:%020d", versionPrefix, name, height))
+}
@@ -0,0 +1,68 @@
+package multiplexer
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	common "github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+)
+
+// SequenceTracker enforces per-principal sequence numbers on signed requests, so that a captured transaction cannot be
+// replayed. Each signed request must carry the next sequence number expected of its principal, which is incremented
+// when the request is committed.
+type SequenceTracker interface {
+	// ValidateCheckTx returns an error response if the sequence number cannot be admitted to the mempool: either it
+	// has already been committed, or it skips ahead of the requests already admitted for the principal.
+	ValidateCheckTx(principal identity.Principal, sequence uint64) *ErrorResponse
+	// Admit records that a request carrying the sequence number has been admitted to the mempool, so that the
+	// principal may submit further requests before it is committed.
+	Admit(principal identity.Principal, sequence uint64)
+	// ValidateFinalize returns an error response if the sequence number is not exactly the one expected of the
+	// principal, taking into account the requests earlier in the block being finalized.
+	ValidateFinalize(principal identity.Principal, sequence uint64) *ErrorResponse
+	// Reserve consumes the sequence number, applying the increment to the working state of the block being finalized.
+	Reserve(principal identity.Principal, sequence uint64) error
+}
+
+// sequencedRequest returns the principal and sequence number of a request that is subject to sequence checks, or false
+// if the request is unsigned or malformed. Version 1 requests omit a sequence number of 0, so are always checked. Legacy
+// requests are checked whenever they carry a sequence number, but their signature does not bind it: the number can be
+// rewritten or removed by anyone replaying the request, so legacy clients have no replay protection. From
+// common.LegacyRejectionAppVersion, every signed request is checked, a missing sequence number being taken as 0.
+func sequencedRequest(req common.MuxedRequest, appVersion uint64) (identity.Principal, uint64, bool) {
+	var peek struct {
+		Principal identity.Principal      `json:"principal"`
+		Signature string                  `json:"signature"`
+		Version   common.SignatureVersion `json:"version"`
+		Sequence  *uint64                 `json:"sequence"`
+	}
+
+	if err := json.Unmarshal(req.Data, &peek); err != nil || peek.Signature == "" {
+		return "", 0, false
+	}
+
+	if peek.Sequence == nil {
+		// Clients that predate sequence numbers send legacy requests without one, which are accepted until the end of
+		// the transition period
+		if peek.Version == common.SignatureVersionLegacy && appVersion < common.LegacyRejectionAppVersion {
+			return peek.Principal, 0, false
+		}
+
+		return peek.Principal, 0, true
+	}
+
+	return peek.Principal, *peek.Sequence, true
+}
+
+// requestSigner returns the principal that signed the request, or false if the request is unsigned or malformed.
+func requestSigner(req common.MuxedRequest) (identity.Principal, bool) {
+	var peek struct {
+		Principal identity.Principal `json:"principal"`
+		Signature string             `json:"signature"`
+	}
+
+	if err := json.Unmarshal(req.Data, &peek); err != nil || peek.Signature == "" || peek.Principal == "" {
+		return "", false
+	}
+
+	return peek.Principal, true
+}
@@ -0,0 +1,90 @@
+package multiplexer_test
+
+import (
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	upgradetypes "github.com/RyanW02/wineventchain/common/pkg/types/upgrade"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Each sequence number may be used once per principal, including within a single block
+func TestReplayedSequence(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	res := chain.Block(
+		harness.CreateEventTx(t, agent, 0, 1),
+		harness.CreateEventTx(t, agent, 0, 2),
+		harness.CreateEventTx(t, agent, 1, 3),
+	)
+	require.Equal(t, uint32(0), res.TxResults[0].Code)
+	require.Equal(t, identitytypes.CodeInvalidSequence, res.TxResults[1].Code)
+	require.Equal(t, uint32(0), res.TxResults[2].Code)
+}
+
+// Legacy requests that carry a sequence number are subject to the same checks as version 1 requests
+func TestLegacySequence(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, chain.App.RegisterMetrics(registry))
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	res := chain.Block(harness.CreateEventTx(t, agent, 0, 1), harness.LegacyEventTx(t, agent, 1, 2))
+	harness.RequireCodes(t, res, eventtypes.CodeOk, eventtypes.CodeOk)
+
+	replayed := harness.LegacyEventTx(t, agent, 1, 3)
+	require.Equal(t, identitytypes.CodeInvalidSequence, chain.CheckTx(replayed).Code)
+
+	res = chain.Block(replayed, harness.LegacyEventTx(t, agent, 2, 3))
+	harness.RequireCodes(t, res, identitytypes.CodeInvalidSequence, eventtypes.CodeOk)
+
+	// Requests from clients that predate sequence numbers are not checked, but are counted, as they could be replays
+	res = chain.Block(harness.LegacyEventTx(t, agent, 0, 4))
+	harness.RequireCodes(t, res, eventtypes.CodeOk)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	var unsequenced float64
+	for _, family := range families {
+		if family.GetName() == "wineventchain_unsequenced_tx_total" {
+			unsequenced = family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+
+	require.Equal(t, float64(1), unsequenced)
+}
+
+// Legacy signatures are accepted and flagged until the chain reaches the app version that rejects them, so that every
+// node ends the transition period at the same height
+func TestLegacySignatureRejection(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	chain.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+
+	res := chain.Block(harness.LegacyEventTx(t, agent, 0, 1))
+	harness.RequireCodes(t, res, eventtypes.CodeOk)
+	require.Contains(t, harness.EventTypes(res.TxResults[0]), "legacy_signature")
+
+	plan := upgradetypes.Plan{Name: "v3", Height: 4, Version: rpc.LegacyRejectionAppVersion}
+	chain.Upgrades = []multiplexer.Upgrade{{Height: plan.Height, Version: plan.Version}}
+	chain.Start()
+
+	chain.Block(harness.ScheduleUpgradeTx(t, admin, 1, plan))
+	res = chain.Block(harness.LegacyEventTx(t, agent, 0, 2), harness.CreateEventTx(t, agent, 0, 3))
+	harness.RequireCodes(t, res, rpc.CodeLegacySignatureRejected, eventtypes.CodeOk)
+
+	checked := chain.CheckTx(harness.LegacyEventTx(t, agent, 1, 4))
+	require.Equal(t, uint32(rpc.CodeLegacySignatureRejected), checked.Code, checked.Log)
+}
@@ -0,0 +1,405 @@
+package multiplexer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/cometbft/cometbft/abci/types"
+	"go.uber.org/zap"
+	"io"
+)
+
+// SnapshottableApp is implemented by sub-applications whose state is included in state sync snapshots.
+type SnapshottableApp interface {
+	MultiplexedApp
+	// ExportSnapshot exports the committed state of the app.
+	ExportSnapshot() (json.RawMessage, error)
+	// RestoreSnapshot replaces the state of the app, which must not have processed any blocks, with exported state.
+	RestoreSnapshot(data json.RawMessage) error
+}
+
+const (
+	// SnapshotFormat is the version of the snapshot encoding. Snapshots in other formats are rejected.
+	SnapshotFormat uint32 = 1
+
+	snapshotChunkSize   = 10 << 20 // 10 MiB, below CometBFT's 16 MiB message limit
+	snapshotPrefix      = "snapshot:"
+	snapshotChunkPrefix = "snapshot_chunk:"
+)
+
+// snapshotData is the content of a snapshot, which is JSON encoded, gzipped, and split into chunks.
+type snapshotData struct {
+	State State                      `json:"state"`
+	Apps  map[string]json.RawMessage `json:"apps"`
+}
+
+// snapshotMetadata is carried by the Metadata field of a snapshot, allowing each chunk to be verified as it is
+// received.
+type snapshotMetadata struct {
+	ChunkHashes [][]byte `json:"chunk_hashes"`
+}
+
+// restoration tracks the progress of a snapshot being restored.
+type restoration struct {
+	snapshot *types.Snapshot
+	metadata snapshotMetadata
+	appHash  []byte
+	chunks   [][]byte
+	received uint32
+}
+
+func (app *MultiplexedApplication) ListSnapshots(ctx context.Context, req *types.RequestListSnapshots) (*types.ResponseListSnapshots, error) {
+	snapshots, err := app.listSnapshots()
+	if err != nil {
+		app.logger.Error("Failed to list snapshots", zap.Error(err))
+		return nil, err
+	}
+
+	return &types.ResponseListSnapshots{Snapshots: snapshots}, nil
+}
+
+func (app *MultiplexedApplication) LoadSnapshotChunk(ctx context.Context, req *types.RequestLoadSnapshotChunk) (*types.ResponseLoadSnapshotChunk, error) {
+	if req.Format != SnapshotFormat {
+		return &types.ResponseLoadSnapshotChunk{}, nil
+	}
+
+	chunk, err := app.db.Get(snapshotChunkKey(req.Height, req.Chunk))
+	if err != nil {
+		app.logger.Error("Failed to load snapshot chunk", zap.Error(err), zap.Uint64("height", req.Height), zap.Uint32("chunk", req.Chunk))
+		return nil, err
+	}
+
+	return &types.ResponseLoadSnapshotChunk{Chunk: chunk}, nil
+}
+
+func (app *MultiplexedApplication) OfferSnapshot(ctx context.Context, req *types.RequestOfferSnapshot) (*types.ResponseOfferSnapshot, error) {
+	if req.Snapshot == nil {
+		return &types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_REJECT}, nil
+	}
+
+	if req.Snapshot.Format != SnapshotFormat {
+		return &types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_REJECT_FORMAT}, nil
+	}
+
+	var metadata snapshotMetadata
+	if err := json.Unmarshal(req.Snapshot.Metadata, &metadata); err != nil || len(metadata.ChunkHashes) != int(req.Snapshot.Chunks) {
+		app.logger.Warn("Rejecting snapshot with invalid metadata", zap.Uint64("height", req.Snapshot.Height))
+		return &types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_REJECT}, nil
+	}
+
+	app.logger.Info(
+		"Accepted snapshot offer",
+		zap.Uint64("height", req.Snapshot.Height),
+		zap.Uint32("chunks", req.Snapshot.Chunks),
+	)
+
+	app.restoring = &restoration{
+		snapshot: req.Snapshot,
+		metadata: metadata,
+		appHash:  req.AppHash,
+		chunks:   make([][]byte, req.Snapshot.Chunks),
+	}
+
+	return &types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_ACCEPT}, nil
+}
+
+func (app *MultiplexedApplication) ApplySnapshotChunk(ctx context.Context, req *types.RequestApplySnapshotChunk) (*types.ResponseApplySnapshotChunk, error) {
+	restoring := app.restoring
+	if restoring == nil || req.Index >= uint32(len(restoring.chunks)) {
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}, nil
+	}
+
+	if !bytes.Equal(utils.Sha256Sum(req.Chunk), restoring.metadata.ChunkHashes[req.Index]) {
+		app.logger.Warn(
+			"Received snapshot chunk with invalid hash",
+			zap.Uint32("chunk", req.Index),
+			zap.String("sender", req.Sender),
+		)
+
+		return &types.ResponseApplySnapshotChunk{
+			Result:        types.ResponseApplySnapshotChunk_RETRY,
+			RefetchChunks: []uint32{req.Index},
+			RejectSenders: []string{req.Sender},
+		}, nil
+	}
+
+	if restoring.chunks[req.Index] == nil {
+		restoring.chunks[req.Index] = req.Chunk
+		restoring.received++
+	}
+
+	if restoring.received < uint32(len(restoring.chunks)) {
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ACCEPT}, nil
+	}
+
+	// All chunks have been received
+	app.restoring = nil
+
+	data, err := decodeSnapshot(restoring)
+	if err != nil {
+		app.logger.Warn("Rejecting snapshot that could not be decoded", zap.Error(err))
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}, nil
+	}
+
+	// Verify the snapshot against the trusted app hash before modifying any state
+	if appHash := data.State.GenerateAppHash(); !bytes.Equal(appHash, restoring.appHash) {
+		app.logger.Warn(
+			"Rejecting snapshot with mismatched app hash",
+			zap.Binary("expected", restoring.appHash),
+			zap.Binary("actual", appHash),
+		)
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}, nil
+	}
+
+	if data.State.Height != int64(restoring.snapshot.Height) {
+		app.logger.Warn("Rejecting snapshot with mismatched height", zap.Int64("state_height", data.State.Height))
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}, nil
+	}
+
+	for name, subApp := range app.apps {
+		snapshottable, ok := subApp.(SnapshottableApp)
+		if !ok {
+			continue
+		}
+
+		exported, ok := data.Apps[name]
+		if !ok {
+			app.logger.Warn("Rejecting snapshot missing app state", zap.String("app", name))
+			return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}, nil
+		}
+
+		// Sub-applications may have been partially restored, so the node must be reset before retrying
+		if err := snapshottable.RestoreSnapshot(exported); err != nil {
+			app.logger.Error("Failed to restore app from snapshot", zap.String("app", name), zap.Error(err))
+			return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}, nil
+		}
+	}
+
+	// Each tree is only checked against the hash exported with it, so the restored state must be checked against the
+	// app hashes committed to by the trusted app hash
+	for name, subApp := range app.apps {
+		workingHash, err := subApp.WorkingHash()
+		if err != nil {
+			app.logger.Error("Failed to get working hash of restored app", zap.String("app", name), zap.Error(err))
+			return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}, nil
+		}
+
+		if !bytes.Equal(workingHash, data.State.AppHashes[name]) {
+			app.logger.Error(
+				"Restored app state does not match the snapshot's app hash",
+				zap.String("app", name),
+				zap.Binary("expected", data.State.AppHashes[name]),
+				zap.Binary("actual", workingHash),
+			)
+			return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}, nil
+		}
+	}
+
+	// The state at heights before the snapshot is not available on this node, and the versions of the snapshot's
+	// state are only recorded from its height
+	data.State.db = app.db
+	data.State.PrunedHeight = data.State.Height - 1
+	data.State.VersionsFrom = data.State.Height
+	app.state = data.State
+
+	if err := app.recordAppHashes(); err != nil {
+		app.logger.Error("Failed to record app hashes after restoring snapshot", zap.Error(err))
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}, nil
+	}
+
+	if err := app.recordVersions(); err != nil {
+		app.logger.Error("Failed to record app versions after restoring snapshot", zap.Error(err))
+		return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}, nil
+	}
+
+	saveState(app.state)
+	app.committed = newCommittedState(app.state)
+
+	app.logger.Info("Restored state from snapshot", zap.Int64("height", app.state.Height))
+
+	return &types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ACCEPT}, nil
+}
+
+// createSnapshot exports the committed state of the multiplexer and all snapshottable sub-applications, and stores it
+// for serving to nodes joining the network.
+func (app *MultiplexedApplication) createSnapshot() error {
+	data := snapshotData{
+		State: app.state,
+		Apps:  make(map[string]json.RawMessage),
+	}
+
+	for name, subApp := range app.apps {
+		if snapshottable, ok := subApp.(SnapshottableApp); ok {
+			exported, err := snapshottable.ExportSnapshot()
+			if err != nil {
+				return fmt.Errorf("error exporting %s app: %w", name, err)
+			}
+
+			data.Apps[name] = exported
+		}
+	}
+
+	marshalled, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(marshalled); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	content := compressed.Bytes()
+
+	height := uint64(app.state.Height)
+	batch := app.db.NewBatch()
+	defer batch.Close()
+
+	var metadata snapshotMetadata
+	for index := uint32(0); len(content) > 0; index++ {
+		chunk := content[:utils.Min(len(content), snapshotChunkSize)]
+		content = content[len(chunk):]
+
+		if err := batch.Set(snapshotChunkKey(height, index), chunk); err != nil {
+			return err
+		}
+
+		metadata.ChunkHashes = append(metadata.ChunkHashes, utils.Sha256Sum(chunk))
+	}
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	snapshot := types.Snapshot{
+		Height:   height,
+		Format:   SnapshotFormat,
+		Chunks:   uint32(len(metadata.ChunkHashes)),
+		Hash:     utils.Sha256Sum(compressed.Bytes()),
+		Metadata: metadataBytes,
+	}
+
+	snapshotBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := batch.Set(snapshotKey(height), snapshotBytes); err != nil {
+		return err
+	}
+
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+
+	app.logger.Info(
+		"Created state sync snapshot",
+		zap.Uint64("height", height),
+		zap.Uint32("chunks", snapshot.Chunks),
+		zap.Int("size", compressed.Len()),
+	)
+
+	return app.pruneSnapshots()
+}
+
+// pruneSnapshots deletes all but the most recent SnapshotKeepRecent snapshots.
+func (app *MultiplexedApplication) pruneSnapshots() error {
+	if app.SnapshotKeepRecent <= 0 {
+		return nil
+	}
+
+	snapshots, err := app.listSnapshots()
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) <= app.SnapshotKeepRecent {
+		return nil
+	}
+
+	batch := app.db.NewBatch()
+	defer batch.Close()
+
+	// Snapshots are listed in ascending height order
+	for _, snapshot := range snapshots[:len(snapshots)-app.SnapshotKeepRecent] {
+		for index := uint32(0); index < snapshot.Chunks; index++ {
+			if err := batch.Delete(snapshotChunkKey(snapshot.Height, index)); err != nil {
+				return err
+			}
+		}
+
+		if err := batch.Delete(snapshotKey(snapshot.Height)); err != nil {
+			return err
+		}
+	}
+
+	return batch.WriteSync()
+}
+
+func (app *MultiplexedApplication) listSnapshots() ([]*types.Snapshot, error) {
+	// ';' is the byte following ':', so the range covers exactly the keys with the snapshot prefix
+	start, end := utils.Bytes(snapshotPrefix), utils.Bytes(snapshotPrefix[:len(snapshotPrefix)-1]+";")
+
+	iterator, err := app.db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	snapshots := make([]*types.Snapshot, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var snapshot types.Snapshot
+		if err := json.Unmarshal(iterator.Value(), &snapshot); err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, iterator.Error()
+}
+
+// decodeSnapshot reassembles the chunks of a snapshot, verifying them against the snapshot hash.
+func decodeSnapshot(restoring *restoration) (snapshotData, error) {
+	content := bytes.Join(restoring.chunks, nil)
+	if !bytes.Equal(utils.Sha256Sum(content), restoring.snapshot.Hash) {
+		return snapshotData{}, errors.New("snapshot content does not match snapshot hash")
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return snapshotData{}, err
+	}
+	defer reader.Close()
+
+	marshalled, err := io.ReadAll(reader)
+	if err != nil {
+		return snapshotData{}, err
+	}
+
+	var data snapshotData
+	if err := json.Unmarshal(marshalled, &data); err != nil {
+		return snapshotData{}, err
+	}
+
+	return data, nil
+}
+
+// Heights are zero-padded so that snapshots are iterated in ascending height order
+func snapshotKey(height uint64) []byte {
+	return utils.Bytes(fmt.Sprintf("%s%020d", snapshotPrefix, height))
+}
+
+func snapshotChunkKey(height uint64, index uint32) []byte {
+	return utils.Bytes(fmt.Sprintf("%s%020d:%d", snapshotChunkPrefix, height, index))
+}
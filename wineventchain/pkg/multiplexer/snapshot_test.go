@@ -0,0 +1,139 @@
+package multiplexer_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+// snapshotContent is the decoded content of a snapshot, with the state of each app left encoded.
+type snapshotContent struct {
+	State json.RawMessage            `json:"state"`
+	Apps  map[string]json.RawMessage `json:"apps"`
+}
+
+// loadSnapshot returns the decoded content of the snapshot taken by the chain at the given height.
+func loadSnapshot(t *testing.T, chain *harness.Chain, height uint64) snapshotContent {
+	res, err := chain.App.ListSnapshots(context.Background(), &abci.RequestListSnapshots{})
+	require.NoError(t, err)
+
+	for _, snapshot := range res.Snapshots {
+		if snapshot.Height != height {
+			continue
+		}
+
+		var compressed []byte
+		for index := uint32(0); index < snapshot.Chunks; index++ {
+			chunk, err := chain.App.LoadSnapshotChunk(context.Background(), &abci.RequestLoadSnapshotChunk{
+				Height: snapshot.Height,
+				Format: snapshot.Format,
+				Chunk:  index,
+			})
+			require.NoError(t, err)
+
+			compressed = append(compressed, chunk.Chunk...)
+		}
+
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err)
+
+		decompressed, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		var content snapshotContent
+		require.NoError(t, json.Unmarshal(decompressed, &content))
+
+		return content
+	}
+
+	require.FailNow(t, "snapshot not found", "height %d", height)
+	return snapshotContent{}
+}
+
+// restoreSnapshot offers the content to the chain as a snapshot at the given height, in a single chunk, and returns
+// the result of applying the chunk.
+func restoreSnapshot(t *testing.T, chain *harness.Chain, height uint64, appHash []byte, content snapshotContent) abci.ResponseApplySnapshotChunk_Result {
+	marshalled, err := json.Marshal(content)
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(marshalled)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	chunkHash := sha256.Sum256(compressed.Bytes())
+	metadata, err := json.Marshal(map[string]any{"chunk_hashes": [][]byte{chunkHash[:]}})
+	require.NoError(t, err)
+
+	offer, err := chain.App.OfferSnapshot(context.Background(), &abci.RequestOfferSnapshot{
+		Snapshot: &abci.Snapshot{Height: height, Format: 1, Chunks: 1, Hash: chunkHash[:], Metadata: metadata},
+		AppHash:  appHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, abci.ResponseOfferSnapshot_ACCEPT, offer.Result)
+
+	res, err := chain.App.ApplySnapshotChunk(context.Background(), &abci.RequestApplySnapshotChunk{Index: 0, Chunk: compressed.Bytes()})
+	require.NoError(t, err)
+
+	return res.Result
+}
+
+// A node restored from a snapshot must serve the same state as the node that took it
+func TestSnapshotRestore(t *testing.T) {
+	source := harness.New(t)
+	source.App.SnapshotInterval = 2
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	source.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+	res := source.Block(harness.CreateEventTx(t, agent, 0, 1))
+	harness.RequireCodes(t, res, eventtypes.CodeOk)
+	eventId := harness.EventIds(t, res, 0)[0]
+
+	content := loadSnapshot(t, source, 2)
+
+	target := harness.NewUninitialised(t)
+	require.Equal(t, abci.ResponseApplySnapshotChunk_ACCEPT, restoreSnapshot(t, target, 2, res.AppHash, content))
+
+	target.Height = 2
+	require.Equal(t, res.AppHash, target.LastAppHash())
+
+	queried := target.Query(eventtypes.AppName, "/event-by-id/"+eventId.String())
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	// Heights before the snapshot are unavailable, even those that are never pruned
+	target.App.Pruning = multiplexer.PruningOptions{KeepRecent: 10, KeepEvery: 1, Interval: 1}
+	queried = target.QueryAt(eventtypes.AppName, "/event-by-id/"+eventId.String(), 1)
+	require.Equal(t, multiplexer.CodeHeightUnavailable, queried.Code, queried.Log)
+}
+
+// The state of each app restored from a snapshot must match the app hashes of the snapshot's state, which are
+// verified against the trusted app hash
+func TestSnapshotMismatchedAppState(t *testing.T) {
+	source := harness.New(t)
+	source.App.SnapshotInterval = 2
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	source.Block(harness.SeedTx(t, admin), harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent))
+	res := source.Block(harness.CreateEventTx(t, agent, 0, 1))
+	source.Block(harness.CreateEventTx(t, agent, 1, 2))
+	source.Block(harness.CreateEventTx(t, agent, 2, 3))
+
+	// Replace the events tree with a later, internally consistent version
+	content := loadSnapshot(t, source, 2)
+	later := loadSnapshot(t, source, 4)
+	content.Apps[eventtypes.AppName] = later.Apps[eventtypes.AppName]
+
+	target := harness.NewUninitialised(t)
+	require.Equal(t, abci.ResponseApplySnapshotChunk_ABORT, restoreSnapshot(t, target, 2, res.AppHash, content))
+}
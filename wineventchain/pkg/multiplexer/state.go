@@ -3,8 +3,8 @@ package multiplexer
 import (
 	"encoding/json"
 	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	dbm "github.com/cometbft/cometbft-db"
-	"sort"
 )
 
 type State struct {
@@ -12,23 +12,26 @@ type State struct {
 	Size      int64             `json:"size"`
 	Height    int64             `json:"height"`
 	AppHashes map[string][]byte `json:"app_hash"`
+	ChainId   string            `json:"chain_id,omitempty"`
+	// PrunedHeight is the latest height whose state may have been pruned. State at heights retained by
+	// PruningOptions.KeepEvery is not pruned.
+	PrunedHeight int64 `json:"pruned_height,omitempty"`
+	// VersionsFrom is the first height at which the versions of sub-application state were recorded. Earlier heights
+	// cannot be mapped to versions, and so cannot be queried.
+	VersionsFrom int64 `json:"versions_from,omitempty"`
+	// AppVersion is the version of the format of the state, which is incremented by upgrades.
+	AppVersion uint64 `json:"app_version,omitempty"`
 }
 
+// GenerateAppHash combines the sub-application roots into the app hash, in the format of the state's app version.
 func (s State) GenerateAppHash() []byte {
-	appNames := utils.Keys(s.AppHashes)
-	sort.Strings(appNames)
-
-	var combined []byte
-	for _, name := range appNames {
-		combined = append(combined, s.AppHashes[name]...)
-	}
-
-	return utils.Sha256Sum(combined)
+	return proof.MultiplexAppHash(s.AppVersion, s.AppHashes)
 }
 
 func loadState(db dbm.DB) State {
 	state := State{
-		db: db,
+		db:         db,
+		AppVersion: AppVersion,
 	}
 
 	stateBytes := utils.Must(db.Get(utils.Bytes(stateKey)))
@@ -41,6 +44,11 @@ func loadState(db dbm.DB) State {
 		panic(err)
 	}
 
+	// Chains started before upgrades were introduced have the initial app version
+	if state.AppVersion == 0 {
+		state.AppVersion = AppVersion
+	}
+
 	return state
 }
 
@@ -0,0 +1,127 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	upgradetypes "github.com/RyanW02/wineventchain/common/pkg/types/upgrade"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"go.uber.org/zap"
+)
+
+// UpgradeScheduleApp is implemented by the sub-application that records upgrade plans on-chain, so that every node
+// agrees on the height at which an upgrade takes effect, including nodes running a binary that predates the upgrade.
+type UpgradeScheduleApp interface {
+	MultiplexedApp
+	// ScheduledUpgrade returns the plan scheduled in the working state, or nil if no upgrade is scheduled.
+	ScheduledUpgrade() *upgradetypes.Plan
+	// CompleteUpgrade records in the working state that the scheduled upgrade has been applied, replacing the app
+	// version previousVersion.
+	CompleteUpgrade(previousVersion uint64) error
+}
+
+// Migration transforms the working state of a sub-application to the format of a new app version. Migrations must be
+// deterministic, as every node runs them while finalizing the same block.
+type Migration func(ctx context.Context) error
+
+// Upgrade is the handler for an upgrade plan, registered by binaries that support the plan.
+type Upgrade struct {
+	// Height and Version must match those of the scheduled plan.
+	Height  int64
+	Version uint64
+	// Migrations are keyed by sub-application name, and run in name order before the transactions of the block at
+	// Height.
+	Migrations map[string]Migration
+}
+
+var (
+	ErrUpgradeRequired    = errors.New("an upgraded binary is required to continue")
+	ErrInvalidUpgrade     = errors.New("invalid upgrade handler")
+	ErrUnsupportedVersion = errors.New("app version is not supported by this binary")
+)
+
+// RegisterUpgrade registers the handler for an upgrade plan. Handlers must be registered before the node is started.
+func (app *MultiplexedApplication) RegisterUpgrade(upgrade Upgrade) error {
+	if upgrade.Height <= 0 || upgrade.Version <= AppVersion {
+		return fmt.Errorf("%w: height must be positive, and version greater than %d", ErrInvalidUpgrade, AppVersion)
+	}
+
+	if _, ok := app.upgrades[upgrade.Version]; ok {
+		return fmt.Errorf("%w: version %d is already registered", ErrInvalidUpgrade, upgrade.Version)
+	}
+
+	for name := range upgrade.Migrations {
+		if _, ok := app.apps[name]; !ok {
+			return fmt.Errorf("%w: unknown app %s", ErrInvalidUpgrade, name)
+		}
+	}
+
+	app.upgrades[upgrade.Version] = upgrade
+	return nil
+}
+
+// supportedVersion returns the latest app version that this binary can process blocks for.
+func (app *MultiplexedApplication) supportedVersion() uint64 {
+	version := uint64(LatestAppVersion)
+	for registered := range app.upgrades {
+		version = utils.Max(version, registered)
+	}
+
+	return version
+}
+
+// applyUpgrade runs the migrations of the upgrade scheduled at the height of the block being finalized, if any, and
+// returns the consensus parameter update announcing the new app version. An error is returned, halting the node before
+// any state is written, if this binary does not have a handler for the scheduled upgrade.
+func (app *MultiplexedApplication) applyUpgrade(ctx context.Context, height int64) (*cmtproto.ConsensusParams, error) {
+	if version := app.state.AppVersion; version > app.supportedVersion() {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	if app.upgradeSchedule == nil {
+		return nil, nil
+	}
+
+	plan := app.upgradeSchedule.ScheduledUpgrade()
+	if plan == nil || plan.Height != height {
+		return nil, nil
+	}
+
+	upgrade, ok := app.upgrades[plan.Version]
+	if !ok || upgrade.Height != plan.Height {
+		app.logger.Error(
+			"Upgrade required, halting. Restart the node with a binary that supports the upgrade",
+			zap.String("name", plan.Name),
+			zap.Int64("height", plan.Height),
+			zap.Uint64("version", plan.Version),
+		)
+
+		return nil, fmt.Errorf("%w: %s at height %d", ErrUpgradeRequired, plan.Name, plan.Height)
+	}
+
+	for _, name := range utils.SortedKeys(upgrade.Migrations) {
+		if err := upgrade.Migrations[name](ctx); err != nil {
+			return nil, fmt.Errorf("error migrating %s app to version %d: %w", name, plan.Version, err)
+		}
+	}
+
+	previousVersion := app.state.AppVersion
+	if err := app.upgradeSchedule.CompleteUpgrade(previousVersion); err != nil {
+		return nil, err
+	}
+
+	app.state.AppVersion = plan.Version
+
+	app.logger.Info(
+		"Applied upgrade",
+		zap.String("name", plan.Name),
+		zap.Int64("height", plan.Height),
+		zap.Uint64("previous_version", previousVersion),
+		zap.Uint64("version", plan.Version),
+	)
+
+	return &cmtproto.ConsensusParams{
+		Version: &cmtproto.VersionParams{App: plan.Version},
+	}, nil
+}
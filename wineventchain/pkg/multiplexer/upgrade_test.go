@@ -0,0 +1,86 @@
+package multiplexer_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	eventtypes "github.com/RyanW02/wineventchain/common/pkg/types/events"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	upgradetypes "github.com/RyanW02/wineventchain/common/pkg/types/upgrade"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// A node without a handler for a scheduled upgrade must halt at the upgrade height without writing any state, and
+// resume once restarted with the handler, which runs its migrations before the transactions of the block
+func TestUpgrade(t *testing.T) {
+	chain := harness.New(t)
+	admin, agent := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "agent")
+
+	plan := upgradetypes.Plan{Name: "v2", Height: 4, Version: 2}
+
+	res := chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, agent, identitytypes.RoleAgent),
+		harness.ScheduleUpgradeTx(t, agent, 0, plan),
+		harness.ScheduleUpgradeTx(t, admin, 1, upgradetypes.Plan{Name: "v2", Height: 1, Version: 2}),
+		harness.ScheduleUpgradeTx(t, admin, 1, plan),
+	)
+	require.Equal(t, upgradetypes.CodeUnauthorized, res.TxResults[2].Code)
+	require.Equal(t, upgradetypes.CodeInvalidPlan, res.TxResults[3].Code)
+	require.Equal(t, uint32(0), res.TxResults[4].Code, res.TxResults[4].Log)
+
+	res = chain.Block(harness.CreateEventTx(t, agent, 0, 1))
+	harness.RequireCodes(t, res, 0)
+	res = chain.Block()
+	require.Nil(t, res.ConsensusParamUpdates)
+
+	_, err := chain.App.FinalizeBlock(context.Background(), &abci.RequestFinalizeBlock{Height: plan.Height})
+	require.ErrorIs(t, err, multiplexer.ErrUpgradeRequired)
+
+	var migratedAt int64
+	chain.Upgrades = []multiplexer.Upgrade{{
+		Height:  plan.Height,
+		Version: plan.Version,
+		Migrations: map[string]multiplexer.Migration{
+			eventtypes.AppName: func(ctx context.Context) error {
+				migratedAt = chain.Height
+				return nil
+			},
+		},
+	}}
+	chain.Start()
+
+	res = chain.Block(harness.CreateEventTx(t, agent, 1, 2))
+	harness.RequireCodes(t, res, 0)
+	require.Equal(t, plan.Height, migratedAt)
+	require.NotNil(t, res.ConsensusParamUpdates)
+	require.Equal(t, plan.Version, res.ConsensusParamUpdates.Version.App)
+
+	// From version 2, the app hash binds the name of each sub-application root
+	eventId := harness.EventIds(t, res, 0)[0]
+	queried := chain.Query(eventtypes.AppName, "/event-by-id/"+eventId.String())
+	_, err = proof.VerifyChain(queried.ProofOps, eventtypes.AppName, eventId, res.AppHash, multiplexer.AppVersion)
+	require.ErrorIs(t, err, proof.ErrAppHashMismatch)
+	_, err = proof.VerifyChain(queried.ProofOps, eventtypes.AppName, eventId, res.AppHash, plan.Version)
+	require.NoError(t, err)
+
+	info, err := chain.App.Info(context.Background(), &abci.RequestInfo{})
+	require.NoError(t, err)
+	require.Equal(t, plan.Version, info.AppVersion)
+
+	queried = chain.Query(upgradetypes.AppName, upgradetypes.QueryPathState)
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	var state upgradetypes.StateResponse
+	require.NoError(t, json.Unmarshal(queried.Value, &state))
+	require.Nil(t, state.Scheduled)
+	require.Equal(t, []upgradetypes.AppliedUpgrade{{Plan: plan, PreviousVersion: 1}}, state.Applied)
+
+	// The upgrade is only applied once
+	res = chain.Block()
+	require.Nil(t, res.ConsensusParamUpdates)
+}
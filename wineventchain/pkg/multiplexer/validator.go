@@ -1,10 +1,24 @@
 package multiplexer
 
 import (
+	"github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
 	"github.com/cometbft/cometbft/libs/sync"
 	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"sort"
 )
 
+// ValidatorSetApp is implemented by the sub-application that manages the validator set, allowing the multiplexer to
+// punish misbehaving validators.
+type ValidatorSetApp interface {
+	MultiplexedApp
+	// Punish sets the power of the validator with the given address in the working state, returning the validator
+	// update to apply. Returns false if the validator is unknown, or if the update cannot be applied.
+	Punish(address Address, power int64) (types.ValidatorUpdate, bool)
+	// Validators returns the validator set in the working state, ordered by address.
+	Validators() []types.ValidatorUpdate
+}
+
 type ValidatorMap struct {
 	mu    sync.RWMutex
 	inner map[Address]Validator
@@ -13,10 +27,17 @@ type ValidatorMap struct {
 type Validator struct {
 	Address Address
 	PubKey  crypto.PublicKey
+	Power   int64
 }
 
+// Address is the upper-case hex encoded address of a validator, as displayed by CometBFT.
 type Address string
 
+// NewAddress encodes the raw address of a validator, as carried by misbehaviour evidence.
+func NewAddress(address []byte) Address {
+	return Address(cmtbytes.HexBytes(address).String())
+}
+
 func (a Address) String() string {
 	return string(a)
 }
@@ -34,9 +55,38 @@ func (v *ValidatorMap) Add(val Validator) {
 	v.inner[val.Address] = val
 }
 
+func (v *ValidatorMap) Remove(address Address) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.inner, address)
+}
+
 func (v *ValidatorMap) Get(address Address) (Validator, bool) {
 	v.mu.RLock()
 	defer v.mu.RUnlock()
 	val, ok := v.inner[address]
 	return val, ok
 }
+
+func (v *ValidatorMap) Len() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return len(v.inner)
+}
+
+// All returns every validator in the map, ordered by address.
+func (v *ValidatorMap) All() []Validator {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	validators := make([]Validator, 0, len(v.inner))
+	for _, val := range v.inner {
+		validators = append(validators, val)
+	}
+
+	sort.Slice(validators, func(i, j int) bool {
+		return validators[i].Address < validators[j].Address
+	})
+
+	return validators
+}
@@ -2,49 +2,69 @@ package retentionpolicy
 
 import (
 	"context"
-	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
 	"github.com/RyanW02/wineventchain/app/internal/utils"
 	"github.com/RyanW02/wineventchain/app/pkg/identity"
 	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
 	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
 	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
 	types "github.com/RyanW02/wineventchain/common/pkg/types/retention"
 	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
 	dbm "github.com/cometbft/cometbft-db"
 	abci "github.com/cometbft/cometbft/abci/types"
-	"github.com/mitchellh/hashstructure/v2"
+	"github.com/cosmos/iavl"
 	"go.uber.org/zap"
+	"strconv"
+	"time"
 )
 
 type RetentionPolicyApp struct {
 	logger     *zap.Logger
 	identities identity.Repository
 	db         dbm.DB
-	policy     *offchain.StoredPolicy
-	txState    txState
+	repository Repository
+	history    offchain.PolicyHistory // Policy history, including versions agreed in the current block
+	proposals  []types.Proposal       // Indexed by proposal ID - 1, including changes made in the current block
+	holds      offchain.LegalHolds    // Indexed by hold ID - 1, including changes made in the current block
 }
 
-type txState struct {
-	policy *offchain.StoredPolicy
-}
-
-const dbKey = "policy"
+var _ multiplexer.SnapshottableApp = (*RetentionPolicyApp)(nil)
+var _ multiplexer.GenesisApp = (*RetentionPolicyApp)(nil)
+var _ multiplexer.VersionedApp = (*RetentionPolicyApp)(nil)
+var _ multiplexer.SizedApp = (*RetentionPolicyApp)(nil)
 
-var _ multiplexer.MultiplexedApp = (*RetentionPolicyApp)(nil)
+const treeCacheSize = 100
 
 func NewRetentionPolicyApp(logger *zap.Logger, identityRepository identity.Repository, db dbm.DB) (*RetentionPolicyApp, error) {
+	tree, err := iavl.NewMutableTree(db, treeCacheSize, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tree.Load(); err != nil {
+		return nil, err
+	}
+
 	app := &RetentionPolicyApp{
 		logger:     logger,
 		identities: identityRepository,
 		db:         db,
-		policy:     nil,
-		txState:    txState{},
+		repository: NewMerkleRepository(tree),
+		history:    make(offchain.PolicyHistory, 0),
+		proposals:  make([]types.Proposal, 0),
+		holds:      make(offchain.LegalHolds, 0),
 	}
 
-	if err := app.loadPolicy(); err != nil {
+	if err := app.migrateLegacyState(); err != nil {
+		return nil, err
+	}
+
+	if err := app.loadState(); err != nil {
 		return nil, err
 	}
 
@@ -56,43 +76,141 @@ func (app *RetentionPolicyApp) Name() string {
 }
 
 func (app *RetentionPolicyApp) Info(ctx context.Context, req *abci.RequestInfo) any {
-	appHash, err := app.appHash()
+	appHash, err := app.repository.Hash()
 	if err != nil {
 		app.logger.Warn("Got error getting hash of RetentionPolicyApp", zap.Error(err))
 		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
 	}
 
+	var latestVersion uint64
+	if latest := app.history.Latest(); latest != nil {
+		latestVersion = latest.Version
+	}
+
 	return map[string]any{
-		"app_hash": hex.EncodeToString(appHash),
-		"is_set":   app.policy != nil,
+		"version":        app.repository.Version(),
+		"app_hash":       hex.EncodeToString(appHash),
+		"is_set":         len(app.history) > 0,
+		"latest_version": latestVersion,
+		"proposal_count": len(app.proposals),
+		"hold_count":     len(app.holds),
 	}
 }
 
+// InitChain saves the empty state of a new chain, so that queries can be proven before a policy has been set.
 func (app *RetentionPolicyApp) InitChain(ctx context.Context, req *abci.RequestInitChain) []byte {
-	appHash, err := app.appHash()
+	if app.repository.Version() == 0 {
+		if err := app.apply(nil, nil); err != nil {
+			app.logger.Fatal("Got error writing RetentionPolicyApp state when running InitChain", zap.Error(err))
+		}
+
+		if _, _, err := app.repository.Save(); err != nil {
+			app.logger.Fatal("Got error saving RetentionPolicyApp state when running InitChain", zap.Error(err))
+		}
+	}
+
+	appHash, err := app.repository.Hash()
 	if err != nil {
-		app.logger.Fatal("Got error getting hash of IdentityApp when running InitChain", zap.Error(err))
+		app.logger.Fatal("Got error getting hash of RetentionPolicyApp when running InitChain", zap.Error(err))
 	}
 
 	return appHash
 }
 
-func (app *RetentionPolicyApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, data json.RawMessage) (*abci.ResponseCheckTx, error) {
-	payload, requester, err := app.decode(data)
+func (app *RetentionPolicyApp) WorkingHash() ([]byte, error) {
+	return app.repository.WorkingHash()
+}
+
+// Commit saves the changes made to the policy history, proposals and legal holds in the current block, if any.
+func (app *RetentionPolicyApp) Commit() error {
+	_, err := datastore.SaveChanges(app.repository)
+	return err
+}
+
+// LoadVersionForOverwriting rolls the tree back to the given version, and reloads the policy history, proposals and
+// legal holds.
+func (app *RetentionPolicyApp) LoadVersionForOverwriting(version int64) error {
+	if err := app.repository.LoadVersionForOverwriting(version); err != nil {
+		return err
+	}
+
+	return app.loadState()
+}
+
+func (app *RetentionPolicyApp) Version() int64 {
+	return app.repository.Version()
+}
+
+func (app *RetentionPolicyApp) Size() int64 {
+	return app.repository.Size()
+}
+
+func (app *RetentionPolicyApp) AvailableVersions() []int64 {
+	return app.repository.AvailableVersions()
+}
+
+func (app *RetentionPolicyApp) DeleteVersions(versions ...int64) error {
+	return app.repository.DeleteVersions(versions...)
+}
+
+// ExportSnapshot exports the latest committed version of the retention policy tree.
+func (app *RetentionPolicyApp) ExportSnapshot() (json.RawMessage, error) {
+	snapshot, err := app.repository.Export()
 	if err != nil {
-		return err.IntoCheckTxResponse(), nil
+		return nil, err
 	}
 
-	if requester.Role != identitytypes.RoleAdmin {
-		return multiplexer.NewErrorResponse(
-			types.CodeUnauthorized,
-			types.Codespace,
-			errors.New("only principals with the admin role can set the retention policy"),
-		).IntoCheckTxResponse(), nil
+	return json.Marshal(snapshot)
+}
+
+// RestoreSnapshot imports the retention policy tree from a snapshot, verifying its hash, and loads the restored policy
+// history, proposals and legal holds.
+func (app *RetentionPolicyApp) RestoreSnapshot(data json.RawMessage) error {
+	var snapshot datastore.TreeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if err := app.repository.Import(snapshot); err != nil {
+		return err
+	}
+
+	return app.loadState()
+}
+
+// ExportGenesis exports the content of the latest committed version of the retention policy tree.
+func (app *RetentionPolicyApp) ExportGenesis() (json.RawMessage, error) {
+	entries, err := app.repository.ExportGenesis()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entries)
+}
+
+// InitGenesis writes the policy history, proposals and legal holds carried over from another chain to the working
+// tree, and loads them. The state is saved by InitChain.
+func (app *RetentionPolicyApp) InitGenesis(data json.RawMessage) error {
+	var entries []datastore.GenesisEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	if err := app.repository.ImportGenesis(entries); err != nil {
+		return err
+	}
+
+	return app.loadState()
+}
+
+func (app *RetentionPolicyApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, data json.RawMessage) (*abci.ResponseCheckTx, error) {
+	payload, requester, err := app.decode(ctx, data, 0)
+	if err != nil {
+		return err.IntoCheckTxResponse(), nil
 	}
 
-	if app.policy != nil || app.txState.policy != nil {
-		return multiplexer.NewErrorResponse(types.CodePolicyAlreadySet, types.Codespace, errors.New("retention policy already set")).IntoCheckTxResponse(), nil
+	if errRes := checkPermission(payload.Type, requester); errRes != nil {
+		return errRes.IntoCheckTxResponse(), nil
 	}
 
 	switch payload.Type {
@@ -102,8 +220,45 @@ func (app *RetentionPolicyApp) CheckTx(ctx context.Context, req *abci.RequestChe
 			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
 		}
 
-		if err := request.Policy.Validate(); err != nil {
-			return multiplexer.NewErrorResponse(types.CodeInvalidPolicy, types.Codespace, err).IntoCheckTxResponse(), nil
+		if errRes := app.validateSetPolicy(request); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+	case types.RequestTypeProposePolicy:
+		var request types.ProposePolicyRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+		}
+
+		if errRes := app.validateProposal(request); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+	case types.RequestTypeApprovePolicy, types.RequestTypeRejectPolicy:
+		var request types.VoteRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+		}
+
+		if _, errRes := app.validateVote(payload.Principal, request); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+	case types.RequestTypePlaceHold:
+		var request types.PlaceHoldRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+		}
+
+		// The block time is not known until the transaction is included in a block, so check against the current time
+		if errRes := validatePlaceHold(request, time.Now()); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
+		}
+	case types.RequestTypeReleaseHold:
+		var request types.ReleaseHoldRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoCheckTxResponse(), nil
+		}
+
+		if _, errRes := app.validateReleaseHold(request); errRes != nil {
+			return errRes.IntoCheckTxResponse(), nil
 		}
 	default:
 		return multiplexer.NewErrorResponse(types.CodeUnknownRequestType, types.Codespace, nil).IntoCheckTxResponse(), nil
@@ -116,26 +271,13 @@ func (app *RetentionPolicyApp) CheckTx(ctx context.Context, req *abci.RequestChe
 }
 
 func (app *RetentionPolicyApp) FinalizeBlock(ctx context.Context, req *abci.RequestFinalizeBlock, data json.RawMessage) multiplexer.FinalizeBlockResponse {
-	payload, requester, errRes := app.decode(data)
+	payload, requester, errRes := app.decode(ctx, data, req.Height)
 	if errRes != nil {
 		return errRes.IntoFinalizeBlockResponse()
 	}
 
-	if requester.Role != identitytypes.RoleAdmin {
-		return multiplexer.NewErrorResponse(
-			types.CodeUnauthorized,
-			types.Codespace,
-			errors.New("only principals with the admin role can set the retention policy"),
-		).IntoFinalizeBlockResponse()
-	}
-
-	if app.policy != nil || app.txState.policy != nil {
-		return multiplexer.NewErrorResponse(types.CodePolicyAlreadySet, types.Codespace, errors.New("retention policy already set")).IntoFinalizeBlockResponse()
-	}
-
-	appHash, err := app.appHash()
-	if errRes != nil {
-		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	if errRes := checkPermission(payload.Type, requester); errRes != nil {
+		return errRes.IntoFinalizeBlockResponse()
 	}
 
 	switch payload.Type {
@@ -145,8 +287,8 @@ func (app *RetentionPolicyApp) FinalizeBlock(ctx context.Context, req *abci.Requ
 			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
 
-		if err := request.Policy.Validate(); err != nil {
-			return multiplexer.NewErrorResponse(types.CodeInvalidPolicy, types.Codespace, err).IntoFinalizeBlockResponse()
+		if errRes := app.validateSetPolicy(request); errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
 		}
 
 		res, err := json.Marshal(types.SetPolicyResponse{})
@@ -154,12 +296,18 @@ func (app *RetentionPolicyApp) FinalizeBlock(ctx context.Context, req *abci.Requ
 			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
 
-		policy := &offchain.StoredPolicy{
-			Policy:    request.Policy,
-			Author:    payload.Principal,
-			AppliedAt: req.Time,
+		// The initial policy takes effect immediately, as there is no existing policy to transition from
+		policy := offchain.StoredPolicy{
+			Policy:          request.Policy,
+			Author:          payload.Principal,
+			AppliedAt:       req.Time,
+			Version:         1,
+			EffectiveHeight: req.Height,
+		}
+		if err := app.apply(nil, &policy); err != nil {
+			app.logger.Warn("Got error applying retention policy changes", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 		}
-		app.txState.policy = policy
 
 		return multiplexer.FinalizeBlockResponse{
 			TxResult: abci.ExecTxResult{
@@ -171,78 +319,416 @@ func (app *RetentionPolicyApp) FinalizeBlock(ctx context.Context, req *abci.Requ
 				},
 				Codespace: types.Codespace,
 			},
-			AppHash: appHash,
-			CommitFunc: func() error {
-				app.policy = policy
-				app.txState = txState{}
+		}
+	case types.RequestTypeProposePolicy:
+		var request types.ProposePolicyRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
 
-				marshalled, err := json.Marshal(app.policy)
-				if err != nil {
-					return err
-				}
+		if errRes := app.validateProposal(request); errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
 
-				return app.db.Set(bz(dbKey), marshalled)
-			},
+		voters, err := app.eligibleVoters()
+		if err != nil {
+			app.logger.Warn("Got error listing principals eligible to vote on proposal", zap.Error(err))
+			return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		proposal := types.NewProposal(app.nextProposalId(), request.Policy, payload.Principal, voters, req.Height)
+		return app.recordProposal(req, proposal, "policy_proposed")
+	case types.RequestTypeApprovePolicy, types.RequestTypeRejectPolicy:
+		var request types.VoteRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		proposal, errRes := app.validateVote(payload.Principal, request)
+		if errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
+
+		approve := payload.Type == types.RequestTypeApprovePolicy
+		proposal = proposal.WithVote(payload.Principal, approve, req.Height)
+
+		app.logger.Info(
+			"Recorded vote on retention policy proposal",
+			zap.Uint64("proposal_id", proposal.Id),
+			zap.String("voter", payload.Principal.String()),
+			zap.Bool("approve", approve),
+			zap.String("status", string(proposal.Status)),
+		)
+
+		return app.recordProposal(req, proposal, "policy_vote")
+	case types.RequestTypePlaceHold:
+		var request types.PlaceHoldRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		if errRes := validatePlaceHold(request, req.Time); errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
+		}
+
+		hold := offchain.LegalHold{
+			Id:        app.nextHoldId(),
+			Criteria:  request.Criteria,
+			Reason:    request.Reason,
+			Author:    payload.Principal,
+			PlacedAt:  req.Height,
+			ExpiresAt: request.ExpiresAt,
+		}
+
+		app.logger.Info(
+			"Placed legal hold",
+			zap.Uint64("hold_id", hold.Id),
+			zap.String("author", payload.Principal.String()),
+			zap.String("reason", hold.Reason),
+		)
+
+		return app.recordHold(hold, "hold_placed")
+	case types.RequestTypeReleaseHold:
+		var request types.ReleaseHoldRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+		}
+
+		hold, errRes := app.validateReleaseHold(request)
+		if errRes != nil {
+			return errRes.IntoFinalizeBlockResponse()
 		}
+
+		hold.ReleasedAt = req.Height
+		hold.ReleasedBy = payload.Principal
+
+		app.logger.Info(
+			"Released legal hold",
+			zap.Uint64("hold_id", hold.Id),
+			zap.String("released_by", payload.Principal.String()),
+		)
+
+		return app.recordHold(hold, "hold_released")
 	default:
 		return multiplexer.NewErrorResponse(types.CodeUnknownRequestType, types.Codespace, nil).IntoFinalizeBlockResponse()
 	}
 }
 
-func (app *RetentionPolicyApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
-	if req.Prove {
-		return multiplexer.NewErrorResponse(
-			types.CodeUnsupportedRequest,
-			types.Codespace,
-			errors.New("proof operation not valid for RetentionPolicyApp, as there is no merkle tree to provide a proof for"),
-		).IntoQueryResponse(), nil
+// recordProposal stores a new or updated proposal, scheduling a new version of the policy if the proposal has been
+// approved, and builds the response for the transaction.
+func (app *RetentionPolicyApp) recordProposal(
+	req *abci.RequestFinalizeBlock,
+	proposal types.Proposal,
+	eventName string,
+) multiplexer.FinalizeBlockResponse {
+	var version *offchain.StoredPolicy
+	if proposal.Status == types.ProposalStatusApproved {
+		version = &offchain.StoredPolicy{
+			Policy:          proposal.Policy,
+			Author:          proposal.Proposer,
+			AppliedAt:       req.Time,
+			Version:         app.nextVersion(),
+			EffectiveHeight: proposal.ActivationHeight,
+			ProposalId:      proposal.Id,
+		}
+		app.logger.Info(
+			"Retention policy proposal approved",
+			zap.Uint64("proposal_id", proposal.Id),
+			zap.Uint64("version", version.Version),
+			zap.Int64("effective_height", version.EffectiveHeight),
+		)
 	}
 
-	if app.policy == nil {
-		return multiplexer.NewErrorResponse(types.CodePolicyNotSet, types.Codespace, nil).IntoQueryResponse(), nil
+	if err := app.apply(&proposal, version); err != nil {
+		app.logger.Warn("Got error applying retention policy changes", zap.Error(err), zap.Uint64("proposal_id", proposal.Id))
+		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
 	}
 
-	data, err := json.Marshal(app.policy)
+	res, err := json.Marshal(types.ProposalResponse{Proposal: proposal})
 	if err != nil {
-		return nil, err
+		return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	return multiplexer.FinalizeBlockResponse{
+		TxResult: abci.ExecTxResult{
+			Code: types.CodeOk,
+			Data: res,
+			Log:  fmt.Sprintf("proposal %d is %s", proposal.Id, proposal.Status),
+			Events: []abci.Event{
+				utils.Event(
+					eventName,
+					abci.EventAttribute{Key: "proposal_id", Value: strconv.FormatUint(proposal.Id, 10), Index: true},
+					abci.EventAttribute{Key: "status", Value: string(proposal.Status), Index: true},
+				),
+			},
+			Codespace: types.Codespace,
+		},
 	}
+}
 
-	return &abci.ResponseQuery{
+func (app *RetentionPolicyApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
+	reader, errRes := app.reader(ctx, req)
+	if errRes != nil {
+		return errRes.IntoQueryResponse(), nil
+	}
+
+	res := &abci.ResponseQuery{
 		Code:      types.CodeOk,
-		Log:       "policy found",
-		Value:     data,
+		Height:    req.Height,
 		Codespace: types.Codespace,
-	}, nil
+	}
+
+	var value any
+	switch req.Path {
+	case "", types.QueryPathPolicy:
+		history, err := reader.History()
+		if err != nil {
+			return app.queryError(err, req).IntoQueryResponse(), nil
+		}
+
+		height := req.Height
+		if height == 0 {
+			height = multiplexer.HeightFromContext(ctx)
+		}
+
+		// The absence of a policy is proven in the same way as its presence, by the history
+		res.Key = bz(types.TreeKeyHistory)
+		if policy := history.At(height); policy != nil {
+			res.Log = "policy found"
+			value = policy
+		} else {
+			res.Code = types.CodePolicyNotSet
+			res.Log = "policy not set"
+		}
+	case types.QueryPathHistory:
+		history, err := reader.History()
+		if err != nil {
+			return app.queryError(err, req).IntoQueryResponse(), nil
+		}
+
+		res.Key = bz(types.TreeKeyHistory)
+		value = history
+	case types.QueryPathProposals:
+		proposals, err := reader.Proposals()
+		if err != nil {
+			return app.queryError(err, req).IntoQueryResponse(), nil
+		}
+
+		res.Key = bz(types.TreeKeyProposals)
+		value = proposals
+	case types.QueryPathHolds:
+		holds, err := reader.Holds()
+		if err != nil {
+			return app.queryError(err, req).IntoQueryResponse(), nil
+		}
+
+		res.Key = bz(types.TreeKeyHolds)
+		value = holds
+	default:
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+
+	if value != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Value = data
+	}
+
+	if req.Prove {
+		proofOp, err := reader.Prove(res.Key)
+		if err != nil {
+			return app.queryError(err, req).IntoQueryResponse(), nil
+		}
+
+		res.ProofOps = proof.ProofOps(proofOp)
+	}
+
+	return res, nil
 }
 
-func (app *RetentionPolicyApp) appHash() ([]byte, error) {
-	appHash, err := hashstructure.Hash(app.policy, hashstructure.FormatV2, nil)
+// reader returns the Reader for the saved version resolved by the multiplexer for the height of the query, or the
+// working tree if no version was resolved.
+func (app *RetentionPolicyApp) reader(ctx context.Context, req *abci.RequestQuery) (Reader, *multiplexer.ErrorResponse) {
+	version, ok := multiplexer.VersionFromContext(ctx)
+	if !ok {
+		return app.repository, nil
+	}
+
+	reader, err := app.repository.ReaderAt(version)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, datastore.ErrVersionUnavailable) {
+			return nil, multiplexer.NewErrorResponse(multiplexer.CodeHeightUnavailable, multiplexer.Codespace, err)
+		}
+
+		return nil, app.queryError(err, req)
+	}
+
+	return reader, nil
+}
+
+func (app *RetentionPolicyApp) queryError(err error, req *abci.RequestQuery) *multiplexer.ErrorResponse {
+	if errors.Is(err, proof.ErrTreeUninitialized) {
+		return multiplexer.NewErrorResponse(types.CodeTreeUninitialized, types.Codespace, err)
+	}
+
+	app.logger.Error(
+		"Got error reading from RetentionPolicyApp",
+		zap.Error(err),
+		zap.String("path", req.Path),
+		zap.Int64("height", req.Height),
+	)
+	return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+}
+
+// validateSetPolicy checks that the initial policy is valid, and that no policy has been set yet. Once a policy has
+// been set, it may only be amended by proposal.
+func (app *RetentionPolicyApp) validateSetPolicy(request types.SetPolicyRequest) *multiplexer.ErrorResponse {
+	if len(app.history) > 0 {
+		return multiplexer.NewErrorResponse(
+			types.CodePolicyAlreadySet,
+			types.Codespace,
+			errors.New("retention policy already set, amendments must be made by proposal"),
+		)
+	}
+
+	if err := request.Policy.Validate(); err != nil {
+		return multiplexer.NewErrorResponse(types.CodeInvalidPolicy, types.Codespace, err)
+	}
+
+	return nil
+}
+
+func (app *RetentionPolicyApp) validateProposal(request types.ProposePolicyRequest) *multiplexer.ErrorResponse {
+	if len(app.history) == 0 {
+		return multiplexer.NewErrorResponse(
+			types.CodePolicyNotSet,
+			types.Codespace,
+			errors.New("retention policy has not been set yet, the initial policy must be set with set_policy"),
+		)
+	}
+
+	if err := request.Policy.Validate(); err != nil {
+		return multiplexer.NewErrorResponse(types.CodeInvalidPolicy, types.Codespace, err)
+	}
+
+	return nil
+}
+
+// validateVote checks that the proposal exists and is still pending, and that the voter was eligible to vote on it
+// and has not already done so. Returns the current state of the proposal.
+func (app *RetentionPolicyApp) validateVote(voter identitytypes.Principal, request types.VoteRequest) (types.Proposal, *multiplexer.ErrorResponse) {
+	proposal, ok := app.getProposal(request.ProposalId)
+	if !ok {
+		return types.Proposal{}, multiplexer.NewErrorResponse(
+			types.CodeProposalNotFound,
+			types.Codespace,
+			fmt.Errorf("proposal %d not found", request.ProposalId),
+		)
+	}
+
+	if !proposal.IsPending() {
+		return types.Proposal{}, multiplexer.NewErrorResponse(
+			types.CodeProposalClosed,
+			types.Codespace,
+			fmt.Errorf("proposal %d is %s", proposal.Id, proposal.Status),
+		)
+	}
+
+	if !proposal.IsEligible(voter) {
+		return types.Proposal{}, multiplexer.NewErrorResponse(
+			types.CodeNotEligibleVoter,
+			types.Codespace,
+			errors.New("principal was not permitted to manage the retention policy when the proposal was made"),
+		)
+	}
+
+	if proposal.HasVoted(voter) {
+		return types.Proposal{}, multiplexer.NewErrorResponse(
+			types.CodeAlreadyVoted,
+			types.Codespace,
+			errors.New("principal has already voted on this proposal"),
+		)
 	}
 
-	appHashBytes := make([]byte, 8)
-	binary.LittleEndian.PutUint64(appHashBytes, appHash)
+	return proposal, nil
+}
+
+// eligibleVoters returns the active principals that are currently permitted to manage the retention policy.
+func (app *RetentionPolicyApp) eligibleVoters() ([]identitytypes.Principal, error) {
+	voters := make([]identitytypes.Principal, 0)
+	err := app.identities.ForEach(func(principal identitytypes.Principal, data identitytypes.IdentityData) bool {
+		if data.IsActive() && data.Can(identitytypes.PermissionManagePolicy) {
+			voters = append(voters, principal)
+		}
 
-	return appHashBytes, nil
+		return true
+	})
+
+	return voters, err
 }
 
-func (app *RetentionPolicyApp) loadPolicy() error {
-	data, err := app.db.Get(bz(dbKey))
+// getProposal returns the proposal with the given ID, including any changes made earlier in the current block.
+func (app *RetentionPolicyApp) getProposal(id uint64) (types.Proposal, bool) {
+	if id == 0 || id > uint64(len(app.proposals)) {
+		return types.Proposal{}, false
+	}
+
+	return app.proposals[id-1], true
+}
+
+func (app *RetentionPolicyApp) nextProposalId() uint64 {
+	return uint64(len(app.proposals)) + 1
+}
+
+func (app *RetentionPolicyApp) nextVersion() uint64 {
+	return uint64(len(app.history)) + 1
+}
+
+// apply applies a new or updated proposal, and a new policy version, to the state and writes it to the working tree.
+// The changes are saved when the block is committed.
+func (app *RetentionPolicyApp) apply(proposal *types.Proposal, version *offchain.StoredPolicy) error {
+	if proposal != nil {
+		if proposal.Id == uint64(len(app.proposals))+1 {
+			app.proposals = append(app.proposals, *proposal)
+		} else {
+			app.proposals[proposal.Id-1] = *proposal
+		}
+	}
+
+	if version != nil {
+		app.history = append(app.history, *version)
+	}
+
+	if err := app.repository.SetProposals(app.proposals); err != nil {
+		return err
+	}
+
+	return app.repository.SetHistory(app.history)
+}
+
+// loadState loads the policy history, proposals and legal holds from the tree.
+func (app *RetentionPolicyApp) loadState() error {
+	history, err := app.repository.History()
 	if err != nil {
 		return err
 	}
 
-	// If data == nil, no policy set
-	if data != nil {
-		var policy offchain.StoredPolicy
-		if err := json.Unmarshal(data, &policy); err != nil {
-			return err
-		}
+	proposals, err := app.repository.Proposals()
+	if err != nil {
+		return err
+	}
 
-		app.policy = &policy
+	holds, err := app.repository.Holds()
+	if err != nil {
+		return err
 	}
 
+	app.history = history
+	app.proposals = proposals
+	app.holds = holds
 	return nil
 }
 
@@ -250,7 +736,9 @@ func bz(s string) []byte {
 	return []byte(s)
 }
 
-func (app *RetentionPolicyApp) decode(data json.RawMessage) (rpc.SignedPayload, identitytypes.IdentityData, *multiplexer.ErrorResponse) {
+// decode decodes the signed payload, and validates its signature against the key that was assigned to the requester at
+// the given block height. A height of 0 validates against the requester's current key.
+func (app *RetentionPolicyApp) decode(ctx context.Context, data json.RawMessage, height int64) (rpc.SignedPayload, identitytypes.IdentityData, *multiplexer.ErrorResponse) {
 	var payload rpc.SignedPayload
 	if err := json.Unmarshal(data, &payload); err != nil {
 		app.logger.Warn("Got error decoding IdentityApp request rpc", zap.Error(err))
@@ -267,7 +755,7 @@ func (app *RetentionPolicyApp) decode(data json.RawMessage) (rpc.SignedPayload,
 		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
 	}
 
-	valid, err := payload.ValidateSignature(requester.PublicKey)
+	valid, err := payload.Verify(requester.KeyAt(height), multiplexer.ChainIDFromContext(ctx), types.AppName)
 	if err != nil {
 		app.logger.Warn(
 			"Got error validating IdentityApp request signature",
@@ -285,5 +773,14 @@ func (app *RetentionPolicyApp) decode(data json.RawMessage) (rpc.SignedPayload,
 		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(rpc.CodeInvalidSignature, rpc.Codespace, nil)
 	}
 
+	if err := identity.CheckStatus(payload.Principal, requester); err != nil {
+		app.logger.Warn(
+			"Got RetentionPolicyApp request from inactive principal",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("status", requester.Status.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, err
+	}
+
 	return payload, requester, nil
 }
@@ -0,0 +1,133 @@
+package retentionpolicy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// checkPermission checks that the requester may make requests of the given type: legal holds are managed separately
+// from the policy itself.
+func checkPermission(requestType rpc.RequestType, requester identitytypes.IdentityData) *multiplexer.ErrorResponse {
+	switch requestType {
+	case types.RequestTypePlaceHold, types.RequestTypeReleaseHold:
+		if !requester.Can(identitytypes.PermissionManageLegalHolds) {
+			return multiplexer.NewErrorResponse(
+				types.CodeUnauthorized,
+				types.Codespace,
+				errors.New("principal is not permitted to manage legal holds"),
+			)
+		}
+	default:
+		if !requester.Can(identitytypes.PermissionManagePolicy) {
+			return multiplexer.NewErrorResponse(
+				types.CodeUnauthorized,
+				types.Codespace,
+				errors.New("principal is not permitted to manage the retention policy"),
+			)
+		}
+	}
+
+	return nil
+}
+
+// validatePlaceHold checks that the hold's criteria and reason are valid, and that it does not expire before the given
+// time.
+func validatePlaceHold(request types.PlaceHoldRequest, now time.Time) *multiplexer.ErrorResponse {
+	if err := request.Criteria.Validate(); err != nil {
+		return multiplexer.NewErrorResponse(types.CodeInvalidHold, types.Codespace, err)
+	}
+
+	if len(request.Reason) == 0 || len(request.Reason) > types.MaxHoldReasonLength {
+		return multiplexer.NewErrorResponse(
+			types.CodeInvalidHold,
+			types.Codespace,
+			fmt.Errorf("reason must be between 1 and %d bytes long", types.MaxHoldReasonLength),
+		)
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return multiplexer.NewErrorResponse(types.CodeInvalidHold, types.Codespace, errors.New("hold has already expired"))
+	}
+
+	return nil
+}
+
+// validateReleaseHold checks that the hold exists and has not already been released. Returns the current state of the
+// hold. Expired holds may still be released, so that the reason they stopped protecting events is recorded.
+func (app *RetentionPolicyApp) validateReleaseHold(request types.ReleaseHoldRequest) (offchain.LegalHold, *multiplexer.ErrorResponse) {
+	if request.HoldId == 0 || request.HoldId > uint64(len(app.holds)) {
+		return offchain.LegalHold{}, multiplexer.NewErrorResponse(
+			types.CodeHoldNotFound,
+			types.Codespace,
+			fmt.Errorf("hold %d not found", request.HoldId),
+		)
+	}
+
+	hold := app.holds[request.HoldId-1]
+	if hold.ReleasedAt != 0 {
+		return offchain.LegalHold{}, multiplexer.NewErrorResponse(
+			types.CodeHoldReleased,
+			types.Codespace,
+			fmt.Errorf("hold %d was released at height %d", hold.Id, hold.ReleasedAt),
+		)
+	}
+
+	return hold, nil
+}
+
+func (app *RetentionPolicyApp) nextHoldId() uint64 {
+	return uint64(len(app.holds)) + 1
+}
+
+// recordHold stores a new or updated legal hold, and builds the response for the transaction.
+func (app *RetentionPolicyApp) recordHold(hold offchain.LegalHold, eventName string) multiplexer.FinalizeBlockResponse {
+	if hold.Id == app.nextHoldId() {
+		app.holds = append(app.holds, hold)
+	} else {
+		app.holds[hold.Id-1] = hold
+	}
+
+	if err := app.repository.SetHolds(app.holds); err != nil {
+		app.logger.Warn("Got error applying legal hold changes", zap.Error(err), zap.Uint64("hold_id", hold.Id))
+		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	res, err := json.Marshal(types.HoldResponse{Hold: hold})
+	if err != nil {
+		return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	return multiplexer.FinalizeBlockResponse{
+		TxResult: abci.ExecTxResult{
+			Code: types.CodeOk,
+			Data: res,
+			Log:  fmt.Sprintf("hold %d %s", hold.Id, holdStatus(hold)),
+			Events: []abci.Event{
+				utils.Event(
+					eventName,
+					abci.EventAttribute{Key: "hold_id", Value: strconv.FormatUint(hold.Id, 10), Index: true},
+				),
+			},
+			Codespace: types.Codespace,
+		},
+	}
+}
+
+func holdStatus(hold offchain.LegalHold) string {
+	if hold.ReleasedAt != 0 {
+		return "released"
+	}
+
+	return "placed"
+}
@@ -0,0 +1,58 @@
+package retentionpolicy_test
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/harness"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	retentiontypes "github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Legal holds may only be placed and released by an admin, and must be provable against the app hash
+func TestLegalHolds(t *testing.T) {
+	chain := harness.New(t)
+	admin, policyAdmin := harness.NewPrincipal(t, "admin"), harness.NewPrincipal(t, "policy_admin")
+
+	place := retentiontypes.PlaceHoldRequest{
+		Criteria: offchain.HoldCriteria{Channels: []string{"Security"}},
+		Reason:   "incident 42",
+	}
+	release := retentiontypes.ReleaseHoldRequest{HoldId: 1}
+
+	res := chain.Block(
+		harness.SeedTx(t, admin),
+		harness.RegisterTx(t, admin, 0, policyAdmin, identitytypes.RolePolicyAdmin),
+		harness.SignedTx(t, policyAdmin, 0, retentiontypes.AppName, retentiontypes.RequestTypePlaceHold, place),
+		harness.SignedTx(t, admin, 1, retentiontypes.AppName, retentiontypes.RequestTypePlaceHold, retentiontypes.PlaceHoldRequest{Reason: "no criteria"}),
+		harness.SignedTx(t, admin, 1, retentiontypes.AppName, retentiontypes.RequestTypePlaceHold, place),
+	)
+	require.Equal(t, retentiontypes.CodeUnauthorized, res.TxResults[2].Code)
+	require.Equal(t, retentiontypes.CodeInvalidHold, res.TxResults[3].Code)
+	require.Equal(t, uint32(0), res.TxResults[4].Code, res.TxResults[4].Log)
+
+	res = chain.Block(
+		harness.SignedTx(t, admin, 2, retentiontypes.AppName, retentiontypes.RequestTypeReleaseHold, release),
+		harness.SignedTx(t, admin, 3, retentiontypes.AppName, retentiontypes.RequestTypeReleaseHold, release),
+		harness.SignedTx(t, admin, 3, retentiontypes.AppName, retentiontypes.RequestTypeReleaseHold, retentiontypes.ReleaseHoldRequest{HoldId: 2}),
+	)
+	require.Equal(t, uint32(0), res.TxResults[0].Code, res.TxResults[0].Log)
+	require.Equal(t, retentiontypes.CodeHoldReleased, res.TxResults[1].Code)
+	require.Equal(t, retentiontypes.CodeHoldNotFound, res.TxResults[2].Code)
+
+	queried := chain.Query(retentiontypes.AppName, retentiontypes.QueryPathHolds)
+	require.Equal(t, uint32(0), queried.Code, queried.Log)
+
+	value, err := proof.VerifyChain(queried.ProofOps, retentiontypes.AppName, []byte(retentiontypes.TreeKeyHolds), res.AppHash, multiplexer.AppVersion)
+	require.NoError(t, err)
+
+	var holds offchain.LegalHolds
+	require.NoError(t, json.Unmarshal(value, &holds))
+	require.Len(t, holds, 1)
+	require.Equal(t, admin.Name, holds[0].Author)
+	require.Equal(t, admin.Name, holds[0].ReleasedBy)
+	require.Equal(t, chain.Height, holds[0].ReleasedAt)
+}
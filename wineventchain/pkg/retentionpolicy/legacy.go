@@ -0,0 +1,94 @@
+package retentionpolicy
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"go.uber.org/zap"
+)
+
+// Before the retention policy app stored its state in a tree, the policy history and proposals were stored as JSON
+// values directly in the database. Before amendments were supported, the single, immutable policy was stored alone.
+const (
+	legacyPolicyKey    = "policy"
+	legacyHistoryKey   = "history"
+	legacyProposalsKey = "proposals"
+)
+
+// migrateLegacyState moves state stored directly in the database into the tree, if no version of the tree has been
+// saved yet. The legacy values are left in place, but are not read again once the tree has been saved.
+func (app *RetentionPolicyApp) migrateLegacyState() error {
+	if app.repository.Version() > 0 {
+		return nil
+	}
+
+	history, proposals, found, err := app.loadLegacyState()
+	if err != nil || !found {
+		return err
+	}
+
+	if err := app.repository.SetHistory(history); err != nil {
+		return err
+	}
+
+	if err := app.repository.SetProposals(proposals); err != nil {
+		return err
+	}
+
+	if _, _, err := app.repository.Save(); err != nil {
+		return err
+	}
+
+	app.logger.Info(
+		"Migrated RetentionPolicyApp state into tree",
+		zap.Int("versions", len(history)),
+		zap.Int("proposals", len(proposals)),
+	)
+
+	return nil
+}
+
+func (app *RetentionPolicyApp) loadLegacyState() (offchain.PolicyHistory, []types.Proposal, bool, error) {
+	history := make(offchain.PolicyHistory, 0)
+	proposals := make([]types.Proposal, 0)
+
+	historyBytes, err := app.db.Get(bz(legacyHistoryKey))
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if historyBytes != nil {
+		if err := json.Unmarshal(historyBytes, &history); err != nil {
+			return nil, nil, false, err
+		}
+	} else {
+		policyBytes, err := app.db.Get(bz(legacyPolicyKey))
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		if policyBytes != nil {
+			var policy offchain.StoredPolicy
+			if err := json.Unmarshal(policyBytes, &policy); err != nil {
+				return nil, nil, false, err
+			}
+
+			policy.Version = 1
+			history = append(history, policy)
+		}
+	}
+
+	proposalBytes, err := app.db.Get(bz(legacyProposalsKey))
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if proposalBytes != nil {
+		if err := json.Unmarshal(proposalBytes, &proposals); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
+	found := historyBytes != nil || len(history) > 0 || proposalBytes != nil
+	return history, proposals, found, nil
+}
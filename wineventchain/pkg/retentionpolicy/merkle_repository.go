@@ -0,0 +1,256 @@
+package retentionpolicy
+
+import (
+	"encoding/json"
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
+	"github.com/RyanW02/wineventchain/common/pkg/proof"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+	"github.com/cosmos/iavl"
+	"sync"
+)
+
+type MerkleRepository struct {
+	tree *iavl.MutableTree
+	mu   sync.Mutex
+}
+
+var _ Repository = (*MerkleRepository)(nil)
+
+func NewMerkleRepository(tree *iavl.MutableTree) *MerkleRepository {
+	return &MerkleRepository{
+		tree: tree,
+		mu:   sync.Mutex{},
+	}
+}
+
+func (r *MerkleRepository) LoadLatest() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Load()
+}
+
+func (r *MerkleRepository) LoadVersion(version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.tree.LoadVersion(version)
+	return err
+}
+
+func (r *MerkleRepository) LoadVersionForOverwriting(version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.tree.LoadVersionForOverwriting(version)
+	return err
+}
+
+func (r *MerkleRepository) Rollback() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tree.Rollback()
+}
+
+func (r *MerkleRepository) Hash() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Hash()
+}
+
+func (r *MerkleRepository) WorkingHash() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.WorkingHash()
+}
+
+func (r *MerkleRepository) Save() ([]byte, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.SaveVersion()
+}
+
+func (r *MerkleRepository) Version() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Version()
+}
+
+func (r *MerkleRepository) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tree.Size()
+}
+
+func (r *MerkleRepository) AvailableVersions() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.AvailableVersions(r.tree)
+}
+
+func (r *MerkleRepository) DeleteVersions(versions ...int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.DeleteVersions(r.tree, versions)
+}
+
+func (r *MerkleRepository) Export() (datastore.TreeSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ExportTree(r.tree)
+}
+
+func (r *MerkleRepository) Import(snapshot datastore.TreeSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ImportTree(r.tree, snapshot)
+}
+
+func (r *MerkleRepository) ExportGenesis() ([]datastore.GenesisEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ExportGenesis(r.tree)
+}
+
+func (r *MerkleRepository) ImportGenesis(entries []datastore.GenesisEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return datastore.ImportGenesis(r.tree, entries)
+}
+
+func (r *MerkleRepository) History() (offchain.PolicyHistory, error) {
+	return r.reader().History()
+}
+
+func (r *MerkleRepository) Proposals() ([]types.Proposal, error) {
+	return r.reader().Proposals()
+}
+
+func (r *MerkleRepository) Holds() (offchain.LegalHolds, error) {
+	return r.reader().Holds()
+}
+
+func (r *MerkleRepository) Prove(key []byte) (crypto.ProofOp, error) {
+	return r.reader().Prove(key)
+}
+
+func (r *MerkleRepository) ReaderAt(version int64) (Reader, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tree, err := datastore.TreeAt(r.tree, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return merkleReader{
+		tree: tree,
+		mu:   &r.mu,
+	}, nil
+}
+
+// reader returns a Reader for the working tree.
+func (r *MerkleRepository) reader() merkleReader {
+	return merkleReader{
+		tree: r.tree,
+		mu:   &r.mu,
+	}
+}
+
+func (r *MerkleRepository) SetHistory(history offchain.PolicyHistory) error {
+	return r.set(types.TreeKeyHistory, history)
+}
+
+func (r *MerkleRepository) SetProposals(proposals []types.Proposal) error {
+	return r.set(types.TreeKeyProposals, proposals)
+}
+
+func (r *MerkleRepository) SetHolds(holds offchain.LegalHolds) error {
+	return r.set(types.TreeKeyHolds, holds)
+}
+
+func (r *MerkleRepository) set(key string, value any) error {
+	marshalled, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.tree.Set([]byte(key), marshalled)
+	return err
+}
+
+// merkleReader reads from a single version of the retention policy tree, sharing the repository's lock.
+type merkleReader struct {
+	tree datastore.ReadableTree
+	mu   *sync.Mutex
+}
+
+var _ Reader = merkleReader{}
+
+func (r merkleReader) History() (offchain.PolicyHistory, error) {
+	history := make(offchain.PolicyHistory, 0)
+	if err := r.get(types.TreeKeyHistory, &history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (r merkleReader) Proposals() ([]types.Proposal, error) {
+	proposals := make([]types.Proposal, 0)
+	if err := r.get(types.TreeKeyProposals, &proposals); err != nil {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+func (r merkleReader) Holds() (offchain.LegalHolds, error) {
+	holds := make(offchain.LegalHolds, 0)
+	if err := r.get(types.TreeKeyHolds, &holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (r merkleReader) Prove(key []byte) (crypto.ProofOp, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return proof.ProofOpForTree(r.tree, key)
+}
+
+// get unmarshals the value stored under the given key into v, leaving v unchanged if the key does not exist.
+func (r merkleReader) get(key string, v any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bytes, err := r.tree.Get([]byte(key))
+	if err != nil {
+		return err
+	}
+
+	if bytes == nil {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, v)
+}
@@ -0,0 +1,29 @@
+package retentionpolicy
+
+import (
+	"github.com/RyanW02/wineventchain/app/internal/datastore"
+	"github.com/RyanW02/wineventchain/common/pkg/types/offchain"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/retention"
+	"github.com/cometbft/cometbft/proto/tendermint/crypto"
+)
+
+// Reader reads the policy history and proposals from a single version of the retention policy tree.
+type Reader interface {
+	History() (offchain.PolicyHistory, error)
+	// Proposals returns every proposal, indexed by proposal ID - 1.
+	Proposals() ([]types.Proposal, error)
+	// Holds returns every legal hold, indexed by hold ID - 1.
+	Holds() (offchain.LegalHolds, error)
+	// Prove generates a proof of the value stored under the given key, or of its absence.
+	Prove(key []byte) (crypto.ProofOp, error)
+}
+
+type Repository interface {
+	datastore.BaseRepository
+	Reader
+	// ReaderAt returns a Reader for the given saved version of the repository.
+	ReaderAt(version int64) (Reader, error)
+	SetHistory(history offchain.PolicyHistory) error
+	SetProposals(proposals []types.Proposal) error
+	SetHolds(holds offchain.LegalHolds) error
+}
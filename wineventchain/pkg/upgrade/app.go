@@ -0,0 +1,363 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/app/pkg/identity"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/upgrade"
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// UpgradeApp records the upgrade plans scheduled by administrators, and the upgrades that have been applied. The
+// multiplexer consults the scheduled plan at each height, and applies the upgrade once its height is reached.
+type UpgradeApp struct {
+	logger     *zap.Logger
+	identities identity.Repository
+	db         dbm.DB
+	state      types.StateResponse // Including changes made in the current block
+	updated    bool                // Whether the state was changed in the current block
+}
+
+const stateKey = "upgrade_state"
+
+var _ multiplexer.UpgradeScheduleApp = (*UpgradeApp)(nil)
+var _ multiplexer.SnapshottableApp = (*UpgradeApp)(nil)
+
+func NewUpgradeApp(logger *zap.Logger, identityRepository identity.Repository, db dbm.DB) (*UpgradeApp, error) {
+	app := &UpgradeApp{
+		logger:     logger,
+		identities: identityRepository,
+		db:         db,
+		state: types.StateResponse{
+			Applied: make([]types.AppliedUpgrade, 0),
+		},
+	}
+
+	if err := app.loadState(); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (app *UpgradeApp) Name() string {
+	return types.AppName
+}
+
+func (app *UpgradeApp) Info(ctx context.Context, req *abci.RequestInfo) any {
+	appHash, err := app.WorkingHash()
+	if err != nil {
+		app.logger.Warn("Got error getting hash of UpgradeApp", zap.Error(err))
+		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	return map[string]any{
+		"app_hash":      hex.EncodeToString(appHash),
+		"scheduled":     app.state.Scheduled,
+		"applied_count": len(app.state.Applied),
+	}
+}
+
+// InitChain persists the empty state of a new chain.
+func (app *UpgradeApp) InitChain(ctx context.Context, req *abci.RequestInitChain) []byte {
+	if err := app.persist(); err != nil {
+		app.logger.Fatal("Got error persisting UpgradeApp state when running InitChain", zap.Error(err))
+	}
+
+	appHash, err := app.WorkingHash()
+	if err != nil {
+		app.logger.Fatal("Got error getting hash of UpgradeApp when running InitChain", zap.Error(err))
+	}
+
+	return appHash
+}
+
+// ExportSnapshot exports the scheduled and applied upgrades.
+func (app *UpgradeApp) ExportSnapshot() (json.RawMessage, error) {
+	return json.Marshal(app.state)
+}
+
+// RestoreSnapshot replaces the scheduled and applied upgrades with those from a snapshot, and persists them.
+func (app *UpgradeApp) RestoreSnapshot(data json.RawMessage) error {
+	var state types.StateResponse
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	app.state = state
+	return app.persist()
+}
+
+func (app *UpgradeApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, data json.RawMessage) (*abci.ResponseCheckTx, error) {
+	payload, requester, errRes := app.decode(ctx, data, 0)
+	if errRes != nil {
+		return errRes.IntoCheckTxResponse(), nil
+	}
+
+	if !requester.Can(identitytypes.PermissionScheduleUpgrades) {
+		return multiplexer.NewErrorResponse(
+			types.CodeUnauthorized,
+			types.Codespace,
+			errors.New("principal is not permitted to schedule upgrades"),
+		).IntoCheckTxResponse(), nil
+	}
+
+	if _, errRes := app.validateRequest(payload, multiplexer.HeightFromContext(ctx), multiplexer.AppVersionFromContext(ctx)); errRes != nil {
+		return errRes.IntoCheckTxResponse(), nil
+	}
+
+	return &abci.ResponseCheckTx{
+		Code:      types.CodeOk,
+		Codespace: types.Codespace,
+	}, nil
+}
+
+func (app *UpgradeApp) FinalizeBlock(ctx context.Context, req *abci.RequestFinalizeBlock, data json.RawMessage) multiplexer.FinalizeBlockResponse {
+	payload, requester, errRes := app.decode(ctx, data, req.Height)
+	if errRes != nil {
+		return errRes.IntoFinalizeBlockResponse()
+	}
+
+	if !requester.Can(identitytypes.PermissionScheduleUpgrades) {
+		app.logger.Warn(
+			"Got unauthorised principal attempting to schedule an upgrade",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("role", requester.Role.String()),
+		)
+		return multiplexer.NewErrorResponse(
+			types.CodeUnauthorized,
+			types.Codespace,
+			errors.New("principal is not permitted to schedule upgrades"),
+		).IntoFinalizeBlockResponse()
+	}
+
+	// The plan must take effect after the block in which it is scheduled
+	plan, errRes := app.validateRequest(payload, req.Height-1, multiplexer.AppVersionFromContext(ctx))
+	if errRes != nil {
+		return errRes.IntoFinalizeBlockResponse()
+	}
+
+	var eventName string
+	switch payload.Type {
+	case types.RequestTypeSchedule:
+		app.state.Scheduled = &plan
+		eventName = "upgrade_scheduled"
+	case types.RequestTypeCancel:
+		app.state.Scheduled = nil
+		eventName = "upgrade_cancelled"
+	}
+
+	app.updated = true
+
+	app.logger.Info(
+		"Updated upgrade plan",
+		zap.String("type", string(payload.Type)),
+		zap.String("name", plan.Name),
+		zap.Int64("height", plan.Height),
+		zap.Uint64("version", plan.Version),
+		zap.String("requester", payload.Principal.String()),
+	)
+
+	res, err := json.Marshal(plan)
+	if err != nil {
+		return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	return multiplexer.FinalizeBlockResponse{
+		TxResult: abci.ExecTxResult{
+			Code: types.CodeOk,
+			Data: res,
+			Log:  fmt.Sprintf("upgrade %s at height %d %s", plan.Name, plan.Height, payload.Type),
+			Events: []abci.Event{
+				utils.Event(
+					eventName,
+					abci.EventAttribute{Key: "name", Value: plan.Name, Index: true},
+					abci.EventAttribute{Key: "height", Value: strconv.FormatInt(plan.Height, 10), Index: true},
+					abci.EventAttribute{Key: "version", Value: strconv.FormatUint(plan.Version, 10), Index: true},
+				),
+			},
+			Codespace: types.Codespace,
+		},
+	}
+}
+
+func (app *UpgradeApp) ScheduledUpgrade() *types.Plan {
+	return app.state.Scheduled
+}
+
+func (app *UpgradeApp) CompleteUpgrade(previousVersion uint64) error {
+	if app.state.Scheduled == nil {
+		return errors.New("no upgrade is scheduled")
+	}
+
+	app.state.Applied = append(app.state.Applied, types.AppliedUpgrade{
+		Plan:            *app.state.Scheduled,
+		PreviousVersion: previousVersion,
+	})
+	app.state.Scheduled = nil
+	app.updated = true
+
+	return nil
+}
+
+func (app *UpgradeApp) WorkingHash() ([]byte, error) {
+	marshalled, err := json.Marshal(app.state)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Sha256Sum(marshalled), nil
+}
+
+// Commit persists the scheduled and applied upgrades, if they were changed in the current block.
+func (app *UpgradeApp) Commit() error {
+	if !app.updated {
+		return nil
+	}
+
+	app.updated = false
+	return app.persist()
+}
+
+func (app *UpgradeApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
+	switch req.Path {
+	case "", types.QueryPathState:
+		data, err := json.Marshal(app.state)
+		if err != nil {
+			return nil, err
+		}
+
+		return &abci.ResponseQuery{
+			Code:      types.CodeOk,
+			Value:     data,
+			Codespace: types.Codespace,
+		}, nil
+	default:
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+}
+
+// validateRequest checks that the schedule or cancel request can be applied, given the latest finalized height and
+// the current app version, and returns the plan that is scheduled or cancelled.
+func (app *UpgradeApp) validateRequest(payload rpc.SignedPayload, height int64, appVersion uint64) (types.Plan, *multiplexer.ErrorResponse) {
+	switch payload.Type {
+	case types.RequestTypeSchedule:
+		var request types.ScheduleRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return types.Plan{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+		}
+
+		// A scheduled plan must be cancelled before another can be scheduled
+		if app.state.Scheduled != nil {
+			return types.Plan{}, multiplexer.NewErrorResponse(
+				types.CodeUpgradeAlreadyScheduled,
+				types.Codespace,
+				fmt.Errorf("upgrade %s is already scheduled", app.state.Scheduled.Name),
+			)
+		}
+
+		if err := request.Plan.Validate(height, appVersion); err != nil {
+			return types.Plan{}, multiplexer.NewErrorResponse(types.CodeInvalidPlan, types.Codespace, err)
+		}
+
+		return request.Plan, nil
+	case types.RequestTypeCancel:
+		var request types.CancelRequest
+		if err := json.Unmarshal(payload.Data, &request); err != nil {
+			return types.Plan{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+		}
+
+		if app.state.Scheduled == nil {
+			return types.Plan{}, multiplexer.NewErrorResponse(
+				types.CodeNoUpgradeScheduled,
+				types.Codespace,
+				errors.New("no upgrade is scheduled"),
+			)
+		}
+
+		return *app.state.Scheduled, nil
+	default:
+		return types.Plan{}, multiplexer.NewErrorResponse(types.CodeUnknownRequestType, types.Codespace, nil)
+	}
+}
+
+func (app *UpgradeApp) persist() error {
+	marshalled, err := json.Marshal(app.state)
+	if err != nil {
+		return err
+	}
+
+	return app.db.Set([]byte(stateKey), marshalled)
+}
+
+func (app *UpgradeApp) loadState() error {
+	data, err := app.db.Get([]byte(stateKey))
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, &app.state)
+}
+
+// decode decodes the signed payload, and validates its signature against the key that was assigned to the requester at
+// the given block height. A height of 0 validates against the requester's current key.
+func (app *UpgradeApp) decode(ctx context.Context, data json.RawMessage, height int64) (rpc.SignedPayload, identitytypes.IdentityData, *multiplexer.ErrorResponse) {
+	var payload rpc.SignedPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		app.logger.Warn("Got error decoding UpgradeApp request rpc", zap.Error(err))
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+	}
+
+	requester, err := app.identities.Get(payload.Principal)
+	if err != nil {
+		app.logger.Warn(
+			"Got error getting requester identity data",
+			zap.Error(err),
+			zap.String("requester", payload.Principal.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	valid, err := payload.Verify(requester.KeyAt(height), multiplexer.ChainIDFromContext(ctx), types.AppName)
+	if err != nil {
+		app.logger.Warn(
+			"Got error validating UpgradeApp request signature",
+			zap.Error(err),
+			zap.String("requester", payload.Principal.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	if !valid {
+		app.logger.Warn(
+			"Got invalid UpgradeApp request signature",
+			zap.String("requester", payload.Principal.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(rpc.CodeInvalidSignature, rpc.Codespace, nil)
+	}
+
+	if err := identity.CheckStatus(payload.Principal, requester); err != nil {
+		app.logger.Warn(
+			"Got UpgradeApp request from inactive principal",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("status", requester.Status.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, err
+	}
+
+	return payload, requester, nil
+}
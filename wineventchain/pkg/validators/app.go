@@ -0,0 +1,487 @@
+package validators
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/RyanW02/wineventchain/app/internal/utils"
+	"github.com/RyanW02/wineventchain/app/pkg/identity"
+	"github.com/RyanW02/wineventchain/app/pkg/multiplexer"
+	identitytypes "github.com/RyanW02/wineventchain/common/pkg/types/identity"
+	"github.com/RyanW02/wineventchain/common/pkg/types/rpc"
+	types "github.com/RyanW02/wineventchain/common/pkg/types/validators"
+	dbm "github.com/cometbft/cometbft-db"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmted25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// ValidatorsApp manages the validator set. Administrators may add and remove validators and change their voting
+// power, and the multiplexer reduces the power of validators that misbehave. The validator set is persisted, so that
+// misbehaving validators can be identified after a restart.
+type ValidatorsApp struct {
+	logger     *zap.Logger
+	identities identity.Repository
+	db         dbm.DB
+	validators *multiplexer.ValidatorMap
+	txState    txState
+}
+
+type txState struct {
+	updating []multiplexer.Address // Validators updated in the current block
+}
+
+func defaultTxState() txState {
+	return txState{
+		updating: make([]multiplexer.Address, 0),
+	}
+}
+
+const validatorsKey = "validators"
+
+var _ multiplexer.ValidatorSetApp = (*ValidatorsApp)(nil)
+var _ multiplexer.SnapshottableApp = (*ValidatorsApp)(nil)
+var _ multiplexer.GenesisApp = (*ValidatorsApp)(nil)
+
+func NewValidatorsApp(logger *zap.Logger, identityRepository identity.Repository, db dbm.DB) (*ValidatorsApp, error) {
+	app := &ValidatorsApp{
+		logger:     logger,
+		identities: identityRepository,
+		db:         db,
+		validators: multiplexer.NewValidatorMap(),
+		txState:    defaultTxState(),
+	}
+
+	if err := app.loadState(); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (app *ValidatorsApp) Name() string {
+	return types.AppName
+}
+
+func (app *ValidatorsApp) Info(ctx context.Context, req *abci.RequestInfo) any {
+	appHash, err := app.appHash()
+	if err != nil {
+		app.logger.Warn("Got error getting hash of ValidatorsApp", zap.Error(err))
+		return multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	return map[string]any{
+		"app_hash":        hex.EncodeToString(appHash),
+		"validator_count": app.validators.Len(),
+	}
+}
+
+// InitChain records the genesis validators, so that they can be managed and punished for misbehaviour.
+func (app *ValidatorsApp) InitChain(ctx context.Context, req *abci.RequestInitChain) []byte {
+	for _, update := range req.Validators {
+		pubKey := update.PubKey.GetEd25519()
+		if pubKey == nil {
+			app.logger.Warn("Ignoring genesis validator with unsupported key type", zap.String("pub_key", update.PubKey.String()))
+			continue
+		}
+
+		app.validators.Add(newValidator(pubKey, update.Power))
+	}
+
+	if err := app.persist(); err != nil {
+		app.logger.Fatal("Got error persisting genesis validators", zap.Error(err))
+	}
+
+	appHash, err := app.appHash()
+	if err != nil {
+		app.logger.Fatal("Got error getting hash of ValidatorsApp when running InitChain", zap.Error(err))
+	}
+
+	return appHash
+}
+
+// ExportSnapshot exports the current validator set.
+func (app *ValidatorsApp) ExportSnapshot() (json.RawMessage, error) {
+	return json.Marshal(app.list())
+}
+
+// RestoreSnapshot replaces the validator set with the one from a snapshot, and persists it.
+func (app *ValidatorsApp) RestoreSnapshot(data json.RawMessage) error {
+	var validators []types.Validator
+	if err := json.Unmarshal(data, &validators); err != nil {
+		return err
+	}
+
+	app.validators = multiplexer.NewValidatorMap()
+	for _, validator := range validators {
+		app.validators.Add(newValidator(validator.PubKey, validator.Power))
+	}
+
+	return app.persist()
+}
+
+// ExportGenesis exports the current validator set, ordered by address.
+func (app *ValidatorsApp) ExportGenesis() (json.RawMessage, error) {
+	return json.Marshal(app.list())
+}
+
+// InitGenesis loads the validator set carried over from another chain. The genesis validators are added to it, and the
+// combined set persisted, by InitChain.
+func (app *ValidatorsApp) InitGenesis(data json.RawMessage) error {
+	var validators []types.Validator
+	if err := json.Unmarshal(data, &validators); err != nil {
+		return err
+	}
+
+	for _, validator := range validators {
+		if len(validator.PubKey) != ed25519.PublicKeySize || validator.Power <= 0 {
+			return fmt.Errorf("invalid genesis validator %s", validator.Address)
+		}
+
+		app.validators.Add(newValidator(validator.PubKey, validator.Power))
+	}
+
+	return nil
+}
+
+func (app *ValidatorsApp) CheckTx(ctx context.Context, req *abci.RequestCheckTx, data json.RawMessage) (*abci.ResponseCheckTx, error) {
+	payload, requester, errRes := app.decode(ctx, data, 0)
+	if errRes != nil {
+		return errRes.IntoCheckTxResponse(), nil
+	}
+
+	if !requester.Can(identitytypes.PermissionManageValidators) {
+		return multiplexer.NewErrorResponse(
+			types.CodeUnauthorized,
+			types.Codespace,
+			errors.New("principal is not permitted to manage validators"),
+		).IntoCheckTxResponse(), nil
+	}
+
+	if _, errRes := app.validateUpdate(payload); errRes != nil {
+		return errRes.IntoCheckTxResponse(), nil
+	}
+
+	return &abci.ResponseCheckTx{
+		Code:      types.CodeOk,
+		Codespace: types.Codespace,
+	}, nil
+}
+
+func (app *ValidatorsApp) FinalizeBlock(ctx context.Context, req *abci.RequestFinalizeBlock, data json.RawMessage) multiplexer.FinalizeBlockResponse {
+	payload, requester, errRes := app.decode(ctx, data, req.Height)
+	if errRes != nil {
+		return errRes.IntoFinalizeBlockResponse()
+	}
+
+	if !requester.Can(identitytypes.PermissionManageValidators) {
+		app.logger.Warn(
+			"Got unauthorised principal attempting to update the validator set",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("role", requester.Role.String()),
+		)
+		return multiplexer.NewErrorResponse(
+			types.CodeUnauthorized,
+			types.Codespace,
+			errors.New("principal is not permitted to manage validators"),
+		).IntoFinalizeBlockResponse()
+	}
+
+	validator, errRes := app.validateUpdate(payload)
+	if errRes != nil {
+		return errRes.IntoFinalizeBlockResponse()
+	}
+
+	res, err := json.Marshal(validator)
+	if err != nil {
+		return multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err).IntoFinalizeBlockResponse()
+	}
+
+	app.apply(validator)
+
+	app.logger.Info(
+		"Updating validator",
+		zap.String("address", validator.Address.String()),
+		zap.Int64("power", validator.Power),
+		zap.String("requester", payload.Principal.String()),
+	)
+
+	return multiplexer.FinalizeBlockResponse{
+		TxResult: abci.ExecTxResult{
+			Code: types.CodeOk,
+			Data: res,
+			Log:  fmt.Sprintf("validator %s power set to %d", validator.Address, validator.Power),
+			Events: []abci.Event{
+				utils.Event(
+					"validator_updated",
+					abci.EventAttribute{Key: "address", Value: validator.Address.String(), Index: true},
+					abci.EventAttribute{Key: "power", Value: strconv.FormatInt(validator.Power, 10), Index: true},
+				),
+			},
+			Codespace: types.Codespace,
+		},
+		ValidatorUpdates: []abci.ValidatorUpdate{
+			{PubKey: validator.PubKey, Power: validator.Power},
+		},
+	}
+}
+
+func (app *ValidatorsApp) Punish(address multiplexer.Address, power int64) (abci.ValidatorUpdate, bool) {
+	validator, ok := app.validators.Get(address)
+	if !ok {
+		return abci.ValidatorUpdate{}, false
+	}
+
+	// CometBFT rejects blocks that update the same validator more than once, or remove every validator
+	if utils.Contains(app.txState.updating, address) || (power == 0 && app.validators.Len() <= 1) {
+		return abci.ValidatorUpdate{}, false
+	}
+
+	validator.Power = power
+	app.apply(validator)
+
+	app.logger.Warn(
+		"Punishing misbehaving validator",
+		zap.String("address", address.String()),
+		zap.Int64("power", power),
+	)
+
+	return abci.ValidatorUpdate{PubKey: validator.PubKey, Power: validator.Power}, true
+}
+
+func (app *ValidatorsApp) Validators() []abci.ValidatorUpdate {
+	all := app.validators.All()
+
+	updates := make([]abci.ValidatorUpdate, len(all))
+	for i, validator := range all {
+		updates[i] = abci.ValidatorUpdate{PubKey: validator.PubKey, Power: validator.Power}
+	}
+
+	return updates
+}
+
+func (app *ValidatorsApp) WorkingHash() ([]byte, error) {
+	return app.appHash()
+}
+
+// Commit persists the validator set, if it was updated in the current block.
+func (app *ValidatorsApp) Commit() error {
+	updated := len(app.txState.updating) > 0
+	app.txState = defaultTxState()
+
+	if !updated {
+		return nil
+	}
+
+	return app.persist()
+}
+
+func (app *ValidatorsApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
+	switch req.Path {
+	case "", types.QueryPathValidators:
+		data, err := json.Marshal(app.list())
+		if err != nil {
+			return nil, err
+		}
+
+		return &abci.ResponseQuery{
+			Code:      types.CodeOk,
+			Value:     data,
+			Codespace: types.Codespace,
+		}, nil
+	default:
+		return multiplexer.NewErrorResponse(types.CodeInvalidQueryPath, types.Codespace, nil).IntoQueryResponse(), nil
+	}
+}
+
+// validateUpdate checks that the add, remove or set_power request can be applied to the validator set, taking into
+// account the updates made earlier in the current block, and returns the validator with its new power. Removed
+// validators have a power of 0.
+func (app *ValidatorsApp) validateUpdate(payload rpc.SignedPayload) (multiplexer.Validator, *multiplexer.ErrorResponse) {
+	var request types.PayloadValidator
+	if err := json.Unmarshal(payload.Data, &request); err != nil {
+		return multiplexer.Validator{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+	}
+
+	if len(request.PubKey) != ed25519.PublicKeySize {
+		return multiplexer.Validator{}, multiplexer.NewErrorResponse(
+			types.CodeInvalidKey,
+			types.Codespace,
+			fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(request.PubKey)),
+		)
+	}
+
+	validator := newValidator(request.PubKey, request.Power)
+	if utils.Contains(app.txState.updating, validator.Address) {
+		return multiplexer.Validator{}, multiplexer.NewErrorResponse(
+			types.CodeValidatorUpdatePending,
+			types.Codespace,
+			errors.New("validator has already been updated in this block"),
+		)
+	}
+
+	_, exists := app.validators.Get(validator.Address)
+
+	switch payload.Type {
+	case types.RequestTypeAdd, types.RequestTypeSetPower:
+		if payload.Type == types.RequestTypeAdd && exists {
+			return multiplexer.Validator{}, multiplexer.NewErrorResponse(types.CodeValidatorAlreadyExists, types.Codespace, errors.New("validator already exists"))
+		}
+
+		if payload.Type == types.RequestTypeSetPower && !exists {
+			return multiplexer.Validator{}, multiplexer.NewErrorResponse(types.CodeValidatorNotFound, types.Codespace, errors.New("validator not found"))
+		}
+
+		if request.Power <= 0 || request.Power > cmttypes.MaxTotalVotingPower {
+			return multiplexer.Validator{}, multiplexer.NewErrorResponse(
+				types.CodeInvalidPower,
+				types.Codespace,
+				fmt.Errorf("power must be between 1 and %d", cmttypes.MaxTotalVotingPower),
+			)
+		}
+	case types.RequestTypeRemove:
+		if !exists {
+			return multiplexer.Validator{}, multiplexer.NewErrorResponse(types.CodeValidatorNotFound, types.Codespace, errors.New("validator not found"))
+		}
+
+		if app.validators.Len() <= 1 {
+			return multiplexer.Validator{}, multiplexer.NewErrorResponse(types.CodeLastValidator, types.Codespace, errors.New("the last validator cannot be removed"))
+		}
+
+		validator.Power = 0
+	default:
+		return multiplexer.Validator{}, multiplexer.NewErrorResponse(types.CodeUnknownRequestType, types.Codespace, fmt.Errorf("unknown request type: %s", payload.Type))
+	}
+
+	return validator, nil
+}
+
+// apply applies an update to the working validator set, and records it in the transaction state, preventing further
+// updates to the same validator in the current block. The validator set is persisted when the block is committed.
+func (app *ValidatorsApp) apply(validator multiplexer.Validator) {
+	if validator.Power == 0 {
+		app.validators.Remove(validator.Address)
+	} else {
+		app.validators.Add(validator)
+	}
+
+	app.txState.updating = append(app.txState.updating, validator.Address)
+}
+
+func (app *ValidatorsApp) persist() error {
+	marshalled, err := json.Marshal(app.list())
+	if err != nil {
+		return err
+	}
+
+	return app.db.Set([]byte(validatorsKey), marshalled)
+}
+
+func (app *ValidatorsApp) loadState() error {
+	data, err := app.db.Get([]byte(validatorsKey))
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	var validators []types.Validator
+	if err := json.Unmarshal(data, &validators); err != nil {
+		return err
+	}
+
+	for _, validator := range validators {
+		app.validators.Add(newValidator(validator.PubKey, validator.Power))
+	}
+
+	return nil
+}
+
+// list returns the validator set in its persisted form, ordered by address.
+func (app *ValidatorsApp) list() []types.Validator {
+	all := app.validators.All()
+
+	validators := make([]types.Validator, len(all))
+	for i, validator := range all {
+		validators[i] = types.Validator{
+			Address: validator.Address.String(),
+			PubKey:  validator.PubKey.GetEd25519(),
+			Power:   validator.Power,
+		}
+	}
+
+	return validators
+}
+
+func (app *ValidatorsApp) appHash() ([]byte, error) {
+	marshalled, err := json.Marshal(app.list())
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Sha256Sum(marshalled), nil
+}
+
+func newValidator(pubKey ed25519.PublicKey, power int64) multiplexer.Validator {
+	return multiplexer.Validator{
+		Address: multiplexer.NewAddress(cmted25519.PubKey(pubKey).Address()),
+		PubKey:  abci.Ed25519ValidatorUpdate(pubKey, power).PubKey,
+		Power:   power,
+	}
+}
+
+// decode decodes the signed payload, and validates its signature against the key that was assigned to the requester at
+// the given block height. A height of 0 validates against the requester's current key.
+func (app *ValidatorsApp) decode(ctx context.Context, data json.RawMessage, height int64) (rpc.SignedPayload, identitytypes.IdentityData, *multiplexer.ErrorResponse) {
+	var payload rpc.SignedPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		app.logger.Warn("Got error decoding ValidatorsApp request rpc", zap.Error(err))
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeEncodingError, multiplexer.Codespace, err)
+	}
+
+	requester, err := app.identities.Get(payload.Principal)
+	if err != nil {
+		app.logger.Warn(
+			"Got error getting requester identity data",
+			zap.Error(err),
+			zap.String("requester", payload.Principal.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	valid, err := payload.Verify(requester.KeyAt(height), multiplexer.ChainIDFromContext(ctx), types.AppName)
+	if err != nil {
+		app.logger.Warn(
+			"Got error validating ValidatorsApp request signature",
+			zap.Error(err),
+			zap.String("requester", payload.Principal.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(multiplexer.CodeUnknownError, multiplexer.Codespace, err)
+	}
+
+	if !valid {
+		app.logger.Warn(
+			"Got invalid ValidatorsApp request signature",
+			zap.String("requester", payload.Principal.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, multiplexer.NewErrorResponse(rpc.CodeInvalidSignature, rpc.Codespace, nil)
+	}
+
+	if err := identity.CheckStatus(payload.Principal, requester); err != nil {
+		app.logger.Warn(
+			"Got ValidatorsApp request from inactive principal",
+			zap.String("requester", payload.Principal.String()),
+			zap.String("status", requester.Status.String()),
+		)
+		return rpc.SignedPayload{}, identitytypes.IdentityData{}, err
+	}
+
+	return payload, requester, nil
+}